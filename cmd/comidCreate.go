@@ -23,8 +23,8 @@ var comidCreateCmd = NewComidCreateCmd()
 func NewComidCreateCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "create",
-		Short: "create one or more CBOR-encoded CoMID(s) from the supplied JSON template(s)",
-		Long: `create one or more CBOR-encoded CoMID(s) from the supplied JSON template(s)
+		Short: "create one or more CBOR-encoded CoMID(s) from the supplied JSON or YAML template(s)",
+		Long: `create one or more CBOR-encoded CoMID(s) from the supplied JSON or YAML template(s)
 
 	Create CoMIDs from templates t1.json and t2.json, plus any template found in
 	the templates/ directory.  Save them to the current working directory.
@@ -38,6 +38,9 @@ func NewComidCreateCmd() *cobra.Command {
 	
 		cocli comid create --template=t3.json --output-dir=comids
 
+	Templates can be written in JSON (.json) or YAML (.yaml, .yml), which
+	allows comments.  YAML templates have the same structure as JSON ones.
+
 	Note: since the output file is deterministically generated from the template
 	file name, all the template file names (when from different directories)
 	MUST be different.
@@ -47,7 +50,7 @@ func NewComidCreateCmd() *cobra.Command {
 				return err
 			}
 
-			filesList := filesList(comidCreateFiles, comidCreateDirs, ".json")
+			filesList := templateFilesList(comidCreateFiles, comidCreateDirs)
 			if len(filesList) == 0 {
 				return errors.New("no files found")
 			}
@@ -71,7 +74,7 @@ func NewComidCreateCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringArrayVarP(
-		&comidCreateFiles, "template", "t", []string{}, "a CoMID template file (in JSON format)",
+		&comidCreateFiles, "template", "t", []string{}, "a CoMID template file (in JSON or YAML format)",
 	)
 
 	cmd.Flags().StringArrayVarP(
@@ -104,7 +107,7 @@ func templateToCBOR(tmplFile, outputDir string) (string, error) {
 		return "", fmt.Errorf("error loading template from %s: %w", tmplFile, err)
 	}
 
-	if err = c.FromJSON(tmplData); err != nil {
+	if err = decodeTemplate(&c, tmplData, tmplFile); err != nil {
 		return "", fmt.Errorf("error decoding template from %s: %w", tmplFile, err)
 	}
 
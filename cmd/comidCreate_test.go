@@ -126,3 +126,56 @@ func Test_ComidCreateCmd_template_from_dir_to_custom_dir(t *testing.T) {
 	_, err = fs.Stat(expectedFileName)
 	assert.NoError(t, err)
 }
+
+func Test_ComidCreateCmd_yaml_template_from_dir(t *testing.T) {
+	var err error
+
+	cmd := NewComidCreateCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "testdir/ok.yaml", testComidACMERoadRunnerYAML, 0644)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, "testdir/ok.json", testComidACMERoadRunner, 0644)
+	require.NoError(t, err)
+
+	args := []string{
+		"--template-dir=testdir",
+		"--output-dir=out",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+
+	// both templates are saved to the same file name, so create them again
+	// separately to compare their CBOR encodings
+	fromYAML, err := afero.ReadFile(fs, "out/ok.cbor")
+	require.NoError(t, err)
+
+	cmd = NewComidCreateCmd()
+	cmd.SetArgs([]string{"--template=testdir/ok.json", "--output-dir=json"})
+	require.NoError(t, cmd.Execute())
+
+	fromJSON, err := afero.ReadFile(fs, "json/ok.cbor")
+	require.NoError(t, err)
+
+	assert.Equal(t, fromJSON, fromYAML)
+}
+
+func Test_ComidCreateCmd_yaml_template_with_invalid_comid(t *testing.T) {
+	var err error
+
+	cmd := NewComidCreateCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "bad-comid.yml", []byte("tag-identity: [1, 2]\n"), 0644)
+	require.NoError(t, err)
+
+	args := []string{
+		"--template=bad-comid.yml",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, "1/1 creations(s) failed")
+}
@@ -0,0 +1,234 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/veraison/corim/comid"
+)
+
+var (
+	comidDiffFormat *string
+	comidDiffFailOn *[]string
+)
+
+var comidDiffCmd = NewComidDiffCmd()
+
+func NewComidDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <comid> <comid>",
+		Short: "compare the triples of two CoMIDs",
+		Long: `compare the triples of two CoMIDs
+
+    Report the changes to reference values, endorsed values, device identity
+    keys and attestation verification keys between the CoMID in old.cbor and the
+    one in new.cbor.  Each CoMID can be CBOR-encoded or a JSON (.json) or YAML
+    (.yaml, .yml) template.
+
+      cocli comid diff old.cbor new.cbor
+
+    Measurements are aligned by environment and measurement key (e.g., the
+    psa.refval-id label, version and signer-id), irrespective of the triple in
+    which they appear, and reported as added, removed or modified.  Keys are
+    aligned by environment and reported as added or removed.  Changes to other
+    CoMID fields (e.g., tag-identity) are reported by field name.
+
+    Report the changes as JSON:
+
+      cocli comid diff old.cbor new.json --format=json
+
+    Exit with an error if any measurement or key has been removed, e.g., to
+    catch accidental removals in CI.  The kinds of change can be combined, as in
+    --fail-on=removed,modified, and "any" matches all of them:
+
+      cocli comid diff old.cbor new.cbor --fail-on=removed
+    `,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkComidDiffArgs(args); err != nil {
+				return err
+			}
+
+			d, err := diffComidFiles(args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			if err = printComidDiff(d, *comidDiffFormat); err != nil {
+				return err
+			}
+
+			if n := d.count(*comidDiffFailOn); n != 0 {
+				return fmt.Errorf(
+					"found %d change(s) matching --fail-on=%s", n, strings.Join(*comidDiffFailOn, ","),
+				)
+			}
+
+			return nil
+		},
+	}
+
+	comidDiffFormat = cmd.Flags().String("format", "text", "output format: text or json")
+	comidDiffFailOn = cmd.Flags().StringSlice(
+		"fail-on", []string{}, "exit with an error on changes of the given kind(s): added, removed, modified or any",
+	)
+
+	return cmd
+}
+
+func checkComidDiffArgs(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expecting two CoMID files, got %d", len(args))
+	}
+
+	if comidDiffFormat == nil {
+		return errors.New("no format supplied")
+	}
+
+	if *comidDiffFormat != "text" && *comidDiffFormat != "json" {
+		return fmt.Errorf(`invalid --format %q: expecting "text" or "json"`, *comidDiffFormat)
+	}
+
+	if comidDiffFailOn == nil {
+		return errors.New("no fail-on kinds supplied")
+	}
+
+	for _, kind := range *comidDiffFailOn {
+		switch kind {
+		case diffAdded, diffRemoved, diffModified, "any":
+		default:
+			return fmt.Errorf(
+				`invalid --fail-on %q: expecting "added", "removed", "modified" or "any"`, kind,
+			)
+		}
+	}
+
+	return nil
+}
+
+// comidDiff describes the differences between two CoMIDs
+type comidDiff struct {
+	// names of the top-level CoMID fields, other than the value and key
+	// triples, that differ
+	Fields  []string     `json:"fields,omitempty"`
+	Triples []tripleDiff `json:"triples,omitempty"`
+}
+
+func diffComidFiles(aFile, bFile string) (*comidDiff, error) {
+	a, err := loadComid(aFile)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := loadComid(bFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var d comidDiff
+
+	if d.Fields, err = diffComidFields(a, b); err != nil {
+		return nil, fmt.Errorf("error comparing %s and %s: %w", aFile, bFile, err)
+	}
+
+	if d.Triples, err = diffComidTriples(a, b); err != nil {
+		return nil, fmt.Errorf("error comparing %s and %s: %w", aFile, bFile, err)
+	}
+
+	return &d, nil
+}
+
+// loadComid loads a CoMID from a CBOR file or, if the file has a .json, .yaml
+// or .yml extension, from a template
+func loadComid(file string) (*comid.Comid, error) {
+	data, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return nil, fmt.Errorf("error loading CoMID from %s: %w", file, err)
+	}
+
+	var c comid.Comid
+
+	if isTemplate(file) {
+		err = decodeTemplate(&c, data, file)
+	} else {
+		err = c.FromCBOR(data)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error decoding CoMID from %s: %w", file, err)
+	}
+
+	return &c, nil
+}
+
+// count returns the number of changes of the supplied kinds.  Changes to other
+// CoMID fields count as modifications.
+func (o comidDiff) count(kinds []string) int {
+	var n int
+
+	for _, kind := range kinds {
+		if kind == "any" {
+			return len(o.Fields) + len(o.Triples)
+		}
+	}
+
+	for _, kind := range kinds {
+		if kind == diffModified {
+			n += len(o.Fields)
+		}
+
+		for _, t := range o.Triples {
+			if t.Change == kind {
+				n++
+			}
+		}
+	}
+
+	return n
+}
+
+// text returns the differences in human-readable form
+func (o comidDiff) text() string {
+	var w strings.Builder
+
+	for _, f := range o.Fields {
+		fmt.Fprintf(&w, "%s %s\n", diffSymbol(diffModified), f)
+	}
+
+	writeTripleDiffs(&w, o.Triples, "")
+
+	return w.String()
+}
+
+func printComidDiff(d *comidDiff, format string) error {
+	if format == "json" {
+		j, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding differences: %w", err)
+		}
+
+		fmt.Println(string(j))
+
+		return nil
+	}
+
+	if len(d.Fields) == 0 && len(d.Triples) == 0 {
+		fmt.Println(">> no differences")
+		return nil
+	}
+
+	fmt.Print(d.text())
+
+	return nil
+}
+
+func init() {
+	comidCmd.AddCommand(comidDiffCmd)
+}
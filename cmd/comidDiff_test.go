@@ -0,0 +1,156 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/corim/comid"
+)
+
+// writeComidDiffFixtures saves the CoMID in testComidACMEWileE as old.cbor, and
+// a new version, with a different raw value for a different environment, as
+// new.json
+func writeComidDiffFixtures(t *testing.T) {
+	var c comid.Comid
+	require.NoError(t, c.FromJSON(testComidACMEWileE))
+	data, err := c.ToCBOR()
+	require.NoError(t, err)
+
+	newComid := strings.Replace(string(testComidACMEWileE), `"AAEC"`, `"AAED"`, 1)
+	newComid = strings.Replace(newComid, `"model": "WileE"`, `"model": "WileE2"`, 1)
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "old.cbor", data, 0644))
+	require.NoError(t, afero.WriteFile(fs, "new.json", []byte(newComid), 0644))
+}
+
+func Test_ComidDiffCmd_bad_args(t *testing.T) {
+	cmd := NewComidDiffCmd()
+	cmd.SetArgs([]string{"a.cbor", "b.cbor", "c.cbor"})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "expecting two CoMID files, got 3")
+
+	cmd = NewComidDiffCmd()
+	cmd.SetArgs([]string{"a.cbor", "b.cbor", "--fail-on=removed,changed"})
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, `invalid --fail-on "changed": expecting "added", "removed", "modified" or "any"`)
+}
+
+func Test_checkComidDiffArgs_nil_flags(t *testing.T) {
+	savedFormat, savedFailOn := comidDiffFormat, comidDiffFailOn
+	defer func() { comidDiffFormat, comidDiffFailOn = savedFormat, savedFailOn }()
+
+	comidDiffFormat, comidDiffFailOn = nil, nil
+	assert.EqualError(t, checkComidDiffArgs([]string{"a.cbor", "b.cbor"}), "no format supplied")
+
+	format := "text"
+	comidDiffFormat = &format
+	assert.EqualError(t, checkComidDiffArgs([]string{"a.cbor", "b.cbor"}), "no fail-on kinds supplied")
+}
+
+func Test_ComidDiffCmd_bad_comid(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "ok.cbor", testComid, 0644))
+	require.NoError(t, afero.WriteFile(fs, "bad.json", []byte("{"), 0644))
+
+	cmd := NewComidDiffCmd()
+	cmd.SetArgs([]string{"ok.cbor", "bad.json"})
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "error decoding CoMID from bad.json: ")
+}
+
+func Test_ComidDiffCmd_no_differences(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "a.cbor", PSARefValCBOR, 0644))
+	require.NoError(t, afero.WriteFile(fs, "b.cbor", PSARefValCBOR, 0644))
+
+	cmd := NewComidDiffCmd()
+	cmd.SetArgs([]string{"a.cbor", "b.cbor", "--fail-on=any"})
+
+	assert.NoError(t, cmd.Execute())
+}
+
+func Test_ComidDiffCmd_fail_on(t *testing.T) {
+	writeComidDiffFixtures(t)
+
+	cmd := NewComidDiffCmd()
+	cmd.SetArgs([]string{"old.cbor", "new.json", "--fail-on=added"})
+	assert.EqualError(t, cmd.Execute(), "found 1 change(s) matching --fail-on=added")
+
+	cmd = NewComidDiffCmd()
+	cmd.SetArgs([]string{"old.cbor", "new.json", "--fail-on=removed", "--format=json"})
+	assert.EqualError(t, cmd.Execute(), "found 1 change(s) matching --fail-on=removed")
+
+	cmd = NewComidDiffCmd()
+	cmd.SetArgs([]string{"old.cbor", "new.json", "--fail-on=modified"})
+	assert.NoError(t, cmd.Execute())
+}
+
+func Test_ComidDiff_environment_change(t *testing.T) {
+	writeComidDiffFixtures(t)
+
+	d, err := diffComidFiles("old.cbor", "new.json")
+	require.NoError(t, err)
+
+	assert.Empty(t, d.Fields)
+	require.Len(t, d.Triples, 2)
+
+	// measurements in different environments are not aligned
+	assert.Equal(t, diffRemoved, d.Triples[0].Change)
+	assert.JSONEq(t, `{"class":{"vendor":"ACME","model":"WileE"}}`, string(d.Triples[0].Environment))
+	assert.Equal(t, diffAdded, d.Triples[1].Change)
+	assert.JSONEq(t, `{"class":{"vendor":"ACME","model":"WileE2"}}`, string(d.Triples[1].Environment))
+
+	assert.Equal(t, 2, d.count([]string{"any"}))
+	assert.Equal(t, 1, d.count([]string{diffRemoved}))
+	assert.Equal(t, 0, d.count([]string{diffModified}))
+}
+
+func Test_ComidDiff_psa_refval_id(t *testing.T) {
+	var c comid.Comid
+	require.NoError(t, c.FromCBOR(PSARefValCBOR))
+	require.NotNil(t, c.Triples.ReferenceValues)
+
+	vts := c.Triples.ReferenceValues.Values
+	require.NotEmpty(t, vts)
+	require.True(t, len(vts[0].Measurements.Values) > 1)
+
+	// drop the first measurement of the first triple, and change the SVN of
+	// the second one
+	removed := vts[0].Measurements.Values[0]
+	vts[0].Measurements.Values = vts[0].Measurements.Values[1:]
+	svn, err := comid.NewSVN(uint64(42), "exact-value")
+	require.NoError(t, err)
+	vts[0].Measurements.Values[0].Val.SVN = svn
+
+	data, err := c.ToCBOR()
+	require.NoError(t, err)
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "old.cbor", PSARefValCBOR, 0644))
+	require.NoError(t, afero.WriteFile(fs, "new.cbor", data, 0644))
+
+	d, err := diffComidFiles("old.cbor", "new.cbor")
+	require.NoError(t, err)
+	require.Len(t, d.Triples, 2)
+
+	assert.Equal(t, diffRemoved, d.Triples[0].Change)
+	assert.Contains(t, string(d.Triples[0].Key), `"type":"psa.refval-id"`)
+	assert.Contains(t, string(d.Triples[0].Key), *removed.Key.Value.(*comid.TaggedPSARefValID).Label)
+
+	assert.Equal(t, diffModified, d.Triples[1].Change)
+	assert.Contains(t, string(d.Triples[1].Key), `"type":"psa.refval-id"`)
+	assert.Contains(t, string(d.Triples[1].New), `"svn":{"type":"exact-value","value":42}`)
+
+	assert.Contains(t, d.text(), "- reference-values\n")
+	assert.Contains(t, d.text(), "~ reference-values\n")
+}
@@ -12,8 +12,10 @@ import (
 )
 
 var (
-	comidDisplayFiles []string
-	comidDisplayDirs  []string
+	comidDisplayFiles    []string
+	comidDisplayDirs     []string
+	comidDisplayFormat   *string
+	comidDisplayAnnotate *bool
 )
 
 var comidDisplayCmd = NewComidDisplayCmd()
@@ -33,6 +35,17 @@ func NewComidDisplayCmd() *cobra.Command {
 	directory.
 	
 	  cocli comid display --file=c1.cbor --file=c2.cbor --dir=comids
+
+	Display CoMID in file c.cbor as YAML, or as a compact table with a row per
+	triple (environment, measurement key, digests and SVN).
+
+	  cocli comid display --file=c.cbor --format=yaml
+	  cocli comid display --file=c.cbor --format=table
+
+	Display CoMID in file c.cbor in CBOR extended diagnostic notation (EDN),
+	with map keys annotated with their CDDL names.
+
+	  cocli comid display --file=c.cbor --format=edn --annotate
 	`,
 
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -47,7 +60,7 @@ func NewComidDisplayCmd() *cobra.Command {
 
 			errs := 0
 			for _, file := range filesList {
-				if err := displayComidFile(file); err != nil {
+				if err := displayComidFile(file, *comidDisplayFormat, *comidDisplayAnnotate); err != nil {
 					fmt.Printf(">> failed displaying %q: %v\n", file, err)
 					errs++
 					continue
@@ -69,10 +82,17 @@ func NewComidDisplayCmd() *cobra.Command {
 		&comidDisplayDirs, "dir", "d", []string{}, "a directory containing CoMID files (in CBOR format)",
 	)
 
+	comidDisplayFormat = cmd.Flags().String(
+		"format", displayFormatJSON, "output format: json, yaml, table or edn",
+	)
+	comidDisplayAnnotate = cmd.Flags().Bool(
+		"annotate", false, "annotate map keys with their CDDL names (edn format only)",
+	)
+
 	return cmd
 }
 
-func displayComidFile(file string) error {
+func displayComidFile(file, format string, annotate bool) error {
 	var (
 		data []byte
 		err  error
@@ -83,14 +103,19 @@ func displayComidFile(file string) error {
 	}
 
 	// use file name as heading
-	return printComid(data, ">> ["+file+"]")
+	if format == displayFormatEDN {
+		return printEDN(data, ednComid, annotate, ">> ["+file+"]")
+	}
+
+	return printComid(data, format, ">> ["+file+"]")
 }
 
 func checkComidDisplayArgs() error {
 	if len(comidDisplayFiles) == 0 && len(comidDisplayDirs) == 0 {
 		return errors.New("no files supplied")
 	}
-	return nil
+
+	return checkDisplayFormat(*comidDisplayFormat, *comidDisplayAnnotate)
 }
 
 func init() {
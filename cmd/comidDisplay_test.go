@@ -99,3 +99,63 @@ func Test_ComidDisplayCmd_file_with_valid_comid_from_dir(t *testing.T) {
 	err = cmd.Execute()
 	assert.NoError(t, err)
 }
+
+func Test_ComidDisplayCmd_bad_format(t *testing.T) {
+	cmd := NewComidDisplayCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--format=xml",
+	}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, `invalid --format "xml": expecting "json", "yaml", "table" or "edn"`)
+}
+
+func Test_ComidDisplayCmd_annotate_without_edn(t *testing.T) {
+	cmd := NewComidDisplayCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--annotate",
+	}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "--annotate requires --format=edn")
+}
+
+func Test_ComidDisplayCmd_file_with_valid_comid_edn(t *testing.T) {
+	var err error
+
+	cmd := NewComidDisplayCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "ok.cbor", PSARefValCBOR, 0400)
+	require.NoError(t, err)
+
+	args := []string{
+		"--file=ok.cbor",
+		"--format=edn",
+		"--annotate",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+}
+
+func Test_ComidDisplayCmd_file_with_valid_comid_yaml_and_table(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "ok.cbor", PSARefValCBOR, 0400)
+	require.NoError(t, err)
+
+	for _, format := range []string{"yaml", "table"} {
+		cmd := NewComidDisplayCmd()
+		cmd.SetArgs([]string{"--file=ok.cbor", "--format=" + format})
+
+		err = cmd.Execute()
+		assert.NoError(t, err, format)
+	}
+}
@@ -4,23 +4,38 @@
 package cmd
 
 import (
+	"bytes"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
 
 	"github.com/spf13/afero"
 	"github.com/veraison/corim/comid"
+	"github.com/veraison/corim/corim"
 	"github.com/veraison/corim/cots"
 	"github.com/veraison/swid"
+	"gopkg.in/yaml.v3"
 )
 
-func filesList(files, dirs []string, ext string) []string {
+func filesList(files, dirs []string, exts ...string) []string {
 	var l []string
 
+	hasExt := func(file string) bool {
+		for _, ext := range exts {
+			if filepath.Ext(file) == ext {
+				return true
+			}
+		}
+		return false
+	}
+
 	for _, file := range files {
 		if _, err := fs.Stat(file); err == nil {
-			if filepath.Ext(file) == ext {
+			if hasExt(file) {
 				l = append(l, file)
 			}
 		}
@@ -33,7 +48,7 @@ func filesList(files, dirs []string, ext string) []string {
 		}
 
 		for _, fileInfo := range filesInfo {
-			if !fileInfo.IsDir() && filepath.Ext(fileInfo.Name()) == ext {
+			if !fileInfo.IsDir() && hasExt(fileInfo.Name()) {
 				l = append(l, filepath.Join(dir, fileInfo.Name()))
 			}
 		}
@@ -46,37 +61,141 @@ type FromCBORLoader interface {
 	FromCBOR([]byte) error
 }
 
-func printJSONFromCBOR(fcl FromCBORLoader, cbor []byte, heading string) error {
-	var (
-		err error
-		j   []byte
-	)
-
-	if err = fcl.FromCBOR(cbor); err != nil {
+func printFromCBOR(fcl FromCBORLoader, cbor []byte, format, heading string) error {
+	if err := fcl.FromCBOR(cbor); err != nil {
 		return fmt.Errorf("CBOR decoding failed: %w", err)
 	}
 
-	indent := "  "
-	if j, err = json.MarshalIndent(fcl, "", indent); err != nil {
-		return fmt.Errorf("JSON encoding failed: %w", err)
+	out, err := formatDisplay(fcl, format)
+	if err != nil {
+		return err
 	}
 
 	fmt.Println(heading)
-	fmt.Println(string(j))
+	fmt.Println(out)
 
 	return nil
 }
 
-func printComid(cbor []byte, heading string) error {
-	return printJSONFromCBOR(&comid.Comid{}, cbor, heading)
+// output formats of the display commands
+const (
+	displayFormatJSON  = "json"
+	displayFormatYAML  = "yaml"
+	displayFormatTable = "table"
+	displayFormatEDN   = "edn"
+)
+
+// checkDisplayFormat checks the --format and --annotate switches of the display
+// commands
+func checkDisplayFormat(format string, annotate bool) error {
+	switch format {
+	case displayFormatJSON, displayFormatYAML, displayFormatTable, displayFormatEDN:
+	default:
+		return fmt.Errorf(`invalid --format %q: expecting "json", "yaml", "table" or "edn"`, format)
+	}
+
+	if annotate && format != displayFormatEDN {
+		return errors.New("--annotate requires --format=edn")
+	}
+
+	return nil
 }
 
-func printCoswid(cbor []byte, heading string) error {
-	return printJSONFromCBOR(&swid.SoftwareIdentity{}, cbor, heading)
+// formatDisplay renders the decoded item in the supplied format, which must be
+// one of json, yaml or table
+func formatDisplay(v any, format string) (string, error) {
+	switch format {
+	case displayFormatTable:
+		t, err := toTable(v)
+		if err != nil {
+			return "", err
+		}
+		return t.String(), nil
+	case displayFormatYAML:
+		y, err := toYAML(v)
+		if err != nil {
+			return "", fmt.Errorf("YAML encoding failed: %w", err)
+		}
+		return y, nil
+	default:
+		j, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("JSON encoding failed: %w", err)
+		}
+		return string(j), nil
+	}
 }
 
-func printCots(cbor []byte, heading string) error {
-	return printJSONFromCBOR(&cots.ConciseTaStore{}, cbor, heading)
+// toYAML renders v as YAML.  The JSON encoding of v is converted, so that YAML
+// and JSON show the same field names, in the same order, and the same values.
+func toYAML(v any) (string, error) {
+	j, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	var doc yaml.Node
+	if err = yaml.Unmarshal(j, &doc); err != nil {
+		return "", err
+	}
+
+	// use block style rather than the flow style of the JSON input, but keep
+	// quoting the strings that YAML 1.1 parsers would read as booleans
+	var resetStyle func(*yaml.Node)
+	resetStyle = func(n *yaml.Node) {
+		n.Style = 0
+		if n.Kind == yaml.ScalarNode && n.Tag == "!!str" {
+			switch strings.ToLower(n.Value) {
+			case "y", "yes", "n", "no", "on", "off":
+				n.Style = yaml.DoubleQuotedStyle
+			}
+		}
+		for _, c := range n.Content {
+			resetStyle(c)
+		}
+	}
+	resetStyle(&doc)
+
+	var b strings.Builder
+
+	e := yaml.NewEncoder(&b)
+	e.SetIndent(2)
+
+	if err = e.Encode(&doc); err != nil {
+		return "", err
+	}
+
+	if err = e.Close(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// printEDN prints the CBOR data item in extended diagnostic notation, annotated
+// using the supplied schema if requested
+func printEDN(cbor []byte, s *ednSchema, annotate bool, heading string) error {
+	edn, err := toEDN(cbor, s, annotate)
+	if err != nil {
+		return fmt.Errorf("CBOR decoding failed: %w", err)
+	}
+
+	fmt.Println(heading)
+	fmt.Println(edn)
+
+	return nil
+}
+
+func printComid(cbor []byte, format, heading string) error {
+	return printFromCBOR(&comid.Comid{}, cbor, format, heading)
+}
+
+func printCoswid(cbor []byte, format, heading string) error {
+	return printFromCBOR(&swid.SoftwareIdentity{}, cbor, format, heading)
+}
+
+func printCots(cbor []byte, format, heading string) error {
+	return printFromCBOR(&cots.ConciseTaStore{}, cbor, format, heading)
 }
 
 func makeFileName(dirName, baseName, ext string) string {
@@ -90,3 +209,168 @@ func makeFileName(dirName, baseName, ext string) string {
 		)+ext,
 	)
 }
+
+// certFilesList returns the DER and PEM certificate files found in the
+// supplied files and directories
+func certFilesList(files, dirs []string) []string {
+	l := filesList(files, dirs, ".der")
+	l = append(l, filesList(files, dirs, ".pem")...)
+	l = append(l, filesList(files, dirs, ".crt")...)
+
+	return l
+}
+
+// parseCertificates decodes one or more X.509 certificates, either PEM-encoded
+// or as a concatenation of DER-encoded certificates
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var (
+		certs []*x509.Certificate
+		block *pem.Block
+		rest  = data
+	)
+
+	for {
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		certs = append(certs, cert)
+	}
+
+	if len(certs) != 0 {
+		return certs, nil
+	}
+
+	certs, err := x509.ParseCertificates(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates found")
+	}
+
+	return certs, nil
+}
+
+// loadCertPool creates a certificate pool from the certificates found in the
+// supplied files
+func loadCertPool(files []string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+
+	for _, file := range files {
+		data, err := afero.ReadFile(fs, file)
+		if err != nil {
+			return nil, err
+		}
+
+		certs, err := parseCertificates(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing certificates from %s: %w", file, err)
+		}
+
+		for _, c := range certs {
+			pool.AddCert(c)
+		}
+	}
+
+	return pool, nil
+}
+
+// loadCorim loads a signed (COSE Sign1 or COSE Sign) or unsigned CoRIM from
+// file, without verifying its signatures.  The Meta is returned for signed
+// CoRIMs, and is nil for unsigned ones.
+func loadCorim(corimFile string) (*corim.UnsignedCorim, *corim.Meta, error) {
+	corimCBOR, err := afero.ReadFile(fs, corimFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error loading CoRIM from %s: %w", corimFile, err)
+	}
+
+	if isMultiSigned(corimCBOR) {
+		var ms multiSignedCorim
+		if err = ms.FromCOSE(corimCBOR); err != nil {
+			return nil, nil, fmt.Errorf("error decoding signed CoRIM from %s: %w", corimFile, err)
+		}
+		return &ms.UnsignedCorim, &ms.Meta, nil
+	}
+
+	var s corim.SignedCorim
+	if err = s.FromCOSE(corimCBOR); err == nil {
+		return &s.UnsignedCorim, &s.Meta, nil
+	}
+
+	// don't mask the reason why something that looks like a COSE Sign1 could
+	// not be decoded
+	if isSign1(corimCBOR) {
+		return nil, nil, fmt.Errorf("error decoding signed CoRIM from %s: %w", corimFile, err)
+	}
+
+	var u corim.UnsignedCorim
+	if err = u.FromCBOR(corimCBOR); err != nil {
+		return nil, nil, fmt.Errorf("error decoding CoRIM (signed or unsigned) from %s: %w", corimFile, err)
+	}
+
+	return &u, nil, nil
+}
+
+var errMalformedTag = errors.New("malformed tag")
+
+// names of the types of the tags embedded in a CoRIM, as returned by
+// splitCorimTag
+var corimTagNames = map[string]string{
+	"comid":  "CoMID",
+	"coswid": "CoSWID",
+	"cots":   "CoTS",
+}
+
+// splitCorimTag splits a tag embedded in a CoRIM into its type ("comid",
+// "coswid" or "cots") and its CBOR-encoded payload.  An error is returned if
+// the tag is too short (errMalformedTag) or of an unknown type.
+func splitCorimTag(t corim.Tag) (string, []byte, error) {
+	// need at least 3 bytes for the tag and 1 for the smallest bstr
+	if len(t) < 3+1 {
+		return "", nil, errMalformedTag
+	}
+
+	cborTag, cborData := t[:3], t[3:]
+
+	switch {
+	case bytes.Equal(cborTag, corim.ComidTag):
+		return "comid", cborData, nil
+	case bytes.Equal(cborTag, corim.CoswidTag):
+		return "coswid", cborData, nil
+	case bytes.Equal(cborTag, cots.CotsTag):
+		return "cots", cborData, nil
+	default:
+		return "", nil, fmt.Errorf("unmatched CBOR tag %x", cborTag)
+	}
+}
+
+// saveUnsignedCorim validates the unsigned CoRIM and saves it to file in CBOR
+// format
+func saveUnsignedCorim(u *corim.UnsignedCorim, corimFile string) error {
+	if err := u.Valid(); err != nil {
+		return fmt.Errorf("error validating CoRIM: %w", err)
+	}
+
+	corimCBOR, err := u.ToCBOR()
+	if err != nil {
+		return fmt.Errorf("error encoding CoRIM to CBOR: %w", err)
+	}
+
+	if err = afero.WriteFile(fs, corimFile, corimCBOR, 0644); err != nil {
+		return fmt.Errorf("error saving CoRIM to file %s: %w", corimFile, err)
+	}
+
+	return nil
+}
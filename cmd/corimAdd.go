@@ -0,0 +1,168 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/veraison/corim/corim"
+)
+
+var (
+	corimAddCorimFile   *string
+	corimAddComidFiles  []string
+	corimAddComidDirs   []string
+	corimAddCoswidFiles []string
+	corimAddCoswidDirs  []string
+	corimAddCotsFiles   []string
+	corimAddCotsDirs    []string
+	corimAddOutputFile  *string
+)
+
+var corimAddCmd = NewCorimAddCmd()
+
+func NewCorimAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "add CoMID(s), CoSWID(s) and/or CoTS to an existing CoRIM",
+		Long: `add CoMID(s), CoSWID(s) and/or CoTS to an existing CoRIM
+
+    Append the CoMID in comid.cbor to the unsigned CoRIM in corim.cbor, which is
+    updated in place.  The resulting CoRIM is validated before being saved.
+
+      cocli corim add --file=corim.cbor --comid=comid.cbor
+
+    Append the CoMIDs found in the comid/ directory, the CoSWID in coswid.cbor
+    and the CoTS in cots.cbor, and save the result to new-corim.cbor:
+
+      cocli corim add --file=corim.cbor \
+                  --comid-dir=comid \
+                  --coswid=coswid.cbor \
+                  --cots=cots.cbor \
+                  --output=new-corim.cbor
+
+    If the CoRIM is signed, its signature and Meta are dropped, and the result
+    is an unsigned CoRIM that must be signed again using corim sign.  In this
+    case, unless --output is supplied, the result is saved next to the signed
+    CoRIM, which is left untouched, e.g., to signed-corim-unsigned.cbor for
+    signed-corim.cbor.
+    `,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkCorimAddArgs(); err != nil {
+				return err
+			}
+
+			comidFilesList := filesList(corimAddComidFiles, corimAddComidDirs, ".cbor")
+			coswidFilesList := filesList(corimAddCoswidFiles, corimAddCoswidDirs, ".cbor")
+			cotsFilesList := filesList(corimAddCotsFiles, corimAddCotsDirs, ".cbor")
+
+			n := len(comidFilesList) + len(coswidFilesList) + len(cotsFilesList)
+			if n == 0 {
+				return errors.New("no CoMID, CoSWID or CoTS files found")
+			}
+
+			outputFile, err := editCorim(*corimAddCorimFile, *corimAddOutputFile, func(u *corim.UnsignedCorim) error {
+				return addCorimTags(u, comidFilesList, coswidFilesList, cotsFilesList)
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf(">> added %d tag(s) to %q and saved to %q\n", n, *corimAddCorimFile, outputFile)
+
+			return nil
+		},
+	}
+
+	corimAddCorimFile = cmd.Flags().StringP("file", "f", "", "a CoRIM file (in CBOR format)")
+
+	cmd.Flags().StringArrayVarP(
+		&corimAddComidDirs, "comid-dir", "M", []string{}, "a directory containing CBOR-encoded CoMID files",
+	)
+
+	cmd.Flags().StringArrayVarP(
+		&corimAddComidFiles, "comid", "m", []string{}, "a CBOR-encoded CoMID file",
+	)
+
+	cmd.Flags().StringArrayVarP(
+		&corimAddCoswidDirs, "coswid-dir", "S", []string{}, "a directory containing CBOR-encoded CoSWID files",
+	)
+
+	cmd.Flags().StringArrayVarP(
+		&corimAddCoswidFiles, "coswid", "s", []string{}, "a CBOR-encoded CoSWID file",
+	)
+
+	cmd.Flags().StringArrayVarP(
+		&corimAddCotsDirs, "cots-dir", "C", []string{}, "a directory containing CBOR-encoded CoTS files",
+	)
+
+	cmd.Flags().StringArrayVarP(
+		&corimAddCotsFiles, "cots", "c", []string{}, "a CBOR-encoded CoTS file",
+	)
+
+	corimAddOutputFile = cmd.Flags().StringP(
+		"output", "o", "", "name of the resulting (unsigned) CoRIM file (default: the input file, or <input>-unsigned.cbor if signed)",
+	)
+
+	return cmd
+}
+
+func checkCorimAddArgs() error {
+	if corimAddCorimFile == nil || *corimAddCorimFile == "" {
+		return errors.New("no CoRIM supplied")
+	}
+
+	if len(corimAddComidDirs)+len(corimAddComidFiles)+
+		len(corimAddCoswidDirs)+len(corimAddCoswidFiles)+
+		len(corimAddCotsDirs)+len(corimAddCotsFiles) == 0 {
+		return errors.New("no CoMID, CoSWID or CoTS files or folders supplied")
+	}
+
+	return nil
+}
+
+// editCorim loads the CoRIM in corimFile, applies the supplied edit to it, and
+// saves the result, once validated, to outputFile, or back to corimFile if
+// outputFile is empty.  A signed CoRIM is saved unsigned and, if outputFile is
+// empty, to <corimFile>-unsigned.cbor, so that the signed CoRIM is not
+// overwritten.  The name of the file the result is saved to is returned.
+func editCorim(corimFile, outputFile string, edit func(*corim.UnsignedCorim) error) (string, error) {
+	u, meta, err := loadCorim(corimFile)
+	if err != nil {
+		return "", err
+	}
+
+	if outputFile == "" {
+		if meta != nil {
+			outputFile = makeFileName(filepath.Dir(corimFile), corimFile, "-unsigned.cbor")
+		} else {
+			outputFile = corimFile
+		}
+	}
+
+	if err = edit(u); err != nil {
+		return "", err
+	}
+
+	if err = saveUnsignedCorim(u, outputFile); err != nil {
+		return "", err
+	}
+
+	if meta != nil {
+		fmt.Printf(
+			">> warning: %q was signed, %q is unsigned and must be re-signed using corim sign\n",
+			corimFile, outputFile,
+		)
+	}
+
+	return outputFile, nil
+}
+
+func init() {
+	corimCmd.AddCommand(corimAddCmd)
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/corim/corim"
+)
+
+func writeCorimAddFixtures(t *testing.T) {
+	var c comid.Comid
+	require.NoError(t, c.FromJSON(testComidACMEWileE))
+	comidCBOR, err := c.ToCBOR()
+	require.NoError(t, err)
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "corim.cbor", makeDiffCorim(t, [][]byte{testComidACMERoadRunner}, nil), 0644))
+	require.NoError(t, afero.WriteFile(fs, "signed-corim.cbor", testSignedCorimValidWithCots, 0644))
+	require.NoError(t, afero.WriteFile(fs, "comid.cbor", comidCBOR, 0644))
+	require.NoError(t, afero.WriteFile(fs, "coswid.cbor", testCoswid, 0644))
+	require.NoError(t, afero.WriteFile(fs, "bad-comid.cbor", invalidComid, 0644))
+}
+
+func Test_CorimAddCmd_mandatory_args_missing(t *testing.T) {
+	cmd := NewCorimAddCmd()
+	cmd.SetArgs([]string{"--comid=comid.cbor"})
+	assert.EqualError(t, cmd.Execute(), "no CoRIM supplied")
+
+	cmd = NewCorimAddCmd()
+	cmd.SetArgs([]string{"--file=corim.cbor"})
+	assert.EqualError(t, cmd.Execute(), "no CoMID, CoSWID or CoTS files or folders supplied")
+}
+
+func Test_CorimAddCmd_no_files_found(t *testing.T) {
+	fs = afero.NewMemMapFs()
+
+	cmd := NewCorimAddCmd()
+	cmd.SetArgs([]string{"--file=corim.cbor", "--comid=nonexistent.cbor"})
+	assert.EqualError(t, cmd.Execute(), "no CoMID, CoSWID or CoTS files found")
+}
+
+func Test_CorimAddCmd_in_place_ok(t *testing.T) {
+	writeCorimAddFixtures(t)
+
+	cmd := NewCorimAddCmd()
+	cmd.SetArgs([]string{"--file=corim.cbor", "--comid=comid.cbor", "--coswid=coswid.cbor"})
+	require.NoError(t, cmd.Execute())
+
+	u, meta, err := loadCorim("corim.cbor")
+	require.NoError(t, err)
+	assert.Nil(t, meta)
+	require.Len(t, u.Tags, 3)
+
+	tags, err := decodeCorimTags(u)
+	require.NoError(t, err)
+	assert.Equal(t, "43bbe37f-2e61-4b33-aed3-53cff1428b16", tags[0].tagID)
+	assert.Equal(t, "urn:example:acme:wile-e", tags[1].tagID)
+	assert.Equal(t, "coswid", tags[2].typ)
+}
+
+func Test_CorimAddCmd_bad_comid(t *testing.T) {
+	writeCorimAddFixtures(t)
+
+	cmd := NewCorimAddCmd()
+	cmd.SetArgs([]string{"--file=corim.cbor", "--comid=bad-comid.cbor", "--output=new.cbor"})
+	assert.ErrorContains(t, cmd.Execute(), "error loading CoMID from bad-comid.cbor: ")
+
+	_, err := fs.Stat("new.cbor")
+	assert.Error(t, err)
+}
+
+func Test_CorimAddCmd_signed_input(t *testing.T) {
+	writeCorimAddFixtures(t)
+
+	var s corim.SignedCorim
+	require.NoError(t, s.FromCOSE(testSignedCorimValidWithCots))
+
+	// the unsigned result is saved next to the signed CoRIM, which is left
+	// untouched
+	cmd := NewCorimAddCmd()
+	cmd.SetArgs([]string{"--file=signed-corim.cbor", "--comid=comid.cbor"})
+	require.NoError(t, cmd.Execute())
+
+	signed, err := afero.ReadFile(fs, "signed-corim.cbor")
+	require.NoError(t, err)
+	assert.Equal(t, testSignedCorimValidWithCots, signed)
+
+	u, meta, err := loadCorim("signed-corim-unsigned.cbor")
+	require.NoError(t, err)
+	assert.Nil(t, meta)
+	assert.Len(t, u.Tags, len(s.UnsignedCorim.Tags)+1)
+
+	cmd = NewCorimAddCmd()
+	cmd.SetArgs([]string{"--file=signed-corim.cbor", "--comid=comid.cbor", "--output=unsigned.cbor"})
+	require.NoError(t, cmd.Execute())
+
+	u, meta, err = loadCorim("unsigned.cbor")
+	require.NoError(t, err)
+	assert.Nil(t, meta)
+	assert.Len(t, u.Tags, len(s.UnsignedCorim.Tags)+1)
+}
@@ -0,0 +1,188 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/veraison/corim/corim"
+)
+
+var (
+	corimCosignCorimFile         *string
+	corimCosignKeyFile           *string
+	corimCosignCertFile          *string
+	corimCosignIntermediateCerts *string
+	corimCosignPassphraseFile    *string
+	corimCosignKid               *string
+	corimCosignPayload           *string
+	corimCosignOutputFile        *string
+)
+
+var corimCosignCmd = NewCorimCosignCmd()
+
+func NewCorimCosignCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cosign",
+		Short: "append a signature to a multi-signed CoRIM using the supplied key",
+		Long: `append a signature to a multi-signed CoRIM using the supplied key
+
+    Add a signature made with the key in oem.jwk to the multi-signed CoRIM
+    (COSE Sign) in signed-corim.cbor, which is updated in place.  The CoRIM
+    must have been created by corim sign with more than one key, or with the
+    --multi-signer switch.  The existing signatures are left untouched.
+
+      cocli corim cosign --file=signed-corim.cbor --key=oem.jwk
+
+    As for corim sign, the key can be a JWK, a PEM or DER-encoded private key,
+    or a PKCS#11 URI, and the signing certificate and certificate chain can be
+    included in the protected header of the new signature.  Save the result to
+    a different file:
+
+      cocli corim cosign --file=signed-corim.cbor \
+                     --key=oem.jwk \
+                     --cert=oem.der \
+                     --intermediates=oem-intermediates.der \
+                     --output=cosigned-corim.cbor
+
+    If the CoRIM has a detached payload, the payload must be supplied:
+
+      cocli corim cosign --file=signature.cbor \
+                     --payload=payload.cbor \
+                     --key=oem.jwk
+    `,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkCorimCosignArgs(); err != nil {
+				return err
+			}
+
+			spec := signerSpec{
+				newSigner:         keySignerFactory(*corimCosignKeyFile, keyPassphrase(*corimCosignPassphraseFile)),
+				certFile:          *corimCosignCertFile,
+				intermediatesFile: *corimCosignIntermediateCerts,
+			}
+
+			outputFile := *corimCosignOutputFile
+			if outputFile == "" {
+				outputFile = *corimCosignCorimFile
+			}
+
+			err := cosign(*corimCosignCorimFile, *corimCosignPayload, outputFile, spec, []byte(*corimCosignKid))
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf(">> %q co-signed and saved to %q\n", *corimCosignCorimFile, outputFile)
+
+			return nil
+		},
+	}
+
+	corimCosignCorimFile = cmd.Flags().StringP("file", "f", "", "a multi-signed CoRIM file (in CBOR format)")
+	corimCosignKeyFile = cmd.Flags().StringP(
+		"key", "k", "", "signing key (JWK, or PEM/DER-encoded PKCS#8, SEC1 or PKCS#1), or a PKCS#11 URI",
+	)
+	corimCosignCertFile = cmd.Flags().StringP("cert", "c", "", "signing certificate in DER format")
+	corimCosignIntermediateCerts = cmd.Flags().String("intermediates", "", "intermediate certificates in DER format")
+	corimCosignPassphraseFile = cmd.Flags().String(
+		"passphrase-file", "", "file containing the passphrase of an encrypted signing key",
+	)
+	corimCosignKid = cmd.Flags().String(
+		"kid", "", "key identifier to set in the protected header (default: the JWK \"kid\", if any)",
+	)
+	corimCosignPayload = cmd.Flags().String(
+		"payload", "", "the unsigned CoRIM file (in CBOR format) for a detached signature",
+	)
+	corimCosignOutputFile = cmd.Flags().StringP(
+		"output", "o", "", "name of the generated COSE Sign file (default: the input file)",
+	)
+
+	return cmd
+}
+
+func checkCorimCosignArgs() error {
+	if corimCosignCorimFile == nil || *corimCosignCorimFile == "" {
+		return errors.New("no CoRIM supplied")
+	}
+
+	if corimCosignKeyFile == nil || *corimCosignKeyFile == "" {
+		return errors.New("no key supplied")
+	}
+
+	return nil
+}
+
+// cosign appends a signature from the supplied signer to the multi-signed CoRIM
+// in signedCorimFile and saves the result to outputFile
+func cosign(signedCorimFile, payloadFile, outputFile string, spec signerSpec, kid []byte) error {
+	var (
+		signedCorimCBOR []byte
+		payload         []byte
+		err             error
+	)
+
+	if signedCorimCBOR, err = afero.ReadFile(fs, signedCorimFile); err != nil {
+		return fmt.Errorf("error loading signed CoRIM from %s: %w", signedCorimFile, err)
+	}
+
+	if !isMultiSigned(signedCorimCBOR) {
+		return fmt.Errorf(
+			"%s is not a multi-signed CoRIM: use corim sign with --multi-signer to create one",
+			signedCorimFile,
+		)
+	}
+
+	// make sure the CoRIM is well-formed and valid before signing it
+	if _, err = loadSignedCorim(signedCorimFile, payloadFile); err != nil {
+		return err
+	}
+
+	if payloadFile != "" {
+		if payload, err = afero.ReadFile(fs, payloadFile); err != nil {
+			return fmt.Errorf("error loading payload from %s: %w", payloadFile, err)
+		}
+	}
+
+	// the SignedCorim is only used to hold the certificates of the signer
+	var s corim.SignedCorim
+
+	if err = addSignerCerts(&s, spec); err != nil {
+		return err
+	}
+
+	key, err := spec.newSigner(&s)
+	if err != nil {
+		return err
+	}
+	defer key.release()
+
+	// an explicit key identifier takes precedence over the one from the key
+	if len(kid) == 0 {
+		kid = []byte(key.kid)
+	}
+
+	signedCorimCBOR, err = cosignCorim(signedCorimCBOR, payload, coseSigner{
+		signer:            key.signer,
+		kid:               kid,
+		signingCert:       s.SigningCert,
+		intermediateCerts: s.IntermediateCerts,
+	})
+	if err != nil {
+		return fmt.Errorf("error signing CoRIM: %w", err)
+	}
+
+	if err = afero.WriteFile(fs, outputFile, signedCorimCBOR, 0644); err != nil {
+		return fmt.Errorf("error saving signed CoRIM to file %s: %w", outputFile, err)
+	}
+
+	return nil
+}
+
+func init() {
+	corimCmd.AddCommand(corimCosignCmd)
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CorimCosignCmd_unknown_argument(t *testing.T) {
+	cmd := NewCorimCosignCmd()
+
+	args := []string{"--unknown-argument=val"}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "unknown flag: --unknown-argument")
+}
+
+func Test_CorimCosignCmd_mandatory_args_missing_corim_file(t *testing.T) {
+	cmd := NewCorimCosignCmd()
+	cmd.SetArgs([]string{"--key=ok.jwk"})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "no CoRIM supplied")
+}
+
+func Test_CorimCosignCmd_mandatory_args_missing_key_file(t *testing.T) {
+	cmd := NewCorimCosignCmd()
+	cmd.SetArgs([]string{"--file=signed-ok.cbor"})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "no key supplied")
+}
+
+func Test_CorimCosignCmd_ok(t *testing.T) {
+	pki := makeTestPKI(t)
+	writeMultiSignerFixtures(t, pki)
+
+	sign := NewCorimSignCmd()
+	sign.SetArgs([]string{"--file=ok.cbor", "--meta=ok.json", "--key=ok.jwk", "--multi-signer"})
+	require.NoError(t, sign.Execute())
+
+	cmd := NewCorimCosignCmd()
+	cmd.SetArgs([]string{
+		"--file=signed-ok.cbor",
+		"--key=signer0.pem",
+		"--cert=signer0.der",
+		"--intermediates=signer0-int.der",
+		"--kid=oem",
+	})
+	require.NoError(t, cmd.Execute())
+
+	vc, err := loadSignedCorim("signed-ok.cbor", "")
+	require.NoError(t, err)
+	require.Len(t, vc.Signatures, 2)
+
+	pk, err := newPublicKeyFromKeyData(testECKey)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), vc.Signatures[0].kid)
+	assert.NoError(t, vc.Signatures[0].verify(pk))
+
+	assert.Equal(t, []byte("oem"), vc.Signatures[1].kid)
+	require.NotNil(t, vc.Signatures[1].signingCert)
+	assert.Equal(t, pki.Leaf, vc.Signatures[1].signingCert.Raw)
+	assert.NoError(t, vc.Signatures[1].verify(pki.LeafKey.Public()))
+}
+
+func Test_CorimCosignCmd_custom_output_file(t *testing.T) {
+	writeMultiSignerFixtures(t, makeTestPKI(t))
+
+	sign := NewCorimSignCmd()
+	sign.SetArgs([]string{"--file=ok.cbor", "--meta=ok.json", "--key=ok.jwk", "--multi-signer"})
+	require.NoError(t, sign.Execute())
+
+	cmd := NewCorimCosignCmd()
+	cmd.SetArgs([]string{"--file=signed-ok.cbor", "--key=signer0.pem", "--output=cosigned.cbor"})
+	require.NoError(t, cmd.Execute())
+
+	vc, err := loadSignedCorim("signed-ok.cbor", "")
+	require.NoError(t, err)
+	assert.Len(t, vc.Signatures, 1)
+
+	vc, err = loadSignedCorim("cosigned.cbor", "")
+	require.NoError(t, err)
+	assert.Len(t, vc.Signatures, 2)
+}
+
+func Test_CorimCosignCmd_detached_ok(t *testing.T) {
+	pki := makeTestPKI(t)
+	writeMultiSignerFixtures(t, pki)
+
+	sign := NewCorimSignCmd()
+	sign.SetArgs([]string{"--file=ok.cbor", "--meta=ok.json", "--key=ok.jwk", "--multi-signer", "--detached"})
+	require.NoError(t, sign.Execute())
+
+	cmd := NewCorimCosignCmd()
+	cmd.SetArgs([]string{"--file=signed-ok.cbor", "--key=signer0.pem"})
+	assert.EqualError(t, cmd.Execute(), "signed-ok.cbor has a detached payload: use --payload")
+
+	cmd = NewCorimCosignCmd()
+	cmd.SetArgs([]string{"--file=signed-ok.cbor", "--key=signer0.pem", "--payload=signed-ok.payload.cbor"})
+	require.NoError(t, cmd.Execute())
+
+	signed, err := afero.ReadFile(fs, "signed-ok.cbor")
+	require.NoError(t, err)
+
+	_, err = attachPayload(signed, nil)
+	require.NoError(t, err, "the payload must still be detached")
+
+	vc, err := loadSignedCorim("signed-ok.cbor", "signed-ok.payload.cbor")
+	require.NoError(t, err)
+	require.Len(t, vc.Signatures, 2)
+	assert.NoError(t, vc.Signatures[1].verify(pki.LeafKey.Public()))
+}
+
+func Test_CorimCosignCmd_not_multi_signed(t *testing.T) {
+	writeMultiSignerFixtures(t)
+
+	sign := NewCorimSignCmd()
+	sign.SetArgs([]string{"--file=ok.cbor", "--meta=ok.json", "--key=ok.jwk"})
+	require.NoError(t, sign.Execute())
+
+	cmd := NewCorimCosignCmd()
+	cmd.SetArgs([]string{"--file=signed-ok.cbor", "--key=ok.jwk"})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err,
+		"signed-ok.cbor is not a multi-signed CoRIM: use corim sign with --multi-signer to create one")
+}
+
+func Test_CorimCosignCmd_non_existent_signed_corim_file(t *testing.T) {
+	fs = afero.NewMemMapFs()
+
+	cmd := NewCorimCosignCmd()
+	cmd.SetArgs([]string{"--file=nonexistent.cbor", "--key=ok.jwk"})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "error loading signed CoRIM from nonexistent.cbor: open nonexistent.cbor: file does not exist")
+}
@@ -79,7 +79,7 @@ func NewCorimCreateCmd() *cobra.Command {
 		},
 	}
 
-	corimCreateCorimFile = cmd.Flags().StringP("template", "t", "", "a CoRIM template file (in JSON format)")
+	corimCreateCorimFile = cmd.Flags().StringP("template", "t", "", "a CoRIM template file (in JSON or YAML format)")
 
 	cmd.Flags().StringArrayVarP(
 		&corimCreateComidDirs, "comid-dir", "M", []string{}, "a directory containing CBOR-encoded CoMID files",
@@ -136,10 +136,43 @@ func corimTemplateToCBOR(tmplFile string, comidFiles, coswidFiles, cotsFiles []s
 		return "", fmt.Errorf("error loading template from %s: %w", tmplFile, err)
 	}
 
-	if err = c.FromJSON(tmplData); err != nil {
+	if err = decodeTemplate(&c, tmplData, tmplFile); err != nil {
 		return "", fmt.Errorf("error decoding template from %s: %w", tmplFile, err)
 	}
 
+	if err = addCorimTags(&c, comidFiles, coswidFiles, cotsFiles); err != nil {
+		return "", err
+	}
+
+	// check the result
+	if err = c.Valid(); err != nil {
+		return "", fmt.Errorf("error validating CoRIM: %w", err)
+	}
+
+	corimCBOR, err = c.ToCBOR()
+	if err != nil {
+		return "", fmt.Errorf("error encoding CoRIM to CBOR: %w", err)
+	}
+
+	if outputFile == nil || *outputFile == "" {
+		corimFile = makeFileName("", tmplFile, ".cbor")
+	} else {
+		corimFile = *outputFile
+	}
+
+	err = afero.WriteFile(fs, corimFile, corimCBOR, 0644)
+	if err != nil {
+		return "", fmt.Errorf("error saving CoRIM to file %s: %w", corimFile, err)
+	}
+
+	return corimFile, nil
+}
+
+// addCorimTags appends the CoMIDs, CoSWIDs and CoTS from the supplied
+// CBOR-encoded files to the CoRIM
+func addCorimTags(c *corim.UnsignedCorim, comidFiles, coswidFiles, cotsFiles []string) error {
+	var err error
+
 	// append CoMID(s)
 	for _, comidFile := range comidFiles {
 		var (
@@ -149,16 +182,16 @@ func corimTemplateToCBOR(tmplFile string, comidFiles, coswidFiles, cotsFiles []s
 
 		comidCBOR, err = afero.ReadFile(fs, comidFile)
 		if err != nil {
-			return "", fmt.Errorf("error loading CoMID from %s: %w", comidFile, err)
+			return fmt.Errorf("error loading CoMID from %s: %w", comidFile, err)
 		}
 
 		err = m.FromCBOR(comidCBOR)
 		if err != nil {
-			return "", fmt.Errorf("error loading CoMID from %s: %w", comidFile, err)
+			return fmt.Errorf("error loading CoMID from %s: %w", comidFile, err)
 		}
 
 		if c.AddComid(&m) == nil {
-			return "", fmt.Errorf(
+			return fmt.Errorf(
 				"error adding CoMID from %s (check its validity using the %q sub-command)",
 				comidFile, "comid validate",
 			)
@@ -174,16 +207,16 @@ func corimTemplateToCBOR(tmplFile string, comidFiles, coswidFiles, cotsFiles []s
 
 		coswidCBOR, err = afero.ReadFile(fs, coswidFile)
 		if err != nil {
-			return "", fmt.Errorf("error loading CoSWID from %s: %w", coswidFile, err)
+			return fmt.Errorf("error loading CoSWID from %s: %w", coswidFile, err)
 		}
 
 		err = s.FromCBOR(coswidCBOR)
 		if err != nil {
-			return "", fmt.Errorf("error loading CoSWID from %s: %w", coswidFile, err)
+			return fmt.Errorf("error loading CoSWID from %s: %w", coswidFile, err)
 		}
 
 		if c.AddCoswid(&s) == nil {
-			return "", fmt.Errorf("error adding CoSWID from %s", coswidFile)
+			return fmt.Errorf("error adding CoSWID from %s", coswidFile)
 		}
 	}
 
@@ -196,41 +229,20 @@ func corimTemplateToCBOR(tmplFile string, comidFiles, coswidFiles, cotsFiles []s
 
 		cotsCBOR, err = afero.ReadFile(fs, cotsFile)
 		if err != nil {
-			return "", fmt.Errorf("error loading CoTS from %s: %w", cotsFile, err)
+			return fmt.Errorf("error loading CoTS from %s: %w", cotsFile, err)
 		}
 
 		err = t.FromCBOR(cotsCBOR)
 		if err != nil {
-			return "", fmt.Errorf("error loading CoTS from %s: %w", cotsFile, err)
+			return fmt.Errorf("error loading CoTS from %s: %w", cotsFile, err)
 		}
 
 		if c.AddCots(&t) == nil {
-			return "", fmt.Errorf("error adding CoTS from %s", cotsFile)
+			return fmt.Errorf("error adding CoTS from %s", cotsFile)
 		}
 	}
 
-	// check the result
-	if err = c.Valid(); err != nil {
-		return "", fmt.Errorf("error validating CoRIM: %w", err)
-	}
-
-	corimCBOR, err = c.ToCBOR()
-	if err != nil {
-		return "", fmt.Errorf("error encoding CoRIM to CBOR: %w", err)
-	}
-
-	if outputFile == nil || *outputFile == "" {
-		corimFile = makeFileName("", tmplFile, ".cbor")
-	} else {
-		corimFile = *outputFile
-	}
-
-	err = afero.WriteFile(fs, corimFile, corimCBOR, 0644)
-	if err != nil {
-		return "", fmt.Errorf("error saving CoRIM to file %s: %w", corimFile, err)
-	}
-
-	return corimFile, nil
+	return nil
 }
 
 func init() {
@@ -0,0 +1,630 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/corim/corim"
+	"github.com/veraison/corim/cots"
+	"github.com/veraison/swid"
+)
+
+var (
+	corimDecompileCorimFile *string
+	corimDecompileOutputDir *string
+)
+
+var corimDecompileCmd = NewCorimDecompileCmd()
+
+func NewCorimDecompileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "decompile",
+		Short: "turn a CoRIM into the JSON templates it can be re-created from",
+		Long: `turn a CoRIM into the JSON templates it can be re-created from
+
+    Decompile the signed CoRIM in signed-corim.cbor into the templates/
+    directory, which must exist.
+
+      cocli corim decompile --file=signed-corim.cbor --output-dir=templates
+
+    The following are saved to the output directory:
+    - corim.json, the CoRIM template, for use with corim create;
+    - meta.json, the Meta template (only if the CoRIM is signed), for use with
+      corim sign;
+    - for each CoMID, a template for use with comid create (e.g.,
+      000000-comid.json);
+    - for each CoSWID, a template for use with coswid create (e.g.,
+      000001-coswid.json);
+    - for each CoTS, the environment and claims templates, and the TA and CA
+      files, for use with cots create (e.g., 000002-cots-env.json,
+      000002-cots-ta-000.der);
+    - rebuild.sh, a script with the cocli commands that re-create the CoRIM
+      from the above.
+
+    Once edited, the templates can be turned back into a CoRIM by running
+    rebuild.sh.  If the templates are left untouched, the re-created
+    (unsigned) CoRIM is byte-identical to the original.  Each tag is checked
+    for this: a tag that would not be re-created exactly from templates (e.g.,
+    a CoTS whose TAs are not in the order imposed by cots create) is saved
+    as-is in CBOR format instead (e.g., 000002-cots.cbor), and a warning is
+    printed.
+    `,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkCorimDecompileArgs(); err != nil {
+				return err
+			}
+
+			d, err := decompile(*corimDecompileCorimFile, *corimDecompileOutputDir)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf(">> decompiled %q into %q\n", *corimDecompileCorimFile, *corimDecompileOutputDir)
+			for _, f := range d.files {
+				fmt.Printf(">> [created] %q\n", f)
+			}
+
+			for _, w := range d.warnings {
+				fmt.Printf(">> warning: %s\n", w)
+			}
+
+			if !d.lossless {
+				fmt.Println(">> warning: the re-created CoRIM will not be byte-identical to the original")
+			}
+
+			return nil
+		},
+	}
+
+	corimDecompileCorimFile = cmd.Flags().StringP("file", "f", "", "a signed or unsigned CoRIM file (in CBOR format)")
+	corimDecompileOutputDir = cmd.Flags().StringP("output-dir", "o", ".", "directory to which the templates are saved")
+
+	return cmd
+}
+
+func checkCorimDecompileArgs() error {
+	if corimDecompileCorimFile == nil || *corimDecompileCorimFile == "" {
+		return errors.New("no CoRIM supplied")
+	}
+
+	return nil
+}
+
+// decompilation is the outcome of decompile
+type decompilation struct {
+	// the files saved to the output directory
+	files []string
+	// the reasons why some tags are saved as CBOR, or why the CoRIM cannot be
+	// re-created exactly
+	warnings []string
+	// whether the CoRIM re-created from the templates is identical to the
+	// original
+	lossless bool
+}
+
+// decompiledFile is a file, relative to the output directory, that is part of
+// a decompiled tag
+type decompiledFile struct {
+	name string
+	data []byte
+}
+
+// decompiledTag is an embedded tag turned into the files it can be re-created
+// from
+type decompiledTag struct {
+	typ   string
+	files []decompiledFile
+	// the cocli commands that create cborFile from files (none, if cborFile
+	// is one of files)
+	commands [][]string
+	// the CBOR file that is added to the CoRIM
+	cborFile string
+	// the tag re-created from files, as corim create would add it
+	rebuilt corim.Tag
+}
+
+// decompile saves the CoRIM in corimFile to outputDir as the templates, and
+// other files, it can be re-created from, along with a script that does so
+func decompile(corimFile, outputDir string) (*decompilation, error) {
+	u, meta, err := loadCorim(corimFile)
+	if err != nil {
+		return nil, err
+	}
+
+	d := decompilation{lossless: true}
+
+	if err = saveCorimTemplates(u, meta, outputDir); err != nil {
+		return nil, err
+	}
+
+	d.files = append(d.files, filepath.Join(outputDir, "corim.json"))
+	if meta != nil {
+		d.files = append(d.files, filepath.Join(outputDir, "meta.json"))
+	}
+
+	// the CoRIM is re-created as corim create would, starting from the
+	// template just saved
+	tmplFile := filepath.Join(outputDir, "corim.json")
+
+	tmplData, err := afero.ReadFile(fs, tmplFile)
+	if err != nil {
+		return nil, fmt.Errorf("error loading template from %s: %w", tmplFile, err)
+	}
+
+	var rebuilt corim.UnsignedCorim
+	if err = rebuilt.FromJSON(tmplData); err != nil {
+		return nil, fmt.Errorf("error decoding template from %s: %w", tmplFile, err)
+	}
+
+	tags := make([]decompiledTag, 0, len(u.Tags))
+
+	for i, t := range u.Tags {
+		base := fmt.Sprintf("%06d", i)
+
+		dt, err := decompileTag(t, base)
+		if err != nil || !bytes.Equal(dt.rebuilt, t) {
+			reason := "it would not be re-created exactly from templates"
+			if err != nil {
+				reason = err.Error()
+			}
+
+			if dt, err = rawTag(t, base); err != nil {
+				return nil, fmt.Errorf("error decompiling tag at index %d: %w", i, err)
+			}
+
+			d.warnings = append(d.warnings, fmt.Sprintf("tag at index %d saved as CBOR: %s", i, reason))
+		}
+
+		for _, f := range dt.files {
+			name := filepath.Join(outputDir, f.name)
+			if err = afero.WriteFile(fs, name, f.data, 0644); err != nil {
+				return nil, fmt.Errorf("error saving %s: %w", name, err)
+			}
+			d.files = append(d.files, name)
+		}
+
+		if !bytes.Equal(dt.rebuilt, t) {
+			d.warnings = append(d.warnings, fmt.Sprintf("tag at index %d cannot be re-created exactly", i))
+			d.lossless = false
+		}
+
+		rebuilt.Tags = append(rebuilt.Tags, dt.rebuilt)
+		tags = append(tags, dt)
+	}
+
+	if ok, err := sameCorim(u, &rebuilt); err != nil {
+		return nil, err
+	} else if !ok {
+		d.warnings = append(d.warnings, "the CoRIM template does not re-create the CoRIM exactly")
+		d.lossless = false
+	}
+
+	scriptFile := filepath.Join(outputDir, "rebuild.sh")
+	script := rebuildScript(corimFile, tags, meta != nil)
+
+	if err = afero.WriteFile(fs, scriptFile, []byte(script), 0755); err != nil {
+		return nil, fmt.Errorf("error saving %s: %w", scriptFile, err)
+	}
+
+	d.files = append(d.files, scriptFile)
+
+	return &d, nil
+}
+
+// sameCorim tells whether the two CoRIMs have the same CBOR encoding
+func sameCorim(a, b *corim.UnsignedCorim) (bool, error) {
+	aCBOR, err := a.ToCBOR()
+	if err != nil {
+		return false, fmt.Errorf("error encoding CoRIM to CBOR: %w", err)
+	}
+
+	bCBOR, err := b.ToCBOR()
+	if err != nil {
+		return false, fmt.Errorf("error encoding re-created CoRIM to CBOR: %w", err)
+	}
+
+	return bytes.Equal(aCBOR, bCBOR), nil
+}
+
+// decompileTag turns the embedded tag into templates, named after base, and
+// re-creates it from them
+func decompileTag(t corim.Tag, base string) (decompiledTag, error) {
+	tag, err := decodeCorimTag(t)
+	if err != nil {
+		return decompiledTag{}, err
+	}
+
+	switch v := tag.value.(type) {
+	case *comid.Comid:
+		return decompileComid(v, base)
+	case *swid.SoftwareIdentity:
+		return decompileCoswid(v, base)
+	case *cots.ConciseTaStore:
+		return decompileCots(v, base)
+	default:
+		return decompiledTag{}, fmt.Errorf("unexpected tag type %T", v)
+	}
+}
+
+// decompileComid saves the CoMID as a template, which is re-created as comid
+// create would
+func decompileComid(c *comid.Comid, base string) (decompiledTag, error) {
+	dt := decompiledTag{typ: "comid", cborFile: base + "-comid.cbor"}
+
+	tmplFile := base + "-comid.json"
+
+	tmplData, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return dt, fmt.Errorf("error encoding CoMID template: %w", err)
+	}
+
+	dt.files = []decompiledFile{{tmplFile, tmplData}}
+	dt.commands = [][]string{{"comid", "create", "--template=" + tmplFile}}
+
+	var m comid.Comid
+	if err = m.FromJSON(tmplData); err != nil {
+		return dt, fmt.Errorf("error decoding CoMID template: %w", err)
+	}
+
+	if err = m.Valid(); err != nil {
+		return dt, fmt.Errorf("error validating CoMID template: %w", err)
+	}
+
+	cborData, err := m.ToCBOR()
+	if err != nil {
+		return dt, fmt.Errorf("error encoding CoMID template to CBOR: %w", err)
+	}
+
+	dt.rebuilt, err = rebuildTag(dt.typ, cborData)
+
+	return dt, err
+}
+
+// decompileCoswid saves the CoSWID as a template, which is re-created as
+// coswid create would
+func decompileCoswid(s *swid.SoftwareIdentity, base string) (decompiledTag, error) {
+	dt := decompiledTag{typ: "coswid", cborFile: base + "-coswid.cbor"}
+
+	tmplFile := base + "-coswid.json"
+
+	tmplData, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return dt, fmt.Errorf("error encoding CoSWID template: %w", err)
+	}
+
+	dt.files = []decompiledFile{{tmplFile, tmplData}}
+	dt.commands = [][]string{{"coswid", "create", "--template=" + tmplFile}}
+
+	var r swid.SoftwareIdentity
+	if err = r.FromJSON(tmplData); err != nil {
+		return dt, fmt.Errorf("error decoding CoSWID template: %w", err)
+	}
+
+	if err = validCoswid(&r); err != nil {
+		return dt, fmt.Errorf("error validating CoSWID template: %w", err)
+	}
+
+	cborData, err := r.ToCBOR()
+	if err != nil {
+		return dt, fmt.Errorf("error encoding CoSWID template to CBOR: %w", err)
+	}
+
+	dt.rebuilt, err = rebuildTag(dt.typ, cborData)
+
+	return dt, err
+}
+
+// taFileExts maps TA formats to the file extensions cots create expects, in
+// the order in which cots create adds them
+var taFileExts = []struct {
+	format cots.TaFormat
+	ext    string
+}{
+	{cots.TaFormatCertificate, ".der"},
+	{cots.TaFormatTrustAnchorInfo, ".ta"},
+	{cots.TaFormatSubjectPublicKeyInfo, ".spki"},
+}
+
+// decompileCots saves the CoTS as the templates, TA and CA files, and
+// options, that cots create takes, and re-creates it as cots create would
+func decompileCots(c *cots.ConciseTaStore, base string) (decompiledTag, error) {
+	dt := decompiledTag{typ: "cots", cborFile: base + "-cots.cbor"}
+
+	// cots create takes at most one claims template of each kind, and
+	// requires TAs
+	if len(c.PermClaims) > 1 || len(c.ExclClaims) > 1 {
+		return dt, errors.New("cots create supports at most one set of permitted and excluded claims")
+	}
+
+	if c.Keys == nil || len(c.Keys.Tas) == 0 {
+		return dt, errors.New("cots create requires TAs")
+	}
+
+	var (
+		r    cots.ConciseTaStore
+		args = []string{"cots", "create"}
+		err  error
+	)
+
+	envFile := base + "-cots-env.json"
+
+	envData, err := json.MarshalIndent(c.Environments, "", "  ")
+	if err != nil {
+		return dt, fmt.Errorf("error encoding CoTS environment template: %w", err)
+	}
+
+	if err = r.Environments.FromJSON(envData); err != nil {
+		return dt, fmt.Errorf("error decoding CoTS environment template: %w", err)
+	}
+
+	dt.files = append(dt.files, decompiledFile{envFile, envData})
+	args = append(args, "--environment="+envFile)
+
+	if c.Language != nil {
+		r.SetLanguage(*c.Language)
+		args = append(args, "--language="+*c.Language)
+	}
+
+	if c.TagIdentity != nil {
+		version := c.TagIdentity.TagVersion
+		tagID := c.TagIdentity.TagID.String()
+
+		if u, err := uuid.Parse(tagID); err == nil {
+			r.SetTagIdentity(u[:], &version)
+			args = append(args, "--uuid-str="+tagID)
+		} else {
+			r.SetTagIdentity(tagID, &version)
+			args = append(args, "--id="+tagID)
+		}
+
+		if version != 0 {
+			args = append(args, fmt.Sprintf("--tag-version=%d", version))
+		}
+	}
+
+	claims := []struct {
+		kind   string
+		claims cots.EatCWTClaims
+		add    func(*cots.EatCWTClaim) *cots.ConciseTaStore
+	}{
+		{"permclaims", c.PermClaims, r.AddPermClaims},
+		{"exclclaims", c.ExclClaims, r.AddExclClaims},
+	}
+
+	for _, cl := range claims {
+		if len(cl.claims) == 0 {
+			continue
+		}
+
+		claimsFile := base + "-cots-" + cl.kind + ".json"
+
+		claimsData, err := json.MarshalIndent(cl.claims[0], "", "  ")
+		if err != nil {
+			return dt, fmt.Errorf("error encoding CoTS %s template: %w", cl.kind, err)
+		}
+
+		var claim cots.EatCWTClaim
+		if err = claim.FromJSON(claimsData); err != nil {
+			return dt, fmt.Errorf("error decoding CoTS %s template: %w", cl.kind, err)
+		}
+
+		cl.add(&claim)
+
+		dt.files = append(dt.files, decompiledFile{claimsFile, claimsData})
+		args = append(args, "--"+cl.kind+"="+claimsFile)
+	}
+
+	for _, p := range c.Purposes {
+		r.AddPurpose(p)
+		args = append(args, "--purpose="+p)
+	}
+
+	r.Keys = &cots.TasAndCas{}
+
+	// cots create adds the TAs grouped by format, whatever the order in which
+	// they are supplied
+	for _, e := range taFileExts {
+		for i, ta := range c.Keys.Tas {
+			if ta.Format != e.format {
+				continue
+			}
+
+			taFile := fmt.Sprintf("%s-cots-ta-%03d%s", base, i, e.ext)
+
+			r.Keys.Tas = append(r.Keys.Tas, cots.TrustAnchor{Format: ta.Format, Data: ta.Data})
+			dt.files = append(dt.files, decompiledFile{taFile, ta.Data})
+			args = append(args, "--tafile="+taFile)
+		}
+	}
+
+	if len(r.Keys.Tas) != len(c.Keys.Tas) {
+		return dt, errors.New("unsupported TA format")
+	}
+
+	for i, ca := range c.Keys.Cas {
+		caFile := fmt.Sprintf("%s-cots-ca-%03d.der", base, i)
+
+		r.Keys.Cas = append(r.Keys.Cas, ca)
+		dt.files = append(dt.files, decompiledFile{caFile, ca})
+		args = append(args, "--cafile="+caFile)
+	}
+
+	args = append(args, "--output="+dt.cborFile)
+	dt.commands = [][]string{args}
+
+	if err = r.Valid(); err != nil {
+		return dt, fmt.Errorf("error validating CoTS: %w", err)
+	}
+
+	cborData, err := r.ToCBOR()
+	if err != nil {
+		return dt, fmt.Errorf("error encoding CoTS to CBOR: %w", err)
+	}
+
+	dt.rebuilt, err = rebuildTag(dt.typ, cborData)
+
+	return dt, err
+}
+
+// rawTag saves the embedded tag as-is, in CBOR format, and re-creates it as
+// corim create would
+func rawTag(t corim.Tag, base string) (decompiledTag, error) {
+	typ, cborData, err := splitCorimTag(t)
+	if err != nil {
+		return decompiledTag{}, err
+	}
+
+	dt := decompiledTag{typ: typ, cborFile: base + "-" + typ + ".cbor"}
+	dt.files = []decompiledFile{{dt.cborFile, cborData}}
+
+	// a tag that corim create rejects is added as-is, so that it is reported
+	// as not re-created exactly
+	rebuilt, err := rebuildTag(typ, cborData)
+	if err != nil {
+		rebuilt = nil
+	}
+
+	dt.rebuilt = rebuilt
+
+	return dt, nil
+}
+
+// rebuildTag returns the embedded tag that corim create (and corim add) make
+// from the supplied CBOR-encoded CoMID, CoSWID or CoTS
+func rebuildTag(typ string, data []byte) (corim.Tag, error) {
+	var c corim.UnsignedCorim
+
+	switch typ {
+	case "comid":
+		var m comid.Comid
+		if err := m.FromCBOR(data); err != nil {
+			return nil, fmt.Errorf("error decoding CoMID: %w", err)
+		}
+		if c.AddComid(&m) == nil {
+			return nil, errors.New("error adding CoMID")
+		}
+	case "coswid":
+		var s swid.SoftwareIdentity
+		if err := s.FromCBOR(data); err != nil {
+			return nil, fmt.Errorf("error decoding CoSWID: %w", err)
+		}
+		if c.AddCoswid(&s) == nil {
+			return nil, errors.New("error adding CoSWID")
+		}
+	case "cots":
+		var t cots.ConciseTaStore
+		if err := t.FromCBOR(data); err != nil {
+			return nil, fmt.Errorf("error decoding CoTS: %w", err)
+		}
+		if c.AddCots(&t) == nil {
+			return nil, errors.New("error adding CoTS")
+		}
+	default:
+		return nil, fmt.Errorf("unknown tag type %q", typ)
+	}
+
+	return c.Tags[0], nil
+}
+
+// rebuildScript returns a shell script that re-creates the CoRIM from the
+// decompiled tags.  Since corim create (and corim add) add CoMIDs, then
+// CoSWIDs, then CoTS, tags are added in as many steps as needed to preserve
+// their order.
+func rebuildScript(corimFile string, tags []decompiledTag, signed bool) string {
+	var (
+		b    strings.Builder
+		rank = map[string]int{"comid": 0, "coswid": 1, "cots": 2}
+	)
+
+	b.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&b, "# re-create %s from the templates saved by cocli corim decompile\n", filepath.Base(corimFile))
+	b.WriteString("set -e\n")
+	b.WriteString("cd \"$(dirname \"$0\")\"\n\n")
+
+	for _, t := range tags {
+		for _, c := range t.commands {
+			writeCommand(&b, c)
+		}
+	}
+
+	var (
+		step    []string
+		created bool
+		prev    int
+	)
+
+	flush := func() {
+		if !created {
+			step = append([]string{"corim", "create", "--template=corim.json"}, step...)
+			step = append(step, "--output=corim.cbor")
+			created = true
+		} else {
+			step = append([]string{"corim", "add", "--file=corim.cbor"}, step...)
+		}
+		writeCommand(&b, step)
+		step = nil
+	}
+
+	for i, t := range tags {
+		r := rank[t.typ]
+		if i > 0 && r < prev {
+			flush()
+		}
+		step = append(step, "--"+t.typ+"="+t.cborFile)
+		prev = r
+	}
+
+	flush()
+
+	if signed {
+		b.WriteString("\n# sign the CoRIM using the original Meta and your own key\n")
+		b.WriteString("# cocli corim sign --file=corim.cbor --meta=meta.json --key=<key file> --output=signed-corim.cbor\n")
+	}
+
+	return b.String()
+}
+
+// writeCommand writes a cocli command line, quoting arguments as needed
+func writeCommand(b *strings.Builder, args []string) {
+	b.WriteString("cocli")
+	for _, a := range args {
+		b.WriteByte(' ')
+		b.WriteString(shellQuote(a))
+	}
+	b.WriteByte('\n')
+}
+
+// shellQuote quotes s for a POSIX shell, unless it is made of safe characters
+// only
+func shellQuote(s string) string {
+	safe := s != "" && strings.IndexFunc(s, func(r rune) bool {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return false
+		default:
+			return !strings.ContainsRune("-_.,:/=+@%", r)
+		}
+	}) == -1
+
+	if safe {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func init() {
+	corimCmd.AddCommand(corimDecompileCmd)
+}
@@ -0,0 +1,194 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/corim/corim"
+	"github.com/veraison/corim/cots"
+	"github.com/veraison/swid"
+)
+
+// makeDecompileCorim returns an unsigned CoRIM with a CoMID, a CoSWID, a CoTS
+// and another CoMID, in this order
+func makeDecompileCorim(t *testing.T, ts *cots.ConciseTaStore) []byte {
+	u := corim.NewUnsignedCorim().SetID("5c57e8f4-46cd-421b-91c9-08cf93e13cfc")
+	require.NotNil(t, u)
+
+	var c comid.Comid
+	require.NoError(t, c.FromJSON(testComidACMEWileE))
+	require.NotNil(t, u.AddComid(&c))
+
+	var s swid.SoftwareIdentity
+	require.NoError(t, s.FromJSON(testCoswidTemplate))
+	require.NotNil(t, u.AddCoswid(&s))
+
+	require.NotNil(t, u.AddCots(ts))
+
+	var p comid.Comid
+	require.NoError(t, p.FromCBOR(PSARefValCBOR))
+	require.NotNil(t, u.AddComid(&p))
+
+	data, err := u.ToCBOR()
+	require.NoError(t, err)
+
+	return data
+}
+
+// runRebuildScript runs the cocli commands in the rebuild.sh script saved by
+// decompile to the current directory
+func runRebuildScript(t *testing.T) {
+	script, err := afero.ReadFile(fs, "rebuild.sh")
+	require.NoError(t, err)
+
+	commands := map[string]func() *cobra.Command{
+		"comid create":  NewComidCreateCmd,
+		"coswid create": NewCoswidCreateCmd,
+		"cots create":   NewCotsCreateCtsCmd,
+		"corim create":  NewCorimCreateCmd,
+		"corim add":     NewCorimAddCmd,
+	}
+
+	for _, line := range strings.Split(string(script), "\n") {
+		if !strings.HasPrefix(line, "cocli ") {
+			continue
+		}
+
+		args := strings.Fields(line)[1:]
+
+		newCmd, ok := commands[args[0]+" "+args[1]]
+		require.True(t, ok, line)
+
+		cmd := newCmd()
+		cmd.SetArgs(args[2:])
+		require.NoError(t, cmd.Execute(), line)
+	}
+}
+
+func Test_CorimDecompileCmd_mandatory_args_missing_corim_file(t *testing.T) {
+	cmd := NewCorimDecompileCmd()
+	cmd.SetArgs([]string{"--output-dir=out"})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "no CoRIM supplied")
+}
+
+func Test_CorimDecompileCmd_bad_corim(t *testing.T) {
+	cmd := NewCorimDecompileCmd()
+	cmd.SetArgs([]string{"--file=bad.cbor"})
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "bad.cbor", []byte("hello!"), 0644))
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "error decoding CoRIM (signed or unsigned) from bad.cbor: ")
+}
+
+func Test_CorimDecompile_round_trip(t *testing.T) {
+	var ts cots.ConciseTaStore
+	require.NoError(t, ts.FromCBOR(testCots))
+
+	data := makeDecompileCorim(t, &ts)
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "input.cbor", data, 0644))
+
+	d, err := decompile("input.cbor", ".")
+	require.NoError(t, err)
+
+	assert.True(t, d.lossless)
+	assert.Empty(t, d.warnings)
+
+	for _, f := range []string{
+		"corim.json",
+		"000000-comid.json",
+		"000001-coswid.json",
+		"000002-cots-env.json",
+		"000003-comid.json",
+		"rebuild.sh",
+	} {
+		assert.Contains(t, d.files, f)
+	}
+
+	// unsigned CoRIM, no Meta
+	_, err = fs.Stat("meta.json")
+	assert.Error(t, err)
+
+	runRebuildScript(t)
+
+	rebuilt, err := afero.ReadFile(fs, "corim.cbor")
+	require.NoError(t, err)
+	assert.Equal(t, data, rebuilt)
+}
+
+func Test_CorimDecompile_raw_tag(t *testing.T) {
+	var s corim.SignedCorim
+	require.NoError(t, s.FromCOSE(testSignedCorimValidWithCots))
+	require.Len(t, s.UnsignedCorim.Tags, 1)
+
+	var ts cots.ConciseTaStore
+	require.NoError(t, ts.FromCBOR(s.UnsignedCorim.Tags[0][3:]))
+
+	// cots create adds TAs grouped by format, so a CoTS with an SPKI before
+	// the other TAs is saved as CBOR
+	tas := ts.Keys.Tas
+	require.Equal(t, cots.TaFormatSubjectPublicKeyInfo, tas[len(tas)-1].Format)
+	require.NotEqual(t, cots.TaFormatSubjectPublicKeyInfo, tas[0].Format)
+	ts.Keys.Tas = append([]cots.TrustAnchor{tas[len(tas)-1]}, tas[:len(tas)-1]...)
+
+	data := makeDecompileCorim(t, &ts)
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "input.cbor", data, 0644))
+
+	d, err := decompile("input.cbor", ".")
+	require.NoError(t, err)
+
+	assert.True(t, d.lossless)
+	require.Len(t, d.warnings, 1)
+	assert.Equal(
+		t,
+		"tag at index 2 saved as CBOR: it would not be re-created exactly from templates",
+		d.warnings[0],
+	)
+	assert.Contains(t, d.files, "000002-cots.cbor")
+
+	runRebuildScript(t)
+
+	rebuilt, err := afero.ReadFile(fs, "corim.cbor")
+	require.NoError(t, err)
+	assert.Equal(t, data, rebuilt)
+}
+
+func Test_CorimDecompileCmd_signed(t *testing.T) {
+	cmd := NewCorimDecompileCmd()
+	cmd.SetArgs([]string{"--file=signed.cbor", "--output-dir=out"})
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "signed.cbor", testSignedCorimValidWithCots, 0644))
+
+	require.NoError(t, cmd.Execute())
+
+	_, err := fs.Stat("out/meta.json")
+	assert.NoError(t, err)
+
+	script, err := afero.ReadFile(fs, "out/rebuild.sh")
+	require.NoError(t, err)
+	assert.Contains(t, string(script), "\ncocli corim create --template=corim.json --cots=000000-cots.cbor --output=corim.cbor\n")
+	assert.Contains(t, string(script), "# cocli corim sign --file=corim.cbor --meta=meta.json")
+}
+
+func Test_shellQuote(t *testing.T) {
+	assert.Equal(t, "--id=com.acme.rrd2013-ce-sp1-v4-1-5-0", shellQuote("--id=com.acme.rrd2013-ce-sp1-v4-1-5-0"))
+	assert.Equal(t, "'--language=en US'", shellQuote("--language=en US"))
+	assert.Equal(t, `'--id=it'\''s'`, shellQuote("--id=it's"))
+	assert.Equal(t, "''", shellQuote(""))
+}
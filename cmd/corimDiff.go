@@ -0,0 +1,358 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/corim/corim"
+	"github.com/veraison/corim/cots"
+	"github.com/veraison/swid"
+)
+
+var (
+	corimDiffFormat *string
+)
+
+var corimDiffCmd = NewCorimDiffCmd()
+
+func NewCorimDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <corim> <corim>",
+		Short: "compare the contents of two CoRIMs",
+		Long: `compare the contents of two CoRIMs
+
+    Report the differences between the CoRIM in old-corim.cbor and the one in
+    new-corim.cbor.  Each CoRIM can be signed (COSE Sign1 or COSE Sign) or
+    unsigned; signatures are not verified.
+
+      cocli corim diff old-corim.cbor new-corim.cbor
+
+    Embedded CoMIDs, CoSWIDs and CoTS are matched by tag-id, regardless of their
+    position, and reported as added, removed or modified.  CoTS without a
+    tag-identity are matched in the order in which they appear.  For modified
+    CoMIDs, the changes to reference values and endorsed values are shown per
+    environment and measurement key, and those to device identity and
+    attestation verification keys per environment and key.  Changes to other
+    fields of the CoRIM and of its tags are reported by field name.
+
+    Report the differences as JSON:
+
+      cocli corim diff old-corim.cbor new-corim.cbor --format=json
+    `,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkCorimDiffArgs(args); err != nil {
+				return err
+			}
+
+			d, err := diffCorimFiles(args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			return printCorimDiff(d, *corimDiffFormat)
+		},
+	}
+
+	corimDiffFormat = cmd.Flags().String("format", "text", "output format: text or json")
+
+	return cmd
+}
+
+func checkCorimDiffArgs(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expecting two CoRIM files, got %d", len(args))
+	}
+
+	if corimDiffFormat == nil {
+		return errors.New("no format supplied")
+	}
+
+	if *corimDiffFormat != "text" && *corimDiffFormat != "json" {
+		return fmt.Errorf(`invalid --format %q: expecting "text" or "json"`, *corimDiffFormat)
+	}
+
+	return nil
+}
+
+// corimDiff describes the differences between two CoRIMs
+type corimDiff struct {
+	// names of the top-level CoRIM fields, other than tags, that differ
+	Corim []string  `json:"corim,omitempty"`
+	Tags  []tagDiff `json:"tags,omitempty"`
+}
+
+// tagDiff describes an embedded tag that has been added, removed or modified
+type tagDiff struct {
+	Type   string `json:"type"`
+	TagID  string `json:"tag-id,omitempty"`
+	Change string `json:"change"`
+	// names of the top-level tag fields that differ, for modified tags
+	Fields []string `json:"fields,omitempty"`
+	// value and key triple differences, for modified CoMIDs
+	Triples []tripleDiff `json:"triples,omitempty"`
+}
+
+// corimTag is an embedded tag decoded for comparison
+type corimTag struct {
+	typ     string
+	tagID   string
+	version uint
+	value   any
+}
+
+func (o corimTag) id() string {
+	return o.typ + "\x00" + o.tagID
+}
+
+func diffCorimFiles(aFile, bFile string) (*corimDiff, error) {
+	a, _, err := loadCorim(aFile)
+	if err != nil {
+		return nil, err
+	}
+
+	b, _, err := loadCorim(bFile)
+	if err != nil {
+		return nil, err
+	}
+
+	aTags, err := decodeCorimTags(a)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding tags from %s: %w", aFile, err)
+	}
+
+	bTags, err := decodeCorimTags(b)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding tags from %s: %w", bFile, err)
+	}
+
+	d, err := diffCorims(a, b, aTags, bTags)
+	if err != nil {
+		return nil, fmt.Errorf("error comparing %s and %s: %w", aFile, bFile, err)
+	}
+
+	return d, nil
+}
+
+// decodeCorimTags decodes the CoMIDs, CoSWIDs and CoTS embedded in the CoRIM
+func decodeCorimTags(u *corim.UnsignedCorim) ([]corimTag, error) {
+	tags := make([]corimTag, 0, len(u.Tags))
+
+	for i, t := range u.Tags {
+		tag, err := decodeCorimTag(t)
+		if err != nil {
+			return nil, fmt.Errorf("tag at index %d: %w", i, err)
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// decodeCorimTag decodes an embedded CoMID, CoSWID or CoTS, and extracts its
+// tag-id and version
+func decodeCorimTag(t corim.Tag) (corimTag, error) {
+	typ, cborData, err := splitCorimTag(t)
+	if err != nil {
+		return corimTag{}, err
+	}
+
+	switch typ {
+	case "comid":
+		var c comid.Comid
+		if err = c.FromCBOR(cborData); err != nil {
+			return corimTag{}, fmt.Errorf("error decoding CoMID: %w", err)
+		}
+		return corimTag{
+			typ:     typ,
+			tagID:   c.TagIdentity.TagID.String(),
+			version: c.TagIdentity.TagVersion,
+			value:   &c,
+		}, nil
+	case "coswid":
+		var s swid.SoftwareIdentity
+		if err = s.FromCBOR(cborData); err != nil {
+			return corimTag{}, fmt.Errorf("error decoding CoSWID: %w", err)
+		}
+		var version uint
+		if s.TagVersion > 0 {
+			version = uint(s.TagVersion)
+		}
+		return corimTag{typ: typ, tagID: s.TagID.String(), version: version, value: &s}, nil
+	default: // "cots"
+		var c cots.ConciseTaStore
+		if err = c.FromCBOR(cborData); err != nil {
+			return corimTag{}, fmt.Errorf("error decoding CoTS: %w", err)
+		}
+		tag := corimTag{typ: typ, value: &c}
+		if c.TagIdentity != nil {
+			tag.tagID = c.TagIdentity.TagID.String()
+			tag.version = c.TagIdentity.TagVersion
+		}
+		return tag, nil
+	}
+}
+
+// diffCorims compares two CoRIMs, whose tags have already been decoded.  Tags
+// are matched by type and tag-id; tags sharing the same type and tag-id are
+// matched in the order in which they appear.
+func diffCorims(a, b *corim.UnsignedCorim, aTags, bTags []corimTag) (*corimDiff, error) {
+	var (
+		d   corimDiff
+		err error
+	)
+
+	if d.Corim, err = diffJSONFields(a, b, "tags"); err != nil {
+		return nil, err
+	}
+
+	aIndex := indexCorimTags(aTags)
+	bIndex := indexCorimTags(bTags)
+
+	for i, t := range aTags {
+		j, ok := bIndex.find(aIndex.keys[i])
+		if !ok {
+			d.Tags = append(d.Tags, tagDiff{Type: t.typ, TagID: t.tagID, Change: diffRemoved})
+			continue
+		}
+
+		td, err := diffCorimTag(t, bTags[j])
+		if err != nil {
+			return nil, fmt.Errorf("%s %q: %w", t.typ, t.tagID, err)
+		}
+
+		if td != nil {
+			d.Tags = append(d.Tags, *td)
+		}
+	}
+
+	for i, t := range bTags {
+		if _, ok := aIndex.find(bIndex.keys[i]); !ok {
+			d.Tags = append(d.Tags, tagDiff{Type: t.typ, TagID: t.tagID, Change: diffAdded})
+		}
+	}
+
+	return &d, nil
+}
+
+// diffCorimTag compares two versions of the same tag, and returns nil if they
+// are semantically equivalent
+func diffCorimTag(a, b corimTag) (*tagDiff, error) {
+	var (
+		td  = tagDiff{Type: a.typ, TagID: a.tagID, Change: diffModified}
+		err error
+	)
+
+	if ac, ok := a.value.(*comid.Comid); ok {
+		bc := b.value.(*comid.Comid)
+
+		if td.Fields, err = diffComidFields(ac, bc); err != nil {
+			return nil, err
+		}
+
+		if td.Triples, err = diffComidTriples(ac, bc); err != nil {
+			return nil, err
+		}
+	} else if td.Fields, err = diffJSONFields(a.value, b.value); err != nil {
+		return nil, err
+	}
+
+	if len(td.Fields) == 0 && len(td.Triples) == 0 {
+		return nil, nil
+	}
+
+	return &td, nil
+}
+
+// corimTagIndex maps the alignment key of each tag, which includes the number
+// of previous tags with the same type and tag-id, to its position
+type corimTagIndex struct {
+	keys      []string
+	positions map[string]int
+}
+
+func indexCorimTags(tags []corimTag) corimTagIndex {
+	seen := map[string]int{}
+	index := corimTagIndex{keys: make([]string, len(tags)), positions: map[string]int{}}
+
+	for i, t := range tags {
+		id := t.id()
+		index.keys[i] = fmt.Sprintf("%s\x00%d", id, seen[id])
+		index.positions[index.keys[i]] = i
+		seen[id]++
+	}
+
+	return index
+}
+
+func (o corimTagIndex) find(key string) (int, bool) {
+	i, ok := o.positions[key]
+	return i, ok
+}
+
+// empty reports whether there are no differences
+func (o corimDiff) empty() bool {
+	return len(o.Corim) == 0 && len(o.Tags) == 0
+}
+
+// text returns the differences in human-readable form
+func (o corimDiff) text() string {
+	var w strings.Builder
+
+	if len(o.Corim) != 0 {
+		fmt.Fprintf(&w, "~ corim (%s)\n", strings.Join(o.Corim, ", "))
+	}
+
+	for _, t := range o.Tags {
+		tagID := fmt.Sprintf("%q", t.TagID)
+		if t.TagID == "" {
+			tagID = "(no tag-id)"
+		}
+
+		fmt.Fprintf(&w, "%s %s %s", diffSymbol(t.Change), t.Type, tagID)
+
+		if len(t.Fields) != 0 {
+			fmt.Fprintf(&w, " (%s)", strings.Join(t.Fields, ", "))
+		}
+
+		w.WriteString("\n")
+
+		writeTripleDiffs(&w, t.Triples, "    ")
+	}
+
+	return w.String()
+}
+
+func printCorimDiff(d *corimDiff, format string) error {
+	if format == "json" {
+		j, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding differences: %w", err)
+		}
+
+		fmt.Println(string(j))
+
+		return nil
+	}
+
+	if d.empty() {
+		fmt.Println(">> no differences")
+		return nil
+	}
+
+	fmt.Print(d.text())
+
+	return nil
+}
+
+func init() {
+	corimCmd.AddCommand(corimDiffCmd)
+}
@@ -0,0 +1,229 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/corim/corim"
+	"github.com/veraison/swid"
+)
+
+var testComidACMEWileE = []byte(`{
+	"tag-identity": {
+		"id": "urn:example:acme:wile-e"
+	},
+	"triples": {
+		"reference-values": [
+			{
+				"environment": {
+					"class": {
+						"vendor": "ACME",
+						"model": "WileE"
+					}
+				},
+				"measurements": [
+					{
+						"key": { "type": "uint", "value": 1 },
+						"value": {
+							"raw-value": { "type": "bytes", "value": "AAEC" }
+						}
+					}
+				]
+			}
+		]
+	}
+}`)
+
+// makeDiffCorim encodes an unsigned CoRIM with the supplied CoMID (JSON) and
+// CoSWID (JSON) tags
+func makeDiffCorim(t *testing.T, comids [][]byte, coswids [][]byte) []byte {
+	u := corim.NewUnsignedCorim().SetID("5c57e8f4-46cd-421b-91c9-08cf93e13cfc")
+	require.NotNil(t, u)
+
+	for _, j := range comids {
+		var c comid.Comid
+		require.NoError(t, c.FromJSON(j))
+		require.NotNil(t, u.AddComid(&c))
+	}
+
+	for _, j := range coswids {
+		var s swid.SoftwareIdentity
+		require.NoError(t, s.FromJSON(j))
+		require.NotNil(t, u.AddCoswid(&s))
+	}
+
+	data, err := u.ToCBOR()
+	require.NoError(t, err)
+
+	return data
+}
+
+func diffCorimBytes(t *testing.T, a, b []byte) *corimDiff {
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "a.cbor", a, 0644))
+	require.NoError(t, afero.WriteFile(fs, "b.cbor", b, 0644))
+
+	d, err := diffCorimFiles("a.cbor", "b.cbor")
+	require.NoError(t, err)
+
+	return d
+}
+
+func Test_CorimDiffCmd_bad_args(t *testing.T) {
+	cmd := NewCorimDiffCmd()
+	cmd.SetArgs([]string{"a.cbor"})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "expecting two CoRIM files, got 1")
+
+	cmd = NewCorimDiffCmd()
+	cmd.SetArgs([]string{"a.cbor", "b.cbor", "--format=xml"})
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, `invalid --format "xml": expecting "text" or "json"`)
+}
+
+func Test_checkCorimDiffArgs_no_format(t *testing.T) {
+	saved := corimDiffFormat
+	defer func() { corimDiffFormat = saved }()
+
+	corimDiffFormat = nil
+	assert.EqualError(t, checkCorimDiffArgs([]string{"a.cbor", "b.cbor"}), "no format supplied")
+}
+
+func Test_CorimDiffCmd_non_existent_corim_file(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "a.cbor", testCorimValid, 0644))
+
+	cmd := NewCorimDiffCmd()
+	cmd.SetArgs([]string{"a.cbor", "b.cbor"})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "error loading CoRIM from b.cbor: open b.cbor: file does not exist")
+}
+
+func Test_CorimDiffCmd_signed_and_unsigned_ok(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "signed.cbor", testSignedCorimValidWithCots, 0644))
+
+	var s corim.SignedCorim
+	require.NoError(t, s.FromCOSE(testSignedCorimValidWithCots))
+	unsigned, err := s.UnsignedCorim.ToCBOR()
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, "unsigned.cbor", unsigned, 0644))
+
+	for _, format := range []string{"text", "json"} {
+		cmd := NewCorimDiffCmd()
+		cmd.SetArgs([]string{"signed.cbor", "unsigned.cbor", "--format=" + format})
+
+		assert.NoError(t, cmd.Execute())
+	}
+
+	d, err := diffCorimFiles("signed.cbor", "unsigned.cbor")
+	require.NoError(t, err)
+	assert.True(t, d.empty())
+}
+
+func Test_CorimDiff_tags_matched_by_tag_id(t *testing.T) {
+	a := makeDiffCorim(t, [][]byte{testComidACMERoadRunner, testComidACMEWileE}, nil)
+	b := makeDiffCorim(t, [][]byte{testComidACMEWileE, testComidACMERoadRunner}, nil)
+
+	d := diffCorimBytes(t, a, b)
+	assert.True(t, d.empty())
+}
+
+func Test_CorimDiff_added_and_removed_tags(t *testing.T) {
+	a := makeDiffCorim(t, [][]byte{testComidACMERoadRunner}, [][]byte{testCoswidTemplate})
+	b := makeDiffCorim(t, [][]byte{testComidACMERoadRunner, testComidACMEWileE}, nil)
+
+	d := diffCorimBytes(t, a, b)
+	assert.Empty(t, d.Corim)
+	assert.Equal(t, []tagDiff{
+		{Type: "coswid", TagID: "com.acme.rrd2013-ce-sp1-v4-1-5-0", Change: diffRemoved},
+		{Type: "comid", TagID: "urn:example:acme:wile-e", Change: diffAdded},
+	}, d.Tags)
+
+	assert.Equal(t,
+		`- coswid "com.acme.rrd2013-ce-sp1-v4-1-5-0"
++ comid "urn:example:acme:wile-e"
+`, d.text())
+}
+
+func Test_CorimDiff_modified_comid(t *testing.T) {
+	modified := strings.Replace(string(testComidACMEWileE), `"AAEC"`, `"AAED"`, 1)
+	modified = strings.Replace(modified, `"measurements": [`, `"measurements": [
+		{
+			"key": { "type": "uint", "value": 2 },
+			"value": { "svn": { "type": "exact-value", "value": 3 } }
+		},`, 1)
+	modified = strings.Replace(modified, `"id": "urn:example:acme:wile-e"`,
+		`"id": "urn:example:acme:wile-e", "version": 1`, 1)
+
+	a := makeDiffCorim(t, [][]byte{testComidACMEWileE}, nil)
+	b := makeDiffCorim(t, [][]byte{[]byte(modified)}, nil)
+
+	d := diffCorimBytes(t, a, b)
+	require.Len(t, d.Tags, 1)
+
+	td := d.Tags[0]
+	assert.Equal(t, "comid", td.Type)
+	assert.Equal(t, "urn:example:acme:wile-e", td.TagID)
+	assert.Equal(t, diffModified, td.Change)
+	assert.Equal(t, []string{"tag-identity"}, td.Fields)
+
+	require.Len(t, td.Triples, 2)
+
+	env := `{"class":{"vendor":"ACME","model":"WileE"}}`
+
+	assert.Equal(t, "reference-values", td.Triples[0].Triple)
+	assert.Equal(t, diffModified, td.Triples[0].Change)
+	assert.JSONEq(t, env, string(td.Triples[0].Environment))
+	assert.JSONEq(t, `{"type":"uint","value":1}`, string(td.Triples[0].Key))
+	assert.JSONEq(t, `{"value":{"raw-value":{"type":"bytes","value":"AAEC"}}}`, string(td.Triples[0].Old))
+	assert.JSONEq(t, `{"value":{"raw-value":{"type":"bytes","value":"AAED"}}}`, string(td.Triples[0].New))
+
+	assert.Equal(t, diffAdded, td.Triples[1].Change)
+	assert.JSONEq(t, `{"type":"uint","value":2}`, string(td.Triples[1].Key))
+	assert.Nil(t, td.Triples[1].Old)
+	assert.JSONEq(t, `{"value":{"svn":{"type":"exact-value","value":3}}}`, string(td.Triples[1].New))
+
+	assert.Equal(t,
+		`~ comid "urn:example:acme:wile-e" (tag-identity)
+    ~ reference-values
+        environment: `+env+`
+        key: {"type":"uint","value":1}
+        - {"value":{"raw-value":{"type":"bytes","value":"AAEC"}}}
+        + {"value":{"raw-value":{"type":"bytes","value":"AAED"}}}
+    + reference-values
+        environment: `+env+`
+        key: {"type":"uint","value":2}
+        + {"value":{"svn":{"type":"exact-value","value":3}}}
+`, d.text())
+
+	j, err := json.Marshal(d)
+	require.NoError(t, err)
+	assert.Contains(t, string(j), `"triple":"reference-values","change":"modified"`)
+}
+
+func Test_CorimDiff_corim_fields(t *testing.T) {
+	a := makeDiffCorim(t, [][]byte{testComidACMERoadRunner}, nil)
+
+	var u corim.UnsignedCorim
+	require.NoError(t, u.FromCBOR(a))
+	require.NotNil(t, u.SetProfile("http://example.com/profile"))
+	b, err := u.ToCBOR()
+	require.NoError(t, err)
+
+	d := diffCorimBytes(t, a, b)
+	assert.Equal(t, []string{"profile"}, d.Corim)
+	assert.Empty(t, d.Tags)
+	assert.Equal(t, "~ corim (profile)\n", d.text())
+}
@@ -4,20 +4,19 @@
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
 	"errors"
 	"fmt"
 
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/veraison/corim/corim"
-	"github.com/veraison/corim/cots"
 )
 
 var (
 	corimDisplayCorimFile *string
 	corimDisplayShowTags  *bool
+	corimDisplayFormat    *string
+	corimDisplayAnnotate  *bool
 )
 
 var corimDisplayCmd = NewCorimDisplayCmd()
@@ -25,8 +24,8 @@ var corimDisplayCmd = NewCorimDisplayCmd()
 func NewCorimDisplayCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "display",
-		Short: "display the content of a CoRIM as JSON",
-		Long: `display the content of a CoRIM as JSON
+		Short: "display the content of a CoRIM as JSON, YAML, tables or EDN",
+		Long: `display the content of a CoRIM as JSON, YAML, tables or EDN
 
 	Display the contents of the signed CoRIM signed-corim.cbor 
 	
@@ -36,6 +35,16 @@ func NewCorimDisplayCmd() *cobra.Command {
 	also unpack any embedded CoMID, CoSWID and CoTS
 	
 	  cocli corim display --file yet-another-signed-corim.cbor --show-tags
+
+	Display a compact summary of signed-corim.cbor and of its tags, as tables
+
+	  cocli corim display --file signed-corim.cbor --show-tags --format=table
+
+	Display the signed CoRIM signed-corim.cbor in CBOR extended diagnostic
+	notation (EDN), i.e., as it is on the wire, including the COSE headers and
+	the embedded tags, with map keys annotated with their CDDL names
+
+	  cocli corim display --file signed-corim.cbor --format=edn --annotate
 	`,
 
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -43,12 +52,24 @@ func NewCorimDisplayCmd() *cobra.Command {
 				return err
 			}
 
-			return display(*corimDisplayCorimFile, *corimDisplayShowTags)
+			if *corimDisplayFormat == displayFormatEDN {
+				return displayCorimEDN(*corimDisplayCorimFile, *corimDisplayAnnotate)
+			}
+
+			return display(*corimDisplayCorimFile, *corimDisplayShowTags, *corimDisplayFormat)
 		},
 	}
 
 	corimDisplayCorimFile = cmd.Flags().StringP("file", "f", "", "a CoRIM file (in CBOR format)")
-	corimDisplayShowTags = cmd.Flags().BoolP("show-tags", "v", false, "display embedded tags")
+	corimDisplayShowTags = cmd.Flags().BoolP(
+		"show-tags", "v", false, "display embedded tags (always displayed in edn format)",
+	)
+	corimDisplayFormat = cmd.Flags().String(
+		"format", displayFormatJSON, "output format: json, yaml, table or edn",
+	)
+	corimDisplayAnnotate = cmd.Flags().Bool(
+		"annotate", false, "annotate map keys with their CDDL names (edn format only)",
+	)
 
 	return cmd
 }
@@ -58,107 +79,105 @@ func checkCorimDisplayArgs() error {
 		return errors.New("no CoRIM supplied")
 	}
 
-	return nil
+	return checkDisplayFormat(*corimDisplayFormat, *corimDisplayAnnotate)
 }
 
-func displaySignedCorim(s corim.SignedCorim, corimFile string, showTags bool) error {
-	metaJSON, err := json.MarshalIndent(&s.Meta, "", "  ")
+func displaySignedCorim(u *corim.UnsignedCorim, m *corim.Meta, corimFile string, showTags bool, format string) error {
+	meta, err := formatDisplay(m, format)
 	if err != nil {
 		return fmt.Errorf("error encoding CoRIM Meta from %s: %w", corimFile, err)
 	}
 
 	fmt.Println("Meta:")
-	fmt.Println(string(metaJSON))
+	fmt.Println(meta)
 
-	corimJSON, err := json.MarshalIndent(&s.UnsignedCorim, "", "  ")
+	unsigned, err := formatDisplay(u, format)
 	if err != nil {
 		return fmt.Errorf("error encoding unsigned CoRIM from %s: %w", corimFile, err)
 	}
 
 	fmt.Println("CoRIM:")
-	fmt.Println(string(corimJSON))
+	fmt.Println(unsigned)
 
 	if showTags {
 		fmt.Println("Tags:")
-		displayTags(s.UnsignedCorim.Tags)
+		displayTags(u.Tags, format)
 	}
 
 	return nil
 }
 
-func displayUnsignedCorim(u corim.UnsignedCorim, corimFile string, showTags bool) error {
-	corimJSON, err := json.MarshalIndent(&u, "", "  ")
+func displayUnsignedCorim(u *corim.UnsignedCorim, corimFile string, showTags bool, format string) error {
+	unsigned, err := formatDisplay(u, format)
 	if err != nil {
 		return fmt.Errorf("error encoding unsigned CoRIM from %s: %w", corimFile, err)
 	}
 
 	fmt.Println("Corim:")
-	fmt.Println(string(corimJSON))
+	fmt.Println(unsigned)
 
 	if showTags {
 		fmt.Println("Tags:")
-		displayTags(u.Tags)
+		displayTags(u.Tags, format)
 	}
 
 	return nil
 }
 
-func display(corimFile string, showTags bool) error {
-	var (
-		corimCBOR []byte
-		err       error
-	)
+func display(corimFile string, showTags bool, format string) error {
+	// signed (COSE Sign1 or COSE Sign) or unsigned CoRIM
+	u, m, err := loadCorim(corimFile)
+	if err != nil {
+		return err
+	}
 
-	// read the CoRIM file
-	if corimCBOR, err = afero.ReadFile(fs, corimFile); err != nil {
-		return fmt.Errorf("error loading CoRIM from %s: %w", corimFile, err)
+	if m != nil {
+		return displaySignedCorim(u, m, corimFile, showTags, format)
 	}
 
-	// try to decode as a signed CoRIM
-	var s corim.SignedCorim
-	if err = s.FromCOSE(corimCBOR); err == nil {
-		// successfully decoded as signed CoRIM
-		return displaySignedCorim(s, corimFile, showTags)
+	return displayUnsignedCorim(u, corimFile, showTags, format)
+}
+
+// displayCorimEDN displays the signed or unsigned CoRIM in corimFile in
+// extended diagnostic notation
+func displayCorimEDN(corimFile string, annotate bool) error {
+	corimCBOR, err := afero.ReadFile(fs, corimFile)
+	if err != nil {
+		return fmt.Errorf("error loading CoRIM from %s: %w", corimFile, err)
 	}
 
-	// if decoding as signed CoRIM failed, attempt to decode as unsigned CoRIM
-	var u corim.UnsignedCorim
-	if err = u.FromCBOR(corimCBOR); err != nil {
-		return fmt.Errorf("error decoding CoRIM (signed or unsigned) from %s: %w", corimFile, err)
+	edn, err := toEDN(corimCBOR, ednCorim, annotate)
+	if err != nil {
+		return fmt.Errorf("error decoding CoRIM from %s: %w", corimFile, err)
 	}
 
-	// successfully decoded as unsigned CoRIM
-	return displayUnsignedCorim(u, corimFile, showTags)
+	fmt.Println(edn)
+
+	return nil
 }
 
 // displayTags processes and displays embedded tags within a CoRIM.
-func displayTags(tags []corim.Tag) {
+func displayTags(tags []corim.Tag, format string) {
 	for i, t := range tags {
-		if len(t) < 4 {
-			fmt.Printf(">> skipping malformed tag at index %d\n", i)
+		typ, cborData, err := splitCorimTag(t)
+		if err != nil {
+			fmt.Printf(">> skipping tag at index %d: %v\n", i, err)
 			continue
 		}
 
-		// Split tag identifier from data
-		cborTag, cborData := t[:3], t[3:]
-
 		hdr := fmt.Sprintf(">> [ %d ]", i)
 
-		switch {
-		case bytes.Equal(cborTag, corim.ComidTag):
-			if err := printComid(cborData, hdr); err != nil {
-				fmt.Printf(">> skipping malformed CoMID tag at index %d: %v\n", i, err)
-			}
-		case bytes.Equal(cborTag, corim.CoswidTag):
-			if err := printCoswid(cborData, hdr); err != nil {
-				fmt.Printf(">> skipping malformed CoSWID tag at index %d: %v\n", i, err)
-			}
-		case bytes.Equal(cborTag, cots.CotsTag):
-			if err := printCots(cborData, hdr); err != nil {
-				fmt.Printf(">> skipping malformed CoTS tag at index %d: %v\n", i, err)
-			}
-		default:
-			fmt.Printf(">> unmatched CBOR tag: %x\n", cborTag)
+		switch typ {
+		case "comid":
+			err = printComid(cborData, format, hdr)
+		case "coswid":
+			err = printCoswid(cborData, format, hdr)
+		case "cots":
+			err = printCots(cborData, format, hdr)
+		}
+
+		if err != nil {
+			fmt.Printf(">> skipping malformed %s tag at index %d: %v\n", corimTagNames[typ], i, err)
 		}
 	}
 }
@@ -157,3 +157,88 @@ func Test_CorimDisplayCmd_ok_nested_view_with_cots(t *testing.T) {
 	err = cmd.Execute()
 	assert.NoError(t, err)
 }
+
+func Test_CorimDisplayCmd_bad_format(t *testing.T) {
+	cmd := NewCorimDisplayCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--format=xml",
+	}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, `invalid --format "xml": expecting "json", "yaml", "table" or "edn"`)
+}
+
+func Test_CorimDisplayCmd_annotate_without_edn(t *testing.T) {
+	cmd := NewCorimDisplayCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--annotate",
+	}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "--annotate requires --format=edn")
+}
+
+func Test_CorimDisplayCmd_ok_edn_with_cots(t *testing.T) {
+	cmd := NewCorimDisplayCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--format=edn",
+		"--annotate",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "ok.cbor", testSignedCorimValidWithCots, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+}
+
+func Test_CorimDisplayCmd_edn_invalid_cbor(t *testing.T) {
+	cmd := NewCorimDisplayCmd()
+
+	args := []string{
+		"--file=bad.cbor",
+		"--format=edn",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "bad.cbor", []byte{0xa1, 0x00}, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, "error decoding CoRIM from bad.cbor: unexpected end of data")
+}
+
+func Test_CorimDisplayCmd_ok_nested_view_yaml_and_table(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "ok.cbor", testSignedCorimValidWithCots, 0644)
+	require.NoError(t, err)
+
+	for _, format := range []string{"yaml", "table"} {
+		cmd := NewCorimDisplayCmd()
+		cmd.SetArgs([]string{"--file=ok.cbor", "--show-tags", "--format=" + format})
+
+		err = cmd.Execute()
+		assert.NoError(t, err, format)
+	}
+}
+
+func Test_CorimDisplayCmd_multi_signed_ok(t *testing.T) {
+	writeMultiSignedFixtures(t, makeTestPKI(t), makeTestPKI(t))
+
+	cmd := NewCorimDisplayCmd()
+	cmd.SetArgs([]string{"--file=signed.cbor", "--show-tags"})
+
+	err := cmd.Execute()
+	assert.NoError(t, err)
+}
@@ -4,20 +4,29 @@
 package cmd
 
 import (
-	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/veraison/corim/corim"
-	"github.com/veraison/corim/cots"
 )
 
 var (
 	corimExtractCorimFile *string
 	corimExtractOutputDir *string
+	corimExtractTemplates *bool
+	corimExtractNaming    *string
+)
+
+const (
+	extractNamingIndex = "index"
+	extractNamingTagID = "tag-id"
 )
 
 var corimExtractCmd = NewCorimExtractCmd()
@@ -29,7 +38,7 @@ func NewCorimExtractCmd() *cobra.Command {
 		Long: `extract, as-is, CoSWIDs and CoMIDs, CoTS found in a CoRIM and save them to disk
 
 	Extract the contents of the signed CoRIM signed-corim.cbor to the current
-	directory
+	directory.  Unsigned CoRIMs are also accepted.
 	
 	  cocli corim extract --file=signed-corim.cbor
 
@@ -38,6 +47,22 @@ func NewCorimExtractCmd() *cobra.Command {
 	
 	  cocli corim extract --file=yet-another-signed-corim.cbor \
 	    				--output-dir=my-dir
+
+	Also save the CoRIM, without its tags, as a JSON template (corim.json) and,
+	if the CoRIM is signed, its Meta as a JSON template (meta.json), that can be
+	fed back to corim create and corim sign respectively.
+
+	  cocli corim extract --file=signed-corim.cbor --templates
+
+	By default, extracted tags are named after their position in the CoRIM
+	(e.g., 000000-comid.cbor).  Name them after their tag-id and version instead
+	(e.g., 43bbe37f-2e61-4b33-aed3-53cff1428b16-v0-comid.cbor), so that names
+	are stable across versions of the CoRIM:
+
+	  cocli corim extract --file=signed-corim.cbor --naming=tag-id
+
+	In either case, an index.json manifest is also saved, listing the index,
+	type, tag-id, version, file name and SHA-256 digest of each extracted tag.
 	`,
 
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -45,12 +70,20 @@ func NewCorimExtractCmd() *cobra.Command {
 				return err
 			}
 
-			return extract(*corimExtractCorimFile, corimExtractOutputDir)
+			return extract(
+				*corimExtractCorimFile, corimExtractOutputDir, *corimExtractTemplates, *corimExtractNaming,
+			)
 		},
 	}
 
-	corimExtractCorimFile = cmd.Flags().StringP("file", "f", "", "a signed CoRIM file (in CBOR format)")
+	corimExtractCorimFile = cmd.Flags().StringP("file", "f", "", "a signed or unsigned CoRIM file (in CBOR format)")
 	corimExtractOutputDir = cmd.Flags().StringP("output-dir", "o", ".", "folder to which CoSWIDs, CoMIDs, CoTSs are saved")
+	corimExtractTemplates = cmd.Flags().Bool(
+		"templates", false, "also save the CoRIM and, if signed, the Meta as JSON templates",
+	)
+	corimExtractNaming = cmd.Flags().String(
+		"naming", extractNamingIndex, `how extracted files are named: "index" or "tag-id" (tag-id and version)`,
+	)
 
 	return cmd
 }
@@ -60,66 +93,162 @@ func checkCorimExtractArgs() error {
 		return errors.New("no CoRIM supplied")
 	}
 
+	if *corimExtractNaming != extractNamingIndex && *corimExtractNaming != extractNamingTagID {
+		return fmt.Errorf(`invalid --naming %q: expecting "index" or "tag-id"`, *corimExtractNaming)
+	}
+
 	return nil
 }
 
-func extract(signedCorimFile string, outputDir *string) error {
+func extract(corimFile string, outputDir *string, templates bool, naming string) error {
+	u, meta, err := loadCorim(corimFile)
+	if err != nil {
+		return err
+	}
+
+	baseDir := "."
+	if outputDir != nil {
+		baseDir = *outputDir
+	}
+
+	if templates {
+		if err = saveCorimTemplates(u, meta, baseDir); err != nil {
+			return err
+		}
+	}
+
 	var (
-		signedCorimCBOR []byte
-		err             error
-		s               corim.SignedCorim
-		baseDir         string
+		manifest = []extractedTag{}
+		used     = map[string]bool{}
 	)
 
-	if signedCorimCBOR, err = afero.ReadFile(fs, signedCorimFile); err != nil {
-		return fmt.Errorf("error loading signed CoRIM from %s: %w", signedCorimFile, err)
+	for i, e := range u.Tags {
+		typ, cborData, err := splitCorimTag(e)
+		if err != nil {
+			fmt.Printf(">> skipping tag at index %d: %v\n", i, err)
+			continue
+		}
+
+		digest := sha256.Sum256(cborData)
+
+		entry := extractedTag{
+			Index:  i,
+			Type:   typ,
+			SHA256: hex.EncodeToString(digest[:]),
+		}
+
+		// tags are extracted as-is, even if they cannot be decoded, in which
+		// case their tag-id and version are unknown
+		if tag, err := decodeCorimTag(e); err == nil && tag.tagID != "" {
+			version := tag.version
+			entry.TagID, entry.Version = tag.tagID, &version
+		}
+
+		if naming == extractNamingTagID && entry.TagID == "" {
+			fmt.Printf(">> no tag-id for %s tag at index %d, naming it by index\n", typ, i)
+		}
+
+		entry.File = extractFileName(entry, naming, used)
+		used[entry.File] = true
+
+		outputFile := filepath.Join(baseDir, entry.File)
+		if err = afero.WriteFile(fs, outputFile, cborData, 0644); err != nil {
+			fmt.Printf(">> error saving %s tag at index %d: %v\n", corimTagNames[typ], i, err)
+			continue
+		}
+
+		manifest = append(manifest, entry)
 	}
 
-	if err = s.FromCOSE(signedCorimCBOR); err != nil {
-		return fmt.Errorf("error decoding signed CoRIM from %s: %w", signedCorimFile, err)
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding manifest: %w", err)
 	}
 
-	baseDir = "."
-	if outputDir != nil {
-		baseDir = *outputDir
+	manifestFile := filepath.Join(baseDir, "index.json")
+	if err = afero.WriteFile(fs, manifestFile, manifestJSON, 0644); err != nil {
+		return fmt.Errorf("error saving manifest to file %s: %w", manifestFile, err)
 	}
 
-	for i, e := range s.UnsignedCorim.Tags {
-		var (
-			outputFile string
-		)
+	return nil
+}
 
-		// need at least 3 bytes for the tag and 1 for the smallest bstr
-		if len(e) < 3+1 {
-			fmt.Printf(">> skipping malformed tag at index %d\n", i)
-			continue
-		}
+// extractedTag is an entry of the index.json manifest written by extract
+type extractedTag struct {
+	Index   int    `json:"index"`
+	Type    string `json:"type"`
+	TagID   string `json:"tag-id,omitempty"`
+	Version *uint  `json:"version,omitempty"`
+	File    string `json:"file"`
+	SHA256  string `json:"sha256"`
+}
 
-		// split tag from data
-		cborTag, cborData := e[:3], e[3:]
+// extractFileName returns the name of the file an extracted tag is saved to.
+// Tags are named by index, or, with the "tag-id" naming, by tag-id and version.
+// Tags without a (known) tag-id are always named by index.  A name that clashes
+// with that of a previous tag is prefixed with the index and, should that clash
+// too, replaced by the plain index name, which cannot clash with any tag-id name.
+func extractFileName(e extractedTag, naming string, used map[string]bool) string {
+	byIndex := fmt.Sprintf("%06d-%s.cbor", e.Index, e.Type)
 
-		switch {
-		case bytes.Equal(cborTag, corim.ComidTag):
-			outputFile = filepath.Join(baseDir, fmt.Sprintf("%06d-comid.cbor", i))
+	if naming != extractNamingTagID || e.TagID == "" {
+		return byIndex
+	}
 
-			if err = afero.WriteFile(fs, outputFile, cborData, 0644); err != nil {
-				fmt.Printf(">> error saving CoMID tag at index %d: %v\n", i, err)
-			}
-		case bytes.Equal(cborTag, corim.CoswidTag):
-			outputFile = filepath.Join(baseDir, fmt.Sprintf("%06d-coswid.cbor", i))
+	name := fmt.Sprintf("%s-v%d-%s.cbor", sanitizeFileName(e.TagID), *e.Version, e.Type)
+	if !used[name] {
+		return name
+	}
 
-			if err = afero.WriteFile(fs, outputFile, cborData, 0644); err != nil {
-				fmt.Printf(">> error saving CoSWID tag at index %d: %v\n", i, err)
-			}
-		case bytes.Equal(cborTag, cots.CotsTag):
-			outputFile = filepath.Join(baseDir, fmt.Sprintf("%06d-cots.cbor", i))
+	if prefixed := fmt.Sprintf("%06d-%s", e.Index, name); !used[prefixed] {
+		return prefixed
+	}
 
-			if err = afero.WriteFile(fs, outputFile, cborData, 0644); err != nil {
-				fmt.Printf(">> error saving CoTS tag at index %d: %v\n", i, err)
-			}
+	return byIndex
+}
+
+// sanitizeFileName replaces any character that is not safe to use in a file
+// name (on any platform) with an underscore
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			r == '.', r == '-', r == '_':
+			return r
 		default:
-			fmt.Printf(">> unmatched CBOR tag: %x\n", cborTag)
+			return '_'
 		}
+	}, s)
+}
+
+// saveCorimTemplates saves the unsigned CoRIM, without its tags, and the Meta,
+// if any, as JSON templates in baseDir
+func saveCorimTemplates(u *corim.UnsignedCorim, meta *corim.Meta, baseDir string) error {
+	tmpl := *u
+	tmpl.Tags = nil
+
+	corimJSON, err := json.MarshalIndent(&tmpl, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding CoRIM template: %w", err)
+	}
+
+	corimTmplFile := filepath.Join(baseDir, "corim.json")
+	if err = afero.WriteFile(fs, corimTmplFile, corimJSON, 0644); err != nil {
+		return fmt.Errorf("error saving CoRIM template to file %s: %w", corimTmplFile, err)
+	}
+
+	if meta == nil {
+		return nil
+	}
+
+	metaJSON, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding Meta template: %w", err)
+	}
+
+	metaTmplFile := filepath.Join(baseDir, "meta.json")
+	if err = afero.WriteFile(fs, metaTmplFile, metaJSON, 0644); err != nil {
+		return fmt.Errorf("error saving Meta template to file %s: %w", metaTmplFile, err)
 	}
 
 	return nil
@@ -4,11 +4,15 @@
 package cmd
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"testing"
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/veraison/corim/corim"
 )
 
 func Test_CorimExtractCmd_unknown_argument(t *testing.T) {
@@ -44,7 +48,7 @@ func Test_CorimExtractCmd_non_existent_corim_file(t *testing.T) {
 	fs = afero.NewMemMapFs()
 
 	err := cmd.Execute()
-	assert.EqualError(t, err, "error loading signed CoRIM from nonexistent.cbor: open nonexistent.cbor: file does not exist")
+	assert.EqualError(t, err, "error loading CoRIM from nonexistent.cbor: open nonexistent.cbor: file does not exist")
 }
 
 func Test_CorimExtractCmd_bad_signed_corim(t *testing.T) {
@@ -60,7 +64,7 @@ func Test_CorimExtractCmd_bad_signed_corim(t *testing.T) {
 	require.NoError(t, err)
 
 	err = cmd.Execute()
-	assert.EqualError(t, err, "error decoding signed CoRIM from bad.txt: failed CBOR decoding for COSE-Sign1 signed CoRIM: cbor: invalid COSE_Sign1_Tagged object")
+	assert.EqualError(t, err, "error decoding CoRIM (signed or unsigned) from bad.txt: expected map (CBOR Major Type 5), found Major Type 3")
 }
 
 func Test_CorimExtractCmd_invalid_signed_corim(t *testing.T) {
@@ -138,3 +142,172 @@ func Test_CorimExtractCmd_with_cots_ok_save_to_default_dir(t *testing.T) {
 	assert.NoError(t, err)
 
 }
+
+func Test_CorimExtractCmd_unsigned_ok(t *testing.T) {
+	cmd := NewCorimExtractCmd()
+
+	args := []string{
+		"--file=unsigned.cbor",
+		"--templates",
+	}
+	cmd.SetArgs(args)
+
+	data := makeDiffCorim(t, [][]byte{testComidACMEWileE}, [][]byte{testCoswidTemplate})
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "unsigned.cbor", data, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+
+	_, err = fs.Stat("000000-comid.cbor")
+	assert.NoError(t, err)
+
+	_, err = fs.Stat("000001-coswid.cbor")
+	assert.NoError(t, err)
+
+	_, err = fs.Stat("corim.json")
+	assert.NoError(t, err)
+
+	_, err = fs.Stat("meta.json")
+	assert.Error(t, err)
+}
+
+func Test_CorimExtractCmd_templates_round_trip(t *testing.T) {
+	cmd := NewCorimExtractCmd()
+
+	args := []string{
+		"--file=signed.cbor",
+		"--output-dir=out",
+		"--templates",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "signed.cbor", testSignedCorimValidWithCots, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	metaJSON, err := afero.ReadFile(fs, "out/meta.json")
+	require.NoError(t, err)
+
+	var m corim.Meta
+	require.NoError(t, m.FromJSON(metaJSON))
+
+	var s corim.SignedCorim
+	require.NoError(t, s.FromCOSE(testSignedCorimValidWithCots))
+	assert.Equal(t, s.Meta, m)
+
+	// the CoRIM can be re-created from the template and the extracted tags
+	outputFile := "recreated.cbor"
+	var tagFiles [3][]string
+	for i, typ := range []string{"comid", "coswid", "cots"} {
+		tagFiles[i], err = afero.Glob(fs, "out/*-"+typ+".cbor")
+		require.NoError(t, err)
+	}
+
+	_, err = corimTemplateToCBOR("out/corim.json", tagFiles[0], tagFiles[1], tagFiles[2], &outputFile)
+	require.NoError(t, err)
+
+	d, err := diffCorimFiles("signed.cbor", outputFile)
+	require.NoError(t, err)
+	assert.True(t, d.empty())
+}
+
+func Test_CorimExtractCmd_bad_naming(t *testing.T) {
+	cmd := NewCorimExtractCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--naming=tag-version",
+	}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, `invalid --naming "tag-version": expecting "index" or "tag-id"`)
+}
+
+func Test_CorimExtractCmd_naming_tag_id(t *testing.T) {
+	cmd := NewCorimExtractCmd()
+
+	args := []string{
+		"--file=unsigned.cbor",
+		"--naming=tag-id",
+	}
+	cmd.SetArgs(args)
+
+	data := makeDiffCorim(
+		t,
+		[][]byte{testComidACMEWileE},
+		[][]byte{testCoswidTemplate, testCoswidTemplate},
+	)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "unsigned.cbor", data, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	manifestJSON, err := afero.ReadFile(fs, "index.json")
+	require.NoError(t, err)
+
+	var manifest []extractedTag
+	require.NoError(t, json.Unmarshal(manifestJSON, &manifest))
+	require.Len(t, manifest, 3)
+
+	expectedFiles := []string{
+		"urn_example_acme_wile-e-v0-comid.cbor",
+		"com.acme.rrd2013-ce-sp1-v4-1-5-0-v0-coswid.cbor",
+		// the second (identical) CoSWID is disambiguated by index
+		"000002-com.acme.rrd2013-ce-sp1-v4-1-5-0-v0-coswid.cbor",
+	}
+
+	for i, e := range manifest {
+		assert.Equal(t, i, e.Index)
+		assert.Equal(t, expectedFiles[i], e.File)
+		require.NotNil(t, e.Version)
+		assert.Equal(t, uint(0), *e.Version)
+
+		tagData, err := afero.ReadFile(fs, e.File)
+		require.NoError(t, err)
+
+		digest := sha256.Sum256(tagData)
+		assert.Equal(t, hex.EncodeToString(digest[:]), e.SHA256)
+	}
+
+	assert.Equal(t, "comid", manifest[0].Type)
+	assert.Equal(t, "urn:example:acme:wile-e", manifest[0].TagID)
+	assert.Equal(t, "coswid", manifest[1].Type)
+	assert.Equal(t, "com.acme.rrd2013-ce-sp1-v4-1-5-0", manifest[1].TagID)
+}
+
+func Test_extractFileName(t *testing.T) {
+	version := uint(0)
+	used := map[string]bool{}
+
+	tvs := []struct {
+		e        extractedTag
+		expected string
+	}{
+		// looks like the index-prefixed name of the third tag
+		{extractedTag{Index: 0, Type: "comid", TagID: "000002-foo", Version: &version}, "000002-foo-v0-comid.cbor"},
+		{extractedTag{Index: 1, Type: "comid", TagID: "foo", Version: &version}, "foo-v0-comid.cbor"},
+		{extractedTag{Index: 2, Type: "comid", TagID: "foo", Version: &version}, "000002-comid.cbor"},
+		{extractedTag{Index: 3, Type: "coswid"}, "000003-coswid.cbor"},
+	}
+
+	for _, tv := range tvs {
+		name := extractFileName(tv.e, extractNamingTagID, used)
+		assert.Equal(t, tv.expected, name)
+		used[name] = true
+	}
+}
+
+func Test_sanitizeFileName(t *testing.T) {
+	assert.Equal(t, "com.acme_fw_1.0", sanitizeFileName("com.acme/fw 1.0"))
+	assert.Equal(t, "a_b_c_", sanitizeFileName(`a\b:c?`))
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/veraison/corim/corim"
+)
+
+var (
+	corimMergeTemplateFile *string
+	corimMergeCorimFiles   []string
+	corimMergeOutputFile   *string
+)
+
+var corimMergeCmd = NewCorimMergeCmd()
+
+func NewCorimMergeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge",
+		Short: "combine the tags of two or more CoRIMs into a new CoRIM",
+		Long: `combine the tags of two or more CoRIMs into a new CoRIM
+
+    Create a CoRIM from template product.json, adding the CoMIDs, CoSWIDs and
+    CoTS embedded in the CoRIMs in fw-a.cbor, fw-b.cbor and fw-c.cbor, in that
+    order.  The input CoRIMs can be signed or unsigned; signatures are not
+    verified, and the result is an unsigned CoRIM.  Since no explicit output
+    file is set, the CoRIM is saved to the current directory with the template
+    basename and a .cbor extension.
+
+      cocli corim merge --template=product.json \
+                    --file=fw-a.cbor \
+                    --file=fw-b.cbor \
+                    --file=fw-c.cbor
+
+    Tags are identified by type and tag-id.  A tag that appears more than once
+    with exactly the same content is only added once, while different tags
+    with the same tag-id are an error.
+    `,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkCorimMergeArgs(); err != nil {
+				return err
+			}
+
+			outputFile, err := merge(*corimMergeTemplateFile, corimMergeCorimFiles, *corimMergeOutputFile)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf(">> merged %d CoRIM(s) into %q\n", len(corimMergeCorimFiles), outputFile)
+
+			return nil
+		},
+	}
+
+	corimMergeTemplateFile = cmd.Flags().StringP("template", "t", "", "a CoRIM template file (in JSON or YAML format)")
+
+	cmd.Flags().StringArrayVarP(
+		&corimMergeCorimFiles, "file", "f", []string{}, "a CoRIM file (in CBOR format) whose tags are merged",
+	)
+
+	corimMergeOutputFile = cmd.Flags().StringP("output", "o", "", "name of the generated (unsigned) CoRIM file")
+
+	return cmd
+}
+
+func checkCorimMergeArgs() error {
+	if corimMergeTemplateFile == nil || *corimMergeTemplateFile == "" {
+		return errors.New("no CoRIM template supplied")
+	}
+
+	if len(corimMergeCorimFiles) == 0 {
+		return errors.New("no CoRIMs supplied")
+	}
+
+	return nil
+}
+
+// merge creates a CoRIM from the template, with the tags of the supplied
+// CoRIMs, and returns the name of the file it has been saved to
+func merge(tmplFile string, corimFiles []string, outputFile string) (string, error) {
+	tmplData, err := afero.ReadFile(fs, tmplFile)
+	if err != nil {
+		return "", fmt.Errorf("error loading template from %s: %w", tmplFile, err)
+	}
+
+	var c corim.UnsignedCorim
+	if err = decodeTemplate(&c, tmplData, tmplFile); err != nil {
+		return "", fmt.Errorf("error decoding template from %s: %w", tmplFile, err)
+	}
+
+	type source struct {
+		file string
+		tag  corim.Tag
+	}
+
+	// where each tag already added comes from
+	added := map[string]source{}
+
+	for _, corimFile := range corimFiles {
+		u, _, err := loadCorim(corimFile)
+		if err != nil {
+			return "", err
+		}
+
+		for i, t := range u.Tags {
+			tag, err := decodeCorimTag(t)
+			if err != nil {
+				return "", fmt.Errorf("error decoding tag at index %d in %s: %w", i, corimFile, err)
+			}
+
+			// CoTS without a tag-identity cannot clash
+			if tag.tagID != "" {
+				if prev, ok := added[tag.id()]; ok {
+					if bytes.Equal(prev.tag, t) {
+						continue
+					}
+					return "", fmt.Errorf(
+						"duplicate %s tag-id %q in %s and %s", tag.typ, tag.tagID, prev.file, corimFile,
+					)
+				}
+				added[tag.id()] = source{file: corimFile, tag: t}
+			}
+
+			c.Tags = append(c.Tags, t)
+		}
+	}
+
+	if outputFile == "" {
+		outputFile = makeFileName("", tmplFile, ".cbor")
+	}
+
+	if err = saveUnsignedCorim(&c, outputFile); err != nil {
+		return "", err
+	}
+
+	return outputFile, nil
+}
+
+func init() {
+	corimCmd.AddCommand(corimMergeCmd)
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CorimMergeCmd_mandatory_args_missing(t *testing.T) {
+	cmd := NewCorimMergeCmd()
+	cmd.SetArgs([]string{"--file=a.cbor"})
+	assert.EqualError(t, cmd.Execute(), "no CoRIM template supplied")
+
+	cmd = NewCorimMergeCmd()
+	cmd.SetArgs([]string{"--template=t.json"})
+	assert.EqualError(t, cmd.Execute(), "no CoRIMs supplied")
+}
+
+func Test_CorimMergeCmd_ok(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "product.json", minimalCorimTemplate, 0644))
+	require.NoError(t, afero.WriteFile(fs, "a.cbor",
+		makeDiffCorim(t, [][]byte{testComidACMERoadRunner}, [][]byte{testCoswidTemplate}), 0644))
+	// the RoadRunner CoMID is repeated verbatim, and only added once
+	require.NoError(t, afero.WriteFile(fs, "b.cbor",
+		makeDiffCorim(t, [][]byte{testComidACMEWileE, testComidACMERoadRunner}, nil), 0644))
+	require.NoError(t, afero.WriteFile(fs, "c.cbor", testSignedCorimValidWithCots, 0644))
+
+	cmd := NewCorimMergeCmd()
+	cmd.SetArgs([]string{"--template=product.json", "--file=a.cbor", "--file=b.cbor", "--file=c.cbor"})
+	require.NoError(t, cmd.Execute())
+
+	u, meta, err := loadCorim("product.cbor")
+	require.NoError(t, err)
+	assert.Nil(t, meta)
+
+	signedCorim, _, err := loadCorim("c.cbor")
+	require.NoError(t, err)
+
+	tags, err := decodeCorimTags(u)
+	require.NoError(t, err)
+	require.Len(t, tags, 3+len(signedCorim.Tags))
+	assert.Equal(t, "43bbe37f-2e61-4b33-aed3-53cff1428b16", tags[0].tagID)
+	assert.Equal(t, "com.acme.rrd2013-ce-sp1-v4-1-5-0", tags[1].tagID)
+	assert.Equal(t, "urn:example:acme:wile-e", tags[2].tagID)
+}
+
+func Test_CorimMergeCmd_duplicate_tag_id(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "product.json", minimalCorimTemplate, 0644))
+	require.NoError(t, afero.WriteFile(fs, "a.cbor",
+		makeDiffCorim(t, [][]byte{testComidACMERoadRunner}, nil), 0644))
+	require.NoError(t, afero.WriteFile(fs, "b.cbor",
+		makeDiffCorim(t, [][]byte{[]byte(strings.Replace(
+			string(testComidACMERoadRunner), `"3q2+7w=="`, `"AAECAw=="`, 1,
+		))}, nil), 0644))
+
+	cmd := NewCorimMergeCmd()
+	cmd.SetArgs([]string{"--template=product.json", "--file=a.cbor", "--file=b.cbor", "--output=out.cbor"})
+	assert.EqualError(t, cmd.Execute(),
+		`duplicate comid tag-id "43bbe37f-2e61-4b33-aed3-53cff1428b16" in a.cbor and b.cbor`)
+
+	_, err := fs.Stat("out.cbor")
+	assert.Error(t, err)
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/veraison/corim/corim"
+)
+
+var (
+	corimRemoveCorimFile  *string
+	corimRemoveTagIDs     []string
+	corimRemoveOutputFile *string
+)
+
+var corimRemoveCmd = NewCorimRemoveCmd()
+
+func NewCorimRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove",
+		Short: "remove CoMID(s), CoSWID(s) and/or CoTS from an existing CoRIM by tag-id",
+		Long: `remove CoMID(s), CoSWID(s) and/or CoTS from an existing CoRIM by tag-id
+
+    Remove the tag (CoMID, CoSWID or CoTS) with tag-id
+    43bbe37f-2e61-4b33-aed3-53cff1428b16 from the unsigned CoRIM in corim.cbor,
+    which is updated in place.  The resulting CoRIM is validated before being
+    saved.
+
+      cocli corim remove --file=corim.cbor \
+                     --tag-id=43bbe37f-2e61-4b33-aed3-53cff1428b16
+
+    Remove two tags, and save the result to new-corim.cbor:
+
+      cocli corim remove --file=corim.cbor \
+                     --tag-id=43bbe37f-2e61-4b33-aed3-53cff1428b16 \
+                     --tag-id=com.acme.rrd2013-ce-sp1-v4-1-5-0 \
+                     --output=new-corim.cbor
+
+    All the tags with a matching tag-id are removed, and it is an error if
+    there are none.  If the CoRIM is signed, its signature and Meta are
+    dropped, and the result is an unsigned CoRIM that must be signed again
+    using corim sign.  In this case, unless --output is supplied, the result is
+    saved next to the signed CoRIM, which is left untouched, e.g., to
+    signed-corim-unsigned.cbor for signed-corim.cbor.
+    `,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkCorimRemoveArgs(); err != nil {
+				return err
+			}
+
+			var n int
+
+			outputFile, err := editCorim(*corimRemoveCorimFile, *corimRemoveOutputFile, func(u *corim.UnsignedCorim) error {
+				var err error
+				n, err = removeCorimTags(u, corimRemoveTagIDs)
+				if err != nil {
+					return fmt.Errorf("error removing tags from %s: %w", *corimRemoveCorimFile, err)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf(">> removed %d tag(s) from %q and saved to %q\n", n, *corimRemoveCorimFile, outputFile)
+
+			return nil
+		},
+	}
+
+	corimRemoveCorimFile = cmd.Flags().StringP("file", "f", "", "a CoRIM file (in CBOR format)")
+
+	cmd.Flags().StringArrayVarP(
+		&corimRemoveTagIDs, "tag-id", "i", []string{}, "the tag-id of a CoMID, CoSWID or CoTS to remove",
+	)
+
+	corimRemoveOutputFile = cmd.Flags().StringP(
+		"output", "o", "", "name of the resulting (unsigned) CoRIM file (default: the input file, or <input>-unsigned.cbor if signed)",
+	)
+
+	return cmd
+}
+
+func checkCorimRemoveArgs() error {
+	if corimRemoveCorimFile == nil || *corimRemoveCorimFile == "" {
+		return errors.New("no CoRIM supplied")
+	}
+
+	if len(corimRemoveTagIDs) == 0 {
+		return errors.New("no tag-id supplied")
+	}
+
+	return nil
+}
+
+// removeCorimTags removes the embedded tags with one of the supplied tag-ids
+// from the CoRIM, and returns the number of removed tags.  Tags that cannot be
+// decoded are left untouched.
+func removeCorimTags(u *corim.UnsignedCorim, tagIDs []string) (int, error) {
+	found := make(map[string]bool, len(tagIDs))
+	for _, id := range tagIDs {
+		found[normalizeTagID(id)] = false
+	}
+
+	tags := make([]corim.Tag, 0, len(u.Tags))
+
+	for _, t := range u.Tags {
+		tag, err := decodeCorimTag(t)
+		if err == nil && tag.tagID != "" {
+			id := normalizeTagID(tag.tagID)
+			if _, ok := found[id]; ok {
+				found[id] = true
+				continue
+			}
+		}
+
+		tags = append(tags, t)
+	}
+
+	for _, id := range tagIDs {
+		if !found[normalizeTagID(id)] {
+			return 0, fmt.Errorf("tag-id %q not found", id)
+		}
+	}
+
+	n := len(u.Tags) - len(tags)
+	u.Tags = tags
+
+	return n, nil
+}
+
+// normalizeTagID returns UUID tag-ids in canonical (lower case) form, so that
+// they can be supplied in any case
+func normalizeTagID(id string) string {
+	if u, err := uuid.Parse(id); err == nil {
+		return u.String()
+	}
+
+	return id
+}
+
+func init() {
+	corimCmd.AddCommand(corimRemoveCmd)
+}
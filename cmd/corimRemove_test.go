@@ -0,0 +1,85 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CorimRemoveCmd_mandatory_args_missing(t *testing.T) {
+	cmd := NewCorimRemoveCmd()
+	cmd.SetArgs([]string{"--tag-id=x"})
+	assert.EqualError(t, cmd.Execute(), "no CoRIM supplied")
+
+	cmd = NewCorimRemoveCmd()
+	cmd.SetArgs([]string{"--file=corim.cbor"})
+	assert.EqualError(t, cmd.Execute(), "no tag-id supplied")
+}
+
+func Test_CorimRemoveCmd_ok(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	corimCBOR := makeDiffCorim(t,
+		[][]byte{testComidACMERoadRunner, testComidACMEWileE}, [][]byte{testCoswidTemplate},
+	)
+	require.NoError(t, afero.WriteFile(fs, "corim.cbor", corimCBOR, 0644))
+
+	cmd := NewCorimRemoveCmd()
+	cmd.SetArgs([]string{
+		"--file=corim.cbor",
+		"--tag-id=urn:example:acme:wile-e",
+		"--tag-id=com.acme.rrd2013-ce-sp1-v4-1-5-0",
+	})
+	require.NoError(t, cmd.Execute())
+
+	u, _, err := loadCorim("corim.cbor")
+	require.NoError(t, err)
+
+	tags, err := decodeCorimTags(u)
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+	assert.Equal(t, "43bbe37f-2e61-4b33-aed3-53cff1428b16", tags[0].tagID)
+}
+
+func Test_CorimRemoveCmd_uuid_tag_id_any_case(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	corimCBOR := makeDiffCorim(t, [][]byte{testComidACMERoadRunner, testComidACMEWileE}, nil)
+	require.NoError(t, afero.WriteFile(fs, "corim.cbor", corimCBOR, 0644))
+
+	cmd := NewCorimRemoveCmd()
+	cmd.SetArgs([]string{"--file=corim.cbor", "--tag-id=43BBE37F-2E61-4B33-AED3-53CFF1428B16"})
+	require.NoError(t, cmd.Execute())
+
+	u, _, err := loadCorim("corim.cbor")
+	require.NoError(t, err)
+	assert.Len(t, u.Tags, 1)
+}
+
+func Test_CorimRemoveCmd_tag_id_not_found(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	corimCBOR := makeDiffCorim(t, [][]byte{testComidACMERoadRunner, testComidACMEWileE}, nil)
+	require.NoError(t, afero.WriteFile(fs, "corim.cbor", corimCBOR, 0644))
+
+	cmd := NewCorimRemoveCmd()
+	cmd.SetArgs([]string{"--file=corim.cbor", "--tag-id=urn:example:acme:wile-e", "--tag-id=unknown"})
+	assert.EqualError(t, cmd.Execute(), `error removing tags from corim.cbor: tag-id "unknown" not found`)
+
+	// the CoRIM is left untouched
+	data, err := afero.ReadFile(fs, "corim.cbor")
+	require.NoError(t, err)
+	assert.Equal(t, corimCBOR, data)
+}
+
+func Test_CorimRemoveCmd_last_tag(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	corimCBOR := makeDiffCorim(t, [][]byte{testComidACMEWileE}, nil)
+	require.NoError(t, afero.WriteFile(fs, "corim.cbor", corimCBOR, 0644))
+
+	cmd := NewCorimRemoveCmd()
+	cmd.SetArgs([]string{"--file=corim.cbor", "--tag-id=urn:example:acme:wile-e"})
+	assert.EqualError(t, cmd.Execute(), "error validating CoRIM: tags validation failed: no tags")
+}
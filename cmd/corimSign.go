@@ -4,8 +4,10 @@
 package cmd
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"path/filepath"
 
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
@@ -15,11 +17,20 @@ import (
 
 var (
 	corimSignCorimFile         *string
-	corimSignKeyFile           *string
+	corimSignKeyFiles          *[]string
 	corimSignOutputFile        *string
 	corimSignMetaFile          *string
-	corimSignCertFile          *string
-	corimSignIntermediateCerts *string
+	corimSignCertFiles         *[]string
+	corimSignIntermediateCerts *[]string
+	corimSignPassphraseFile    *string
+	corimSignSignerCmd         *string
+	corimSignSignerArgs        *[]string
+	corimSignSignerAlg         *string
+	corimSignKid               *string
+	corimSignDetached          *bool
+	corimSignPayloadOutputFile *string
+	corimSignMultiSigner       *bool
+	corimSignTsaURL            *string
 )
 
 var corimSignCmd = NewCorimSignCmd()
@@ -39,6 +50,75 @@ func NewCorimSignCmd() *cobra.Command {
                     --meta=meta.json \
                     --output=signed-corim.cbor
                     
+    The kid protected header is taken from the "kid" parameter of the JWK, if
+    any, or from the --kid switch, which takes precedence.
+
+      cocli corim sign  --file=unsigned-corim.cbor \
+                    --key=key.jwk \
+                    --kid=2025-q3 \
+                    --meta=meta.json
+
+    The signing key can also be a PEM or DER-encoded private key (PKCS#8, SEC1
+    or PKCS#1).  The COSE algorithm is derived from the key type.
+
+      cocli corim sign  --file=unsigned-corim.cbor \
+                    --key=key.pem \
+                    --meta=meta.json \
+                    --output=signed-corim.cbor
+
+    If the signing key is an encrypted PKCS#8 PEM file, the passphrase is read
+    from the file given via --passphrase-file or, if not supplied, from the
+    COCLI_PASSPHRASE environment variable (or the "passphrase" entry in the
+    configuration file).  Otherwise, it is prompted for interactively.  The
+    decrypted key is only ever kept in memory.
+
+      cocli corim sign  --file=unsigned-corim.cbor \
+                    --key=encrypted-key.pem \
+                    --passphrase-file=passphrase.txt \
+                    --meta=meta.json
+
+    The signing key can also live in a PKCS#11 token, identified by a PKCS#11
+    URI (RFC 7512).  The module path and user PIN can be given in the URI
+    (module-path and pin-value query attributes) or via the pkcs11_module and
+    pkcs11_pin configuration entries (or the COCLI_PKCS11_MODULE and
+    COCLI_PKCS11_PIN environment variables).
+
+      cocli corim sign  --file=unsigned-corim.cbor \
+                    --key="pkcs11:token=cocli;object=corim-signer" \
+                    --meta=meta.json
+
+    Alternatively, delegate the signature operation to an external program
+    (e.g., a cloud KMS client), run with the arguments given via --signer-arg.
+    The program is given a JSON request on its standard input, and must reply
+    with a JSON object on its standard output: the certificate chain of the
+    signing key, if no --cert is supplied, then the signature of the
+    to-be-signed bytes (see README.md for details).
+
+      cocli corim sign  --file=unsigned-corim.cbor \
+                    --signer-cmd=/usr/local/bin/kms-signer \
+                    --signer-arg=--key-id=corim-signer \
+                    --signer-alg=ES256 \
+                    --meta=meta.json
+
+    Produce a detached signature: the COSE Sign1 without its payload is saved
+    to signed-corim.cbor and the payload (the unsigned CoRIM, as signed) to
+    signed-corim.payload.cbor, unless --payload-output is given.
+
+      cocli corim sign  --file=unsigned-corim.cbor \
+                    --key=key.jwk \
+                    --meta=meta.json \
+                    --detached \
+                    --output=signed-corim.cbor
+
+    Obtain an RFC 3161 timestamp token over the signature from the TSA at the
+    supplied URL, and embed it in the unprotected header.  The token proves
+    when the CoRIM was signed, and can be checked by corim verify --tsa-cert.
+
+      cocli corim sign  --file=unsigned-corim.cbor \
+                    --key=key.jwk \
+                    --meta=meta.json \
+                    --tsa-url=http://timestamp.example.com/tsa
+
     Optionally include the signing certificate and certificate chain in the COSE header:
     
       cocli corim sign  --file=unsigned-corim.cbor \
@@ -47,6 +127,18 @@ func NewCorimSignCmd() *cobra.Command {
                     --cert=signing-cert.der \
                     --intermediates=intermediate-certs.der \
                     --output=signed-corim.cbor
+
+    Sign with more than one key, producing a COSE Sign with one signature per
+    key.  If certificates are supplied, there must be one --cert per --key (and
+    one --intermediates per --cert), matched in order.  Use --multi-signer to
+    produce a COSE Sign with a single key, so that more signatures can be
+    appended later with "cocli corim cosign".
+
+      cocli corim sign  --file=unsigned-corim.cbor \
+                    --key=vendor.jwk --cert=vendor.der \
+                    --key=oem.jwk --cert=oem.der \
+                    --meta=meta.json \
+                    --output=signed-corim.cbor
     `,
 
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -54,24 +146,96 @@ func NewCorimSignCmd() *cobra.Command {
 				return err
 			}
 
+			var signers []signerSpec
+			if *corimSignSignerCmd != "" {
+				signers = []signerSpec{{
+					newSigner: externalSignerFactory(
+						*corimSignSignerCmd, *corimSignSignerArgs, *corimSignSignerAlg,
+					),
+				}}
+			} else {
+				getPassphrase := keyPassphrase(*corimSignPassphraseFile)
+				for _, keyFile := range *corimSignKeyFiles {
+					signers = append(signers, signerSpec{
+						newSigner: keySignerFactory(keyFile, getPassphrase),
+					})
+				}
+			}
+
+			// checkCorimSignArgs makes sure there are as many certificates and
+			// intermediates as signers, or none
+			for i, certFile := range *corimSignCertFiles {
+				signers[i].certFile = certFile
+			}
+			for i, intermediatesFile := range *corimSignIntermediateCerts {
+				signers[i].intermediatesFile = intermediatesFile
+			}
+
+			opts := coseSignOptions{
+				kid:      []byte(*corimSignKid),
+				detached: *corimSignDetached,
+				tsaURL:   *corimSignTsaURL,
+			}
+
 			// checkCorimSignArgs makes sure corimSignCorimFile is not nil
-			coseFile, err := sign(*corimSignCorimFile, *corimSignKeyFile,
-				*corimSignMetaFile, corimSignOutputFile, corimSignCertFile, corimSignIntermediateCerts)
+			coseFile, payloadFile, err := sign(*corimSignCorimFile, *corimSignMetaFile,
+				corimSignOutputFile, signers, opts, *corimSignMultiSigner,
+				*corimSignPayloadOutputFile)
 			if err != nil {
 				return err
 			}
-			fmt.Printf(">> %q signed and saved to %q\n", *corimSignCorimFile, coseFile)
+
+			if payloadFile != "" {
+				fmt.Printf(">> %q signed, detached signature saved to %q and payload to %q\n",
+					*corimSignCorimFile, coseFile, payloadFile)
+			} else {
+				fmt.Printf(">> %q signed and saved to %q\n", *corimSignCorimFile, coseFile)
+			}
 
 			return nil
 		},
 	}
 
 	corimSignCorimFile = cmd.Flags().StringP("file", "f", "", "an unsigned CoRIM file (in CBOR format)")
-	corimSignMetaFile = cmd.Flags().StringP("meta", "m", "", "CoRIM Meta file (in JSON format)")
-	corimSignKeyFile = cmd.Flags().StringP("key", "k", "", "signing key in JWK format")
-	corimSignOutputFile = cmd.Flags().StringP("output", "o", "", "name of the generated COSE Sign1 file")
-	corimSignCertFile = cmd.Flags().StringP("cert", "c", "", "signing certificate in DER format")
-	corimSignIntermediateCerts = cmd.Flags().String("intermediates", "", "intermediate certificates in DER format")
+	corimSignMetaFile = cmd.Flags().StringP("meta", "m", "", "CoRIM Meta file (in JSON or YAML format)")
+	corimSignKeyFiles = cmd.Flags().StringArrayP(
+		"key", "k", nil,
+		"signing key (JWK, or PEM/DER-encoded PKCS#8, SEC1 or PKCS#1), or a PKCS#11 URI (repeat for multiple signers)",
+	)
+	corimSignOutputFile = cmd.Flags().StringP("output", "o", "", "name of the generated COSE Sign1 (or COSE Sign) file")
+	corimSignCertFiles = cmd.Flags().StringArrayP(
+		"cert", "c", nil, "signing certificate in DER format (repeat for multiple signers)",
+	)
+	corimSignIntermediateCerts = cmd.Flags().StringArray(
+		"intermediates", nil, "intermediate certificates in DER format (repeat for multiple signers)",
+	)
+	corimSignPassphraseFile = cmd.Flags().String(
+		"passphrase-file", "", "file containing the passphrase of an encrypted signing key",
+	)
+	corimSignSignerCmd = cmd.Flags().String(
+		"signer-cmd", "", "external program that produces the signature (used instead of --key)",
+	)
+	corimSignSignerArgs = cmd.Flags().StringArray(
+		"signer-arg", nil, "argument to pass to --signer-cmd (repeat for multiple arguments)",
+	)
+	corimSignSignerAlg = cmd.Flags().String(
+		"signer-alg", "", "COSE algorithm used by --signer-cmd, e.g. ES256 (default: derived from --cert)",
+	)
+	corimSignKid = cmd.Flags().String(
+		"kid", "", "key identifier to set in the protected header (default: the JWK \"kid\", if any)",
+	)
+	corimSignDetached = cmd.Flags().Bool(
+		"detached", false, "produce a detached signature and save the payload to a separate file",
+	)
+	corimSignPayloadOutputFile = cmd.Flags().String(
+		"payload-output", "", "name of the payload file for --detached (default <output>.payload.cbor)",
+	)
+	corimSignTsaURL = cmd.Flags().String(
+		"tsa-url", "", "URL of an RFC 3161 Time Stamping Authority to timestamp the signature(s)",
+	)
+	corimSignMultiSigner = cmd.Flags().Bool(
+		"multi-signer", false, "produce a COSE Sign even if there is a single signer",
+	)
 
 	return cmd
 }
@@ -81,113 +245,414 @@ func checkCorimSignArgs() error {
 		return errors.New("no CoRIM supplied")
 	}
 
-	if corimSignKeyFile == nil || *corimSignKeyFile == "" {
+	var keyFiles, certFiles, intermediatesFiles []string
+	if corimSignKeyFiles != nil {
+		keyFiles = *corimSignKeyFiles
+	}
+	if corimSignCertFiles != nil {
+		certFiles = *corimSignCertFiles
+	}
+	if corimSignIntermediateCerts != nil {
+		intermediatesFiles = *corimSignIntermediateCerts
+	}
+
+	hasKey := len(keyFiles) != 0
+	hasSignerCmd := corimSignSignerCmd != nil && *corimSignSignerCmd != ""
+
+	if !hasKey && !hasSignerCmd {
 		return errors.New("no key supplied")
 	}
 
+	if hasKey && hasSignerCmd {
+		return errors.New("only one of --key and --signer-cmd can be used at the same time")
+	}
+
+	if !hasSignerCmd && corimSignSignerAlg != nil && *corimSignSignerAlg != "" {
+		return errors.New("--signer-alg can only be used with --signer-cmd")
+	}
+
+	if !hasSignerCmd && corimSignSignerArgs != nil && len(*corimSignSignerArgs) != 0 {
+		return errors.New("--signer-arg can only be used with --signer-cmd")
+	}
+
 	if corimSignMetaFile == nil || *corimSignMetaFile == "" {
 		return errors.New("no CoRIM Meta supplied")
 	}
 
+	if corimSignPayloadOutputFile != nil && *corimSignPayloadOutputFile != "" &&
+		(corimSignDetached == nil || !*corimSignDetached) {
+		return errors.New("--payload-output can only be used with --detached")
+	}
+
+	multiSigner := len(keyFiles) > 1 || (corimSignMultiSigner != nil && *corimSignMultiSigner)
+
+	if !multiSigner {
+		if len(certFiles) > 1 || len(intermediatesFiles) > 1 {
+			return errors.New("multiple --cert or --intermediates require one --key per signer")
+		}
+		return nil
+	}
+
+	if hasSignerCmd {
+		return errors.New("--signer-cmd cannot be used with --multi-signer")
+	}
+
+	if len(keyFiles) > 1 && corimSignKid != nil && *corimSignKid != "" {
+		return errors.New("--kid can only be used with a single signer")
+	}
+
+	if len(certFiles) != 0 && len(certFiles) != len(keyFiles) {
+		return fmt.Errorf("got %d --cert for %d --key: supply one per signer, or none", len(certFiles), len(keyFiles))
+	}
+
+	if len(intermediatesFiles) != 0 && len(intermediatesFiles) != len(certFiles) {
+		return fmt.Errorf(
+			"got %d --intermediates for %d --cert: supply one per signer, or none",
+			len(intermediatesFiles), len(certFiles),
+		)
+	}
+
 	return nil
 }
 
-func sign(unsignedCorimFile, keyFile, metaFile string, outputFile, certFile, intermediatesFile *string) (string, error) {
+// signingKey is a COSE signer along with the identifier of its key, if known,
+// and a function that releases any resources held by the signer
+type signingKey struct {
+	signer  cose.Signer
+	kid     string
+	release func()
+}
+
+// signerFactory creates the signing key for the supplied CoRIM
+type signerFactory func(s *corim.SignedCorim) (*signingKey, error)
+
+// keySignerFactory returns a signerFactory for the key in keyFile, or for the
+// key in a PKCS#11 token if keyFile is a PKCS#11 URI
+func keySignerFactory(keyFile string, getPassphrase passphraseFunc) signerFactory {
+	return func(*corim.SignedCorim) (*signingKey, error) {
+		if isPKCS11URI(keyFile) {
+			signer, closeToken, err := newPKCS11Signer(keyFile)
+			if err != nil {
+				return nil, fmt.Errorf("error loading signing key from PKCS#11 token: %w", err)
+			}
+			return &signingKey{signer: signer, release: closeToken}, nil
+		}
+
+		keyData, err := afero.ReadFile(fs, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading signing key from %s: %w", keyFile, err)
+		}
+
+		signer, err := newSignerFromKeyData(keyData, getPassphrase)
+		if err != nil {
+			return nil, fmt.Errorf("error loading signing key from %s: %w", keyFile, err)
+		}
+
+		return &signingKey{signer: signer, kid: jwkKeyID(keyData), release: func() {}}, nil
+	}
+}
+
+// externalSignerFactory returns a signerFactory for an external signer.  If no
+// signing certificate was supplied, the certificate chain returned by the
+// external signer, if any, is added to the CoRIM.  If not supplied, the
+// algorithm is derived from the signing certificate.
+func externalSignerFactory(command string, args []string, algName string) signerFactory {
+	return func(s *corim.SignedCorim) (*signingKey, error) {
+		var (
+			alg cose.Algorithm
+			err error
+		)
+
+		es := newExternalSigner(command, args)
+
+		if s.SigningCert == nil {
+			if err = addExternalSignerCerts(s, es); err != nil {
+				return nil, err
+			}
+		}
+
+		switch {
+		case algName != "":
+			alg, err = algFromName(algName)
+		case s.SigningCert != nil:
+			alg, err = algFromKey(s.SigningCert.PublicKey)
+		default:
+			err = errNoSignerAlg
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("error setting up external signer: %w", err)
+		}
+
+		es.alg = alg
+
+		return &signingKey{signer: es, release: func() {}}, nil
+	}
+}
+
+// addExternalSignerCerts adds the certificate chain of the external signer, if
+// any, to the supplied CoRIM.  Since x5chain is a protected header, the chain
+// must be known before signing.
+func addExternalSignerCerts(s *corim.SignedCorim, es *externalSigner) error {
+	chain, err := es.CertChain()
+	if err != nil {
+		return fmt.Errorf("error retrieving certificate chain from external signer: %w", err)
+	}
+
+	if len(chain) == 0 {
+		return nil
+	}
+
+	if err = s.AddSigningCert(chain[0]); err != nil {
+		return fmt.Errorf("error adding signing certificate from external signer: %w", err)
+	}
+
+	if len(chain) > 1 {
+		if err = s.AddIntermediateCerts(bytes.Join(chain[1:], nil)); err != nil {
+			return fmt.Errorf("error adding intermediate certificates from external signer: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// signerSpec describes one of the signers of a CoRIM: how to create its signing
+// key and where to find its (optional) certificates
+type signerSpec struct {
+	newSigner         signerFactory
+	certFile          string
+	intermediatesFile string
+}
+
+// sign signs the unsigned CoRIM and saves the result.  With a single signer,
+// and unless multiSigner is set, a COSE Sign1 is produced, otherwise a COSE
+// Sign with one signature per signer.  It returns the name of the signed CoRIM
+// file and, for detached signatures, the name of the payload file.
+func sign(
+	unsignedCorimFile, metaFile string,
+	outputFile *string,
+	signers []signerSpec,
+	opts coseSignOptions,
+	multiSigner bool,
+	payloadOutputFile string,
+) (string, string, error) {
 	var (
 		unsignedCorimCBOR []byte
 		signedCorimCBOR   []byte
+		payloadCBOR       []byte
 		metaJSON          []byte
-		keyJWK            []byte
-		certDER           []byte
-		intermediatesDER  []byte
 		err               error
 		signedCorimFile   string
 		c                 corim.UnsignedCorim
 		m                 corim.Meta
-		signer            cose.Signer
 	)
 
 	if unsignedCorimCBOR, err = afero.ReadFile(fs, unsignedCorimFile); err != nil {
-		return "", fmt.Errorf("error loading unsigned CoRIM from %s: %w", unsignedCorimFile, err)
+		return "", "", fmt.Errorf("error loading unsigned CoRIM from %s: %w", unsignedCorimFile, err)
 	}
 
 	if err = c.FromCBOR(unsignedCorimCBOR); err != nil {
-		return "", fmt.Errorf("error decoding unsigned CoRIM from %s: %w", unsignedCorimFile, err)
+		return "", "", fmt.Errorf("error decoding unsigned CoRIM from %s: %w", unsignedCorimFile, err)
 	}
 
 	if err = c.Valid(); err != nil {
-		return "", fmt.Errorf("error validating CoRIM: %w", err)
+		return "", "", fmt.Errorf("error validating CoRIM: %w", err)
 	}
 
 	if metaJSON, err = afero.ReadFile(fs, metaFile); err != nil {
-		return "", fmt.Errorf("error loading CoRIM Meta from %s: %w", metaFile, err)
+		return "", "", fmt.Errorf("error loading CoRIM Meta from %s: %w", metaFile, err)
 	}
 
-	if err = m.FromJSON(metaJSON); err != nil {
-		return "", fmt.Errorf("error decoding CoRIM Meta from %s: %w", metaFile, err)
+	if err = decodeTemplate(&m, metaJSON, metaFile); err != nil {
+		return "", "", fmt.Errorf("error decoding CoRIM Meta from %s: %w", metaFile, err)
 	}
 
 	if err = m.Valid(); err != nil {
-		return "", fmt.Errorf("error validating CoRIM Meta: %w", err)
+		return "", "", fmt.Errorf("error validating CoRIM Meta: %w", err)
+	}
+
+	if len(signers) > 1 || multiSigner {
+		signedCorimCBOR, payloadCBOR, err = signMulti(&c, &m, signers, opts)
+	} else {
+		signedCorimCBOR, payloadCBOR, err = signSingle(&c, &m, signers[0], opts)
+	}
+	if err != nil {
+		return "", "", err
 	}
 
-	if keyJWK, err = afero.ReadFile(fs, keyFile); err != nil {
-		return "", fmt.Errorf("error loading signing key from %s: %w", keyFile, err)
+	if opts.tsaURL != "" {
+		if signedCorimCBOR, err = timestampCorim(signedCorimCBOR, opts.tsaURL); err != nil {
+			return "", "", fmt.Errorf("error timestamping CoRIM with TSA at %s: %w", opts.tsaURL, err)
+		}
 	}
 
-	if signer, err = corim.NewSignerFromJWK(keyJWK); err != nil {
-		return "", fmt.Errorf("error loading signing key from %s: %w", keyFile, err)
+	if outputFile == nil || *outputFile == "" {
+		signedCorimFile = "signed-" + unsignedCorimFile
+	} else {
+		signedCorimFile = *outputFile
 	}
 
+	err = afero.WriteFile(fs, signedCorimFile, signedCorimCBOR, 0644)
+	if err != nil {
+		return "", "", fmt.Errorf("error saving signed CoRIM to file %s: %w", signedCorimFile, err)
+	}
+
+	if !opts.detached {
+		return signedCorimFile, "", nil
+	}
+
+	// the payload is saved as signed, which may differ from the input file
+	// if the latter is not in canonical CBOR
+	if payloadOutputFile == "" {
+		payloadOutputFile = makeFileName(filepath.Dir(signedCorimFile), signedCorimFile, ".payload.cbor")
+	}
+
+	err = afero.WriteFile(fs, payloadOutputFile, payloadCBOR, 0644)
+	if err != nil {
+		return "", "", fmt.Errorf("error saving payload to file %s: %w", payloadOutputFile, err)
+	}
+
+	return signedCorimFile, payloadOutputFile, nil
+}
+
+// signSingle wraps the CoRIM in a COSE Sign1 signed by the supplied signer
+func signSingle(c *corim.UnsignedCorim, m *corim.Meta, spec signerSpec, opts coseSignOptions) ([]byte, []byte, error) {
 	s := corim.SignedCorim{
-		UnsignedCorim: c,
-		Meta:          m,
+		UnsignedCorim: *c,
+		Meta:          *m,
+	}
+
+	if err := addSignerCerts(&s, spec); err != nil {
+		return nil, nil, err
+	}
+
+	key, err := spec.newSigner(&s)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer key.release()
+
+	// an explicit key identifier takes precedence over the one from the key
+	if len(opts.kid) == 0 {
+		opts.kid = []byte(key.kid)
+	}
+
+	signed, payload, err := signCorim(&s, key.signer, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error signing CoRIM: %w", err)
+	}
+
+	if _, ok := key.signer.(*externalSigner); ok && s.SigningCert != nil {
+		if err = checkExternalSignature(&s, opts, signed, payload); err != nil {
+			return nil, nil, err
+		}
 	}
 
+	return signed, payload, nil
+}
+
+// signMulti wraps the CoRIM in a COSE Sign with a signature from each of the
+// supplied signers
+func signMulti(c *corim.UnsignedCorim, m *corim.Meta, specs []signerSpec, opts coseSignOptions) ([]byte, []byte, error) {
+	signers := make([]coseSigner, 0, len(specs))
+
+	for _, spec := range specs {
+		// the SignedCorim is only used to hold the certificates of the
+		// signer
+		var s corim.SignedCorim
+
+		if err := addSignerCerts(&s, spec); err != nil {
+			return nil, nil, err
+		}
+
+		key, err := spec.newSigner(&s)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer key.release()
+
+		kid := opts.kid
+		if len(kid) == 0 {
+			kid = []byte(key.kid)
+		}
+
+		signers = append(signers, coseSigner{
+			signer:            key.signer,
+			kid:               kid,
+			signingCert:       s.SigningCert,
+			intermediateCerts: s.IntermediateCerts,
+		})
+	}
+
+	signed, payload, err := signMultiCorim(c, m, signers, opts.detached)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error signing CoRIM: %w", err)
+	}
+
+	return signed, payload, nil
+}
+
+// addSignerCerts adds the signing certificate and intermediate certificates of
+// the signer, if any, to the supplied CoRIM
+func addSignerCerts(s *corim.SignedCorim, spec signerSpec) error {
+	var (
+		certDER          []byte
+		intermediatesDER []byte
+		err              error
+	)
+
 	// Add signing certificate if provided
-	if certFile != nil && *certFile != "" {
-		if certDER, err = afero.ReadFile(fs, *certFile); err != nil {
-			return "", fmt.Errorf("error loading signing certificate from %s: %w", *certFile, err)
+	if spec.certFile != "" {
+		if certDER, err = afero.ReadFile(fs, spec.certFile); err != nil {
+			return fmt.Errorf("error loading signing certificate from %s: %w", spec.certFile, err)
 		}
 
 		if err = s.AddSigningCert(certDER); err != nil {
-			return "", fmt.Errorf("error adding signing certificate: %w", err)
+			return fmt.Errorf("error adding signing certificate: %w", err)
 		}
 	}
 
 	// Add intermediate certificates if provided
-	if intermediatesFile != nil && *intermediatesFile != "" {
+	if spec.intermediatesFile != "" {
 		// Ensure signing certificate was provided
-		if certFile == nil || *certFile == "" {
-			return "", fmt.Errorf("cannot add intermediate certificates without a signing certificate")
+		if spec.certFile == "" {
+			return fmt.Errorf("cannot add intermediate certificates without a signing certificate")
 		}
 
-		if intermediatesDER, err = afero.ReadFile(fs, *intermediatesFile); err != nil {
-			return "", fmt.Errorf("error loading intermediate certificates from %s: %w", *intermediatesFile, err)
+		if intermediatesDER, err = afero.ReadFile(fs, spec.intermediatesFile); err != nil {
+			return fmt.Errorf("error loading intermediate certificates from %s: %w", spec.intermediatesFile, err)
 		}
 
 		if err = s.AddIntermediateCerts(intermediatesDER); err != nil {
-			return "", fmt.Errorf("error adding intermediate certificates: %w", err)
+			return fmt.Errorf("error adding intermediate certificates: %w", err)
 		}
 	}
 
-	signedCorimCBOR, err = s.Sign(signer)
-	if err != nil {
-		return "", fmt.Errorf("error signing CoRIM: %w", err)
+	return nil
+}
+
+// checkExternalSignature checks the signature produced by the external signer
+// against the signing certificate
+func checkExternalSignature(s *corim.SignedCorim, opts coseSignOptions, signed, payload []byte) error {
+	var err error
+
+	attached := signed
+	if opts.detached {
+		if attached, err = attachPayload(signed, payload); err != nil {
+			return fmt.Errorf("error attaching payload: %w", err)
+		}
 	}
 
-	if outputFile == nil || *outputFile == "" {
-		signedCorimFile = "signed-" + unsignedCorimFile
-	} else {
-		signedCorimFile = *outputFile
+	var check corim.SignedCorim
+	if err = check.FromCOSE(attached); err != nil {
+		return fmt.Errorf("error decoding signed CoRIM: %w", err)
 	}
 
-	err = afero.WriteFile(fs, signedCorimFile, signedCorimCBOR, 0644)
-	if err != nil {
-		return "", fmt.Errorf("error saving signed CoRIM to file %s: %w", signedCorimFile, err)
+	if err = check.Verify(s.SigningCert.PublicKey); err != nil {
+		return fmt.Errorf("signature from external signer does not match the signing certificate: %w", err)
 	}
 
-	return signedCorimFile, nil
+	return nil
 }
 
 func init() {
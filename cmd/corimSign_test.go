@@ -4,11 +4,19 @@
 package cmd
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
 	"testing"
 
 	"github.com/spf13/afero"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/veraison/corim/corim"
+	cose "github.com/veraison/go-cose"
+	"github.com/youmark/pkcs8"
 )
 
 func Test_CorimSignCmd_unknown_argument(t *testing.T) {
@@ -450,3 +458,509 @@ func Test_CorimSignCmd_nonexistent_intermediates_file(t *testing.T) {
 	err = cmd.Execute()
 	assert.EqualError(t, err, "error loading intermediate certificates from nonexistent.der: open nonexistent.der: file does not exist")
 }
+
+func Test_CorimSignCmd_ok_with_pem_key(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	cmd := NewCorimSignCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--key=key.pem",
+		"--meta=ok.json",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "ok.cbor", testCorimValid, 0644)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, "ok.json", testMetaValid, 0644)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, "key.pem", pemEncode("PRIVATE KEY", mustPKCS8(t, key)), 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	data, err := afero.ReadFile(fs, "signed-ok.cbor")
+	require.NoError(t, err)
+
+	var s corim.SignedCorim
+	require.NoError(t, s.FromCOSE(data))
+	assert.NoError(t, s.Verify(key.Public()))
+}
+
+// writeSignFixtures resets the file system and saves the unsigned CoRIM, the
+// meta and the key used by the signing tests to ok.cbor, ok.json and ok.jwk
+func writeSignFixtures(t *testing.T) {
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "ok.cbor", testCorimValid, 0644))
+	require.NoError(t, afero.WriteFile(fs, "ok.json", testMetaValid, 0644))
+	require.NoError(t, afero.WriteFile(fs, "ok.jwk", testECKey, 0644))
+}
+
+func writeEncryptedKeyFixtures(t *testing.T, passphrase string) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err := pkcs8.MarshalPrivateKey(key, []byte(passphrase), nil)
+	require.NoError(t, err)
+
+	writeSignFixtures(t)
+	err = afero.WriteFile(fs, "key.pem", pemEncode("ENCRYPTED PRIVATE KEY", der), 0644)
+	require.NoError(t, err)
+
+	return key
+}
+
+func Test_CorimSignCmd_encrypted_key_passphrase_file_ok(t *testing.T) {
+	key := writeEncryptedKeyFixtures(t, "s3cr3t")
+	err := afero.WriteFile(fs, "passphrase.txt", []byte("s3cr3t\n"), 0600)
+	require.NoError(t, err)
+
+	cmd := NewCorimSignCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--key=key.pem",
+		"--meta=ok.json",
+		"--passphrase-file=passphrase.txt",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	data, err := afero.ReadFile(fs, "signed-ok.cbor")
+	require.NoError(t, err)
+
+	var s corim.SignedCorim
+	require.NoError(t, s.FromCOSE(data))
+	assert.NoError(t, s.Verify(key.Public()))
+
+	// make sure nothing other than the signed CoRIM has been written
+	files, err := afero.ReadDir(fs, ".")
+	require.NoError(t, err)
+	assert.Len(t, files, 6)
+}
+
+func Test_CorimSignCmd_encrypted_key_passphrase_from_config_ok(t *testing.T) {
+	writeEncryptedKeyFixtures(t, "s3cr3t")
+
+	viper.Set("passphrase", "s3cr3t")
+	defer viper.Set("passphrase", "")
+
+	cmd := NewCorimSignCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--key=key.pem",
+		"--meta=ok.json",
+	}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.NoError(t, err)
+}
+
+func Test_CorimSignCmd_encrypted_key_wrong_passphrase(t *testing.T) {
+	writeEncryptedKeyFixtures(t, "s3cr3t")
+	err := afero.WriteFile(fs, "passphrase.txt", []byte("wrong"), 0600)
+	require.NoError(t, err)
+
+	cmd := NewCorimSignCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--key=key.pem",
+		"--meta=ok.json",
+		"--passphrase-file=passphrase.txt",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.ErrorContains(t, err, "error loading signing key from key.pem: error decrypting private key")
+}
+
+func Test_CorimSignCmd_encrypted_key_no_passphrase(t *testing.T) {
+	writeEncryptedKeyFixtures(t, "s3cr3t")
+
+	cmd := NewCorimSignCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--key=key.pem",
+		"--meta=ok.json",
+	}
+	cmd.SetArgs(args)
+
+	// stdin is not a terminal when running tests
+	err := cmd.Execute()
+	assert.EqualError(t, err, "error loading signing key from key.pem: "+
+		"no passphrase supplied: use --passphrase-file, set COCLI_PASSPHRASE, or run interactively")
+}
+
+func signedCorimKeyID(t *testing.T, data []byte) []byte {
+	msg, err := decodeSign1(data)
+	require.NoError(t, err)
+	return msg.Headers.Protected[cose.HeaderLabelKeyID].([]byte)
+}
+
+func Test_CorimSignCmd_kid_from_jwk(t *testing.T) {
+	cmd := NewCorimSignCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--key=ok.jwk",
+		"--meta=ok.json",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "ok.cbor", testCorimValid, 0644))
+	require.NoError(t, afero.WriteFile(fs, "ok.json", testMetaValid, 0644))
+	require.NoError(t, afero.WriteFile(fs, "ok.jwk", testECKey, 0644))
+
+	require.NoError(t, cmd.Execute())
+
+	data, err := afero.ReadFile(fs, "signed-ok.cbor")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), signedCorimKeyID(t, data))
+
+	var s corim.SignedCorim
+	require.NoError(t, s.FromCOSE(data))
+	pk, err := newPublicKeyFromKeyData(testECKey)
+	require.NoError(t, err)
+	assert.NoError(t, s.Verify(pk))
+}
+
+func Test_CorimSignCmd_kid_flag_overrides_jwk(t *testing.T) {
+	cmd := NewCorimSignCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--key=ok.jwk",
+		"--meta=ok.json",
+		"--kid=2025-q3",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "ok.cbor", testCorimValid, 0644))
+	require.NoError(t, afero.WriteFile(fs, "ok.json", testMetaValid, 0644))
+	require.NoError(t, afero.WriteFile(fs, "ok.jwk", testECKey, 0644))
+
+	require.NoError(t, cmd.Execute())
+
+	data, err := afero.ReadFile(fs, "signed-ok.cbor")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("2025-q3"), signedCorimKeyID(t, data))
+}
+
+func Test_CorimSignCmd_no_kid_with_pem_key(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	cmd := NewCorimSignCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--key=key.pem",
+		"--meta=ok.json",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "ok.cbor", testCorimValid, 0644))
+	require.NoError(t, afero.WriteFile(fs, "ok.json", testMetaValid, 0644))
+	require.NoError(t, afero.WriteFile(fs, "key.pem", pemEncode("PRIVATE KEY", mustPKCS8(t, key)), 0644))
+
+	require.NoError(t, cmd.Execute())
+
+	data, err := afero.ReadFile(fs, "signed-ok.cbor")
+	require.NoError(t, err)
+
+	msg, err := decodeSign1(data)
+	require.NoError(t, err)
+	assert.NotContains(t, msg.Headers.Protected, cose.HeaderLabelKeyID)
+}
+
+func Test_CorimSignCmd_detached_ok(t *testing.T) {
+	cmd := NewCorimSignCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--key=ok.jwk",
+		"--meta=ok.json",
+		"--detached",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "ok.cbor", testCorimValid, 0644))
+	require.NoError(t, afero.WriteFile(fs, "ok.json", testMetaValid, 0644))
+	require.NoError(t, afero.WriteFile(fs, "ok.jwk", testECKey, 0644))
+
+	require.NoError(t, cmd.Execute())
+
+	signed, err := afero.ReadFile(fs, "signed-ok.cbor")
+	require.NoError(t, err)
+	payload, err := afero.ReadFile(fs, "signed-ok.payload.cbor")
+	require.NoError(t, err)
+
+	msg, err := decodeSign1(signed)
+	require.NoError(t, err)
+	assert.Nil(t, msg.Payload)
+
+	var c corim.UnsignedCorim
+	require.NoError(t, c.FromCBOR(payload))
+
+	attached, err := attachPayload(signed, payload)
+	require.NoError(t, err)
+
+	var s corim.SignedCorim
+	require.NoError(t, s.FromCOSE(attached))
+	pk, err := newPublicKeyFromKeyData(testECKey)
+	require.NoError(t, err)
+	assert.NoError(t, s.Verify(pk))
+}
+
+func Test_CorimSignCmd_detached_custom_payload_output(t *testing.T) {
+	cmd := NewCorimSignCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--key=ok.jwk",
+		"--meta=ok.json",
+		"--detached",
+		"--output=corim.sig",
+		"--payload-output=corim.payload",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "ok.cbor", testCorimValid, 0644))
+	require.NoError(t, afero.WriteFile(fs, "ok.json", testMetaValid, 0644))
+	require.NoError(t, afero.WriteFile(fs, "ok.jwk", testECKey, 0644))
+
+	require.NoError(t, cmd.Execute())
+
+	_, err := fs.Stat("corim.sig")
+	assert.NoError(t, err)
+	_, err = fs.Stat("corim.payload")
+	assert.NoError(t, err)
+}
+
+func Test_CorimSignCmd_payload_output_without_detached(t *testing.T) {
+	cmd := NewCorimSignCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--key=ok.jwk",
+		"--meta=ok.json",
+		"--payload-output=corim.payload",
+	}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "--payload-output can only be used with --detached")
+}
+
+func writeMultiSignerFixtures(t *testing.T, pkis ...testPKI) {
+	writeSignFixtures(t)
+
+	for i, pki := range pkis {
+		name := fmt.Sprintf("signer%d", i)
+		require.NoError(t, afero.WriteFile(fs, name+".pem", pemEncode("PRIVATE KEY", mustPKCS8(t, pki.LeafKey)), 0644))
+		require.NoError(t, afero.WriteFile(fs, name+".der", pki.Leaf, 0644))
+		require.NoError(t, afero.WriteFile(fs, name+"-int.der", pki.Intermediate, 0644))
+	}
+}
+
+func Test_CorimSignCmd_multi_signer_ok(t *testing.T) {
+	pkis := []testPKI{makeTestPKI(t), makeTestPKI(t)}
+	writeMultiSignerFixtures(t, pkis...)
+
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--key=signer0.pem", "--cert=signer0.der", "--intermediates=signer0-int.der",
+		"--key=signer1.pem", "--cert=signer1.der", "--intermediates=signer1-int.der",
+	})
+
+	require.NoError(t, cmd.Execute())
+
+	data, err := afero.ReadFile(fs, "signed-ok.cbor")
+	require.NoError(t, err)
+	assert.True(t, isMultiSigned(data))
+
+	vc, err := loadSignedCorim("signed-ok.cbor", "")
+	require.NoError(t, err)
+	assert.True(t, vc.MultiSigned)
+	require.Len(t, vc.Signatures, 2)
+
+	for i, sig := range vc.Signatures {
+		require.NotNil(t, sig.signingCert)
+		assert.Equal(t, pkis[i].Leaf, sig.signingCert.Raw)
+		require.Len(t, sig.intermediateCerts, 1)
+		assert.Equal(t, pkis[i].Intermediate, sig.intermediateCerts[0].Raw)
+		assert.NoError(t, sig.verify(pkis[i].LeafKey.Public()))
+		assert.Error(t, sig.verify(pkis[1-i].LeafKey.Public()))
+	}
+}
+
+func Test_CorimSignCmd_multi_signer_flag_single_key(t *testing.T) {
+	writeMultiSignerFixtures(t)
+
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--key=ok.jwk",
+		"--multi-signer",
+	})
+
+	require.NoError(t, cmd.Execute())
+
+	vc, err := loadSignedCorim("signed-ok.cbor", "")
+	require.NoError(t, err)
+	assert.True(t, vc.MultiSigned)
+	require.Len(t, vc.Signatures, 1)
+	assert.Equal(t, []byte("1"), vc.Signatures[0].kid)
+
+	pk, err := newPublicKeyFromKeyData(testECKey)
+	require.NoError(t, err)
+	assert.NoError(t, vc.Signatures[0].verify(pk))
+}
+
+func Test_CorimSignCmd_multi_signer_detached(t *testing.T) {
+	pkis := []testPKI{makeTestPKI(t), makeTestPKI(t)}
+	writeMultiSignerFixtures(t, pkis...)
+
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--key=signer0.pem",
+		"--key=signer1.pem",
+		"--detached",
+	})
+
+	require.NoError(t, cmd.Execute())
+
+	_, err := loadSignedCorim("signed-ok.cbor", "")
+	assert.EqualError(t, err, "signed-ok.cbor has a detached payload: use --payload")
+
+	vc, err := loadSignedCorim("signed-ok.cbor", "signed-ok.payload.cbor")
+	require.NoError(t, err)
+	require.Len(t, vc.Signatures, 2)
+	assert.NoError(t, vc.Signatures[1].verify(pkis[1].LeafKey.Public()))
+}
+
+func Test_CorimSignCmd_multi_signer_cert_count_mismatch(t *testing.T) {
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--key=signer0.pem", "--cert=signer0.der",
+		"--key=signer1.pem",
+	})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "got 1 --cert for 2 --key: supply one per signer, or none")
+}
+
+func Test_CorimSignCmd_multi_signer_intermediates_count_mismatch(t *testing.T) {
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--key=signer0.pem", "--cert=signer0.der", "--intermediates=signer0-int.der",
+		"--key=signer1.pem", "--cert=signer1.der",
+	})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "got 1 --intermediates for 2 --cert: supply one per signer, or none")
+}
+
+func Test_CorimSignCmd_multi_signer_kid(t *testing.T) {
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--key=signer0.pem",
+		"--key=signer1.pem",
+		"--kid=k1",
+	})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "--kid can only be used with a single signer")
+}
+
+func Test_CorimSignCmd_multi_signer_with_signer_cmd(t *testing.T) {
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--signer-cmd=signer",
+		"--multi-signer",
+	})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "--signer-cmd cannot be used with --multi-signer")
+}
+
+func Test_CorimSignCmd_single_signer_multiple_certs(t *testing.T) {
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--key=signer0.pem",
+		"--cert=signer0.der",
+		"--cert=signer1.der",
+	})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "multiple --cert or --intermediates require one --key per signer")
+}
+
+func Test_signCorim_kid_and_detached(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	signer, err := cose.NewSigner(cose.AlgorithmES256, key)
+	require.NoError(t, err)
+
+	s := corim.SignedCorim{UnsignedCorim: *makeTestCorimWithComid(t)}
+	s.Meta.SetSigner("ACME Ltd.", nil)
+
+	signed, payload, err := signCorim(&s, signer, coseSignOptions{kid: []byte("2025-q3"), detached: true})
+	require.NoError(t, err)
+
+	msg, err := decodeSign1(signed)
+	require.NoError(t, err)
+	assert.Nil(t, msg.Payload)
+	assert.Equal(t, []byte("2025-q3"), msg.Headers.Protected[cose.HeaderLabelKeyID])
+
+	attached, err := attachPayload(signed, payload)
+	require.NoError(t, err)
+
+	var check corim.SignedCorim
+	require.NoError(t, check.FromCOSE(attached))
+	assert.NoError(t, check.Verify(key.Public()))
+}
+
+func Test_signCorim_signer_without_algorithm(t *testing.T) {
+	s := corim.SignedCorim{UnsignedCorim: *makeTestCorimWithComid(t)}
+	s.Meta.SetSigner("ACME Ltd.", nil)
+
+	for _, opts := range []coseSignOptions{{}, {kid: []byte("2025-q3")}} {
+		_, _, err := signCorim(&s, placeholderSigner{alg: cose.Algorithm(-65000)}, opts)
+		assert.EqualError(t, err, "signer has no algorithm (got Algorithm(-65000))")
+
+		_, _, err = signCorim(&s, placeholderSigner{}, opts)
+		assert.EqualError(t, err, "signer has no algorithm (got Reserved)")
+	}
+}
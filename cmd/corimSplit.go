@@ -0,0 +1,392 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/corim/corim"
+)
+
+var (
+	corimSplitCorimFile *string
+	corimSplitByType    *bool
+	corimSplitMaxTags   *int
+	corimSplitMaxSize   *int
+	corimSplitBaseURI   *string
+	corimSplitOutputDir *string
+)
+
+var corimSplitCmd = NewCorimSplitCmd()
+
+func NewCorimSplitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "split",
+		Short: "partition the tags of a CoRIM into multiple CoRIMs",
+		Long: `partition the tags of a CoRIM into multiple CoRIMs
+
+    Split the CoRIM in corim.cbor into CoRIMs of at most 4096 bytes each, saved
+    in the output.d/ directory (created if needed) as corim-000.cbor,
+    corim-001.cbor, and so on.  Tags are kept in their original order.  Any
+    existing files with the same names are overwritten.
+
+      cocli corim split --file=corim.cbor --max-size=4096 --output-dir=output.d
+
+    Put CoMIDs, CoSWIDs and CoTS in separate CoRIMs, with at most 10 tags each:
+
+      cocli corim split --file=corim.cbor --by-type --max-tags=10
+
+    Each resulting CoRIM has a fresh corim-id, keeps the other fields of the
+    original CoRIM, and has a dependent-rims entry for each of its siblings.
+    By default, siblings are referred to by corim-id (as urn:uuid:<corim-id>).
+    If the CoRIMs are to be published, they can be referred to by URL instead,
+    by supplying the location of the output directory:
+
+      cocli corim split --file=corim.cbor --max-size=4096 \
+                    --base-uri=https://example.com/corims/
+
+    The input CoRIM can be signed or unsigned.  The resulting CoRIMs are
+    unsigned, and each can be signed using corim sign.
+    `,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkCorimSplitArgs(); err != nil {
+				return err
+			}
+
+			opts := splitOptions{
+				byType:  *corimSplitByType,
+				maxTags: *corimSplitMaxTags,
+				maxSize: *corimSplitMaxSize,
+				baseURI: *corimSplitBaseURI,
+			}
+
+			files, err := split(*corimSplitCorimFile, *corimSplitOutputDir, opts)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf(">> split %q into %d CoRIM(s)\n", *corimSplitCorimFile, len(files))
+			for _, f := range files {
+				fmt.Printf(">> [created] %q\n", f)
+			}
+
+			return nil
+		},
+	}
+
+	corimSplitCorimFile = cmd.Flags().StringP("file", "f", "", "a CoRIM file (in CBOR format)")
+	corimSplitByType = cmd.Flags().Bool("by-type", false, "put CoMIDs, CoSWIDs and CoTS in separate CoRIMs")
+	corimSplitMaxTags = cmd.Flags().Int("max-tags", 0, "maximum number of tags in each CoRIM")
+	corimSplitMaxSize = cmd.Flags().Int("max-size", 0, "maximum size in bytes of each (CBOR-encoded) CoRIM")
+	corimSplitBaseURI = cmd.Flags().String(
+		"base-uri", "", "URI of the output directory, used in the dependent-rims links (default: by corim-id)",
+	)
+	corimSplitOutputDir = cmd.Flags().StringP("output-dir", "o", ".", "directory for the resulting CoRIMs")
+
+	return cmd
+}
+
+func checkCorimSplitArgs() error {
+	if corimSplitCorimFile == nil || *corimSplitCorimFile == "" {
+		return errors.New("no CoRIM supplied")
+	}
+
+	if *corimSplitMaxTags < 0 || *corimSplitMaxSize < 0 {
+		return errors.New("--max-tags and --max-size must not be negative")
+	}
+
+	if !*corimSplitByType && *corimSplitMaxTags == 0 && *corimSplitMaxSize == 0 {
+		return errors.New("no split criteria supplied: use --by-type, --max-tags and/or --max-size")
+	}
+
+	return nil
+}
+
+// splitOptions controls how the tags of a CoRIM are partitioned by split.  The
+// criteria are applied in order: by type, then by count, then by size.
+type splitOptions struct {
+	byType bool
+	// maximum number of tags in each part, if not zero
+	maxTags int
+	// maximum encoded size of each part, if not zero
+	maxSize int
+	// if not empty, siblings are linked by URI, rather than by corim-id
+	baseURI string
+}
+
+// corimSplitter creates the parts of a split CoRIM
+type corimSplitter struct {
+	orig      *corim.UnsignedCorim
+	baseName  string
+	outputDir string
+	baseURI   string
+	// the corim-ids of the parts created so far
+	ids []uuid.UUID
+}
+
+// fileName returns the name of the file for the i-th part
+func (o *corimSplitter) fileName(i int) string {
+	return filepath.Join(o.outputDir, fmt.Sprintf("%s-%03d.cbor", o.baseName, i))
+}
+
+// part returns the i-th of n parts, with the supplied tags
+func (o *corimSplitter) part(i, n int, tags []corim.Tag) (*corim.UnsignedCorim, error) {
+	for len(o.ids) < n {
+		o.ids = append(o.ids, uuid.New())
+	}
+
+	p := *o.orig
+
+	if p.SetID(o.ids[i]) == nil {
+		return nil, errors.New("error setting corim-id")
+	}
+
+	p.Tags = tags
+
+	var locators []corim.Locator
+	if o.orig.DependentRims != nil {
+		locators = append(locators, *o.orig.DependentRims...)
+	}
+
+	for j := 0; j < n; j++ {
+		if j == i {
+			continue
+		}
+
+		href := "urn:uuid:" + o.ids[j].String()
+		if o.baseURI != "" {
+			href = strings.TrimSuffix(o.baseURI, "/") + "/" + filepath.Base(o.fileName(j))
+		}
+
+		locators = append(locators, corim.Locator{Href: comid.TaggedURI(href)})
+	}
+
+	p.DependentRims = nil
+	if len(locators) != 0 {
+		p.DependentRims = &locators
+	}
+
+	return &p, nil
+}
+
+// split partitions the tags of the CoRIM in corimFile according to the
+// supplied options, saves each part to outputDir, and returns the names of the
+// resulting files
+func split(corimFile, outputDir string, opts splitOptions) ([]string, error) {
+	u, meta, err := loadCorim(corimFile)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := [][]corim.Tag{u.Tags}
+
+	if opts.byType {
+		if groups, err = splitTagsByType(u.Tags); err != nil {
+			return nil, fmt.Errorf("error splitting %s: %w", corimFile, err)
+		}
+	}
+
+	if opts.maxTags != 0 {
+		groups = splitTagsByCount(groups, opts.maxTags)
+	}
+
+	s := corimSplitter{
+		orig:      u,
+		baseName:  strings.TrimSuffix(filepath.Base(corimFile), filepath.Ext(corimFile)),
+		outputDir: outputDir,
+		baseURI:   opts.baseURI,
+	}
+
+	if opts.maxSize != 0 {
+		if groups, err = s.splitTagsBySize(groups, opts.maxSize); err != nil {
+			return nil, fmt.Errorf("error splitting %s: %w", corimFile, err)
+		}
+	}
+
+	if err = fs.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating directory %s: %w", outputDir, err)
+	}
+
+	files := make([]string, 0, len(groups))
+
+	for i, tags := range groups {
+		p, err := s.part(i, len(groups), tags)
+		if err != nil {
+			return nil, err
+		}
+
+		f := s.fileName(i)
+		if err = saveUnsignedCorim(p, f); err != nil {
+			return nil, err
+		}
+
+		files = append(files, f)
+	}
+
+	// parts left over from an earlier split of the same CoRIM into more parts
+	// look like siblings, but are not linked to the new ones
+	for i := len(groups); ; i++ {
+		f := s.fileName(i)
+		if _, err := fs.Stat(f); err != nil {
+			break
+		}
+
+		fmt.Printf(
+			">> warning: %q is left over from a previous split and is not a sibling of the resulting CoRIMs\n",
+			f,
+		)
+	}
+
+	if meta != nil {
+		fmt.Printf(
+			">> warning: %q was signed, the resulting CoRIMs are unsigned and must be signed using corim sign\n",
+			corimFile,
+		)
+	}
+
+	return files, nil
+}
+
+// splitTagsByType groups the tags by type (CoMID, CoSWID or CoTS), in order
+// of first appearance of each type
+func splitTagsByType(tags []corim.Tag) ([][]corim.Tag, error) {
+	var (
+		groups [][]corim.Tag
+		index  = map[string]int{}
+	)
+
+	for i, t := range tags {
+		typ, _, err := splitCorimTag(t)
+		if err != nil {
+			return nil, fmt.Errorf("tag at index %d: %w", i, err)
+		}
+
+		j, ok := index[typ]
+		if !ok {
+			j = len(groups)
+			index[typ] = j
+			groups = append(groups, nil)
+		}
+
+		groups[j] = append(groups[j], t)
+	}
+
+	return groups, nil
+}
+
+// splitTagsByCount splits each group into groups of at most max tags
+func splitTagsByCount(groups [][]corim.Tag, max int) [][]corim.Tag {
+	var res [][]corim.Tag
+
+	for _, g := range groups {
+		for len(g) > max {
+			res = append(res, g[:max])
+			g = g[max:]
+		}
+		if len(g) != 0 {
+			res = append(res, g)
+		}
+	}
+
+	return res
+}
+
+// splitTagsBySize splits each group so that each part, once encoded with its
+// dependent-rims links, is at most max bytes.  Since the size of the links
+// depends on the number of parts, the split is repeated until the number of
+// parts does not change.
+func (o *corimSplitter) splitTagsBySize(groups [][]corim.Tag, max int) ([][]corim.Tag, error) {
+	n := len(groups)
+
+	for {
+		res, err := o.packTags(groups, n, max)
+		if err != nil {
+			return nil, err
+		}
+
+		// adding parts can only add links, so n grows until it settles
+		if len(res) <= n {
+			return res, nil
+		}
+
+		n = len(res)
+	}
+}
+
+// packTags fills each part with as many tags as fit in max bytes, assuming n
+// parts in total
+func (o *corimSplitter) packTags(groups [][]corim.Tag, n, max int) ([][]corim.Tag, error) {
+	var res [][]corim.Tag
+
+	for _, g := range groups {
+		var cur []corim.Tag
+
+		for _, t := range g {
+			size, err := o.partSize(len(res), n, append(cur[:len(cur):len(cur)], t))
+			if err != nil {
+				return nil, err
+			}
+
+			if size <= max {
+				cur = append(cur, t)
+				continue
+			}
+
+			if len(cur) == 0 {
+				return nil, fmt.Errorf("a tag of %d bytes does not fit in %d bytes", len(t), max)
+			}
+
+			res = append(res, cur)
+			cur = nil
+
+			if size, err = o.partSize(len(res), n, []corim.Tag{t}); err != nil {
+				return nil, err
+			}
+
+			if size > max {
+				return nil, fmt.Errorf("a tag of %d bytes does not fit in %d bytes", len(t), max)
+			}
+
+			cur = []corim.Tag{t}
+		}
+
+		if len(cur) != 0 {
+			res = append(res, cur)
+		}
+	}
+
+	return res, nil
+}
+
+// partSize returns the encoded size of the i-th of n parts, with the supplied
+// tags
+func (o *corimSplitter) partSize(i, n int, tags []corim.Tag) (int, error) {
+	// later parts are linked to as siblings, so make room for them
+	if i >= n {
+		n = i + 1
+	}
+
+	p, err := o.part(i, n, tags)
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := p.ToCBOR()
+	if err != nil {
+		return 0, fmt.Errorf("error encoding CoRIM to CBOR: %w", err)
+	}
+
+	return len(data), nil
+}
+
+func init() {
+	corimCmd.AddCommand(corimSplitCmd)
+}
@@ -0,0 +1,193 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/corim/corim"
+)
+
+// writeCorimSplitFixture adds to the signing fixtures a CoRIM with n distinct
+// CoMIDs followed by a CoSWID, saved to corim.cbor
+func writeCorimSplitFixture(t *testing.T, n int) {
+	var comids [][]byte
+	for i := 0; i < n; i++ {
+		comids = append(comids, []byte(strings.Replace(
+			string(testComidACMEWileE), "urn:example:acme:wile-e", fmt.Sprintf("urn:example:acme:%d", i), 1,
+		)))
+	}
+
+	writeSignFixtures(t)
+	require.NoError(t, fs.Mkdir("out", 0755))
+	require.NoError(t, afero.WriteFile(fs, "corim.cbor", makeDiffCorim(t, comids, [][]byte{testCoswidTemplate}), 0644))
+}
+
+// loadSplitCorims loads the split CoRIMs, and checks that each has a distinct
+// corim-id and links to all its siblings
+func loadSplitCorims(t *testing.T, files []string) []*corim.UnsignedCorim {
+	var (
+		corims []*corim.UnsignedCorim
+		ids    = map[string]bool{}
+	)
+
+	for _, f := range files {
+		u, _, err := loadCorim(f)
+		require.NoError(t, err)
+
+		assert.NotEqual(t, "5c57e8f4-46cd-421b-91c9-08cf93e13cfc", u.GetID())
+		assert.False(t, ids[u.GetID()])
+		ids[u.GetID()] = true
+
+		corims = append(corims, u)
+	}
+
+	for i, u := range corims {
+		require.NotNil(t, u.DependentRims)
+		require.Len(t, *u.DependentRims, len(corims)-1)
+
+		var hrefs []string
+		for _, l := range *u.DependentRims {
+			hrefs = append(hrefs, string(l.Href))
+		}
+
+		for j, sibling := range corims {
+			if i != j {
+				assert.Contains(t, hrefs, "urn:uuid:"+sibling.GetID())
+			}
+		}
+	}
+
+	return corims
+}
+
+func Test_CorimSplitCmd_bad_args(t *testing.T) {
+	cmd := NewCorimSplitCmd()
+	cmd.SetArgs([]string{"--by-type"})
+	assert.EqualError(t, cmd.Execute(), "no CoRIM supplied")
+
+	cmd = NewCorimSplitCmd()
+	cmd.SetArgs([]string{"--file=corim.cbor"})
+	assert.EqualError(t, cmd.Execute(), "no split criteria supplied: use --by-type, --max-tags and/or --max-size")
+
+	cmd = NewCorimSplitCmd()
+	cmd.SetArgs([]string{"--file=corim.cbor", "--max-size=-1"})
+	assert.EqualError(t, cmd.Execute(), "--max-tags and --max-size must not be negative")
+}
+
+func Test_CorimSplitCmd_by_type_and_count(t *testing.T) {
+	writeCorimSplitFixture(t, 5)
+
+	cmd := NewCorimSplitCmd()
+	cmd.SetArgs([]string{"--file=corim.cbor", "--by-type", "--max-tags=2", "--output-dir=out"})
+	require.NoError(t, cmd.Execute())
+
+	files := []string{"out/corim-000.cbor", "out/corim-001.cbor", "out/corim-002.cbor", "out/corim-003.cbor"}
+
+	_, err := fs.Stat("out/corim-004.cbor")
+	assert.Error(t, err)
+
+	corims := loadSplitCorims(t, files)
+	assert.Len(t, corims[0].Tags, 2)
+	assert.Len(t, corims[1].Tags, 2)
+	assert.Len(t, corims[2].Tags, 1)
+	require.Len(t, corims[3].Tags, 1)
+
+	tag, err := decodeCorimTag(corims[3].Tags[0])
+	require.NoError(t, err)
+	assert.Equal(t, "coswid", tag.typ)
+}
+
+func Test_CorimSplit_by_size(t *testing.T) {
+	writeCorimSplitFixture(t, 20)
+
+	const maxSize = 1024
+
+	files, err := split("corim.cbor", "out", splitOptions{maxSize: maxSize})
+	require.NoError(t, err)
+	require.True(t, len(files) > 1)
+
+	var n int
+	for _, u := range loadSplitCorims(t, files) {
+		n += len(u.Tags)
+	}
+	assert.Equal(t, 21, n)
+
+	for _, f := range files {
+		data, err := afero.ReadFile(fs, f)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(data), maxSize)
+	}
+}
+
+func Test_CorimSplit_tag_too_big(t *testing.T) {
+	writeCorimSplitFixture(t, 1)
+
+	_, err := split("corim.cbor", "out", splitOptions{maxSize: 100})
+	assert.ErrorContains(t, err, "error splitting corim.cbor: a tag of ")
+}
+
+func Test_CorimSplit_base_uri(t *testing.T) {
+	writeCorimSplitFixture(t, 1)
+
+	files, err := split("corim.cbor", "out", splitOptions{byType: true, baseURI: "https://example.com/corims/"})
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	u, _, err := loadCorim(files[0])
+	require.NoError(t, err)
+	require.NotNil(t, u.DependentRims)
+	require.Len(t, *u.DependentRims, 1)
+	assert.Equal(t, "https://example.com/corims/corim-001.cbor", string((*u.DependentRims)[0].Href))
+}
+
+func Test_CorimSplit_malformed_tag(t *testing.T) {
+	u := corim.NewUnsignedCorim().SetID("5c57e8f4-46cd-421b-91c9-08cf93e13cfc")
+	require.NotNil(t, u)
+	u.Tags = append(u.Tags, corim.Tag{0xd9, 0x01})
+
+	data, err := u.ToCBOR()
+	require.NoError(t, err)
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "corim.cbor", data, 0644))
+
+	_, err = split("corim.cbor", "out", splitOptions{byType: true})
+	assert.EqualError(t, err, "error splitting corim.cbor: tag at index 0: malformed tag")
+}
+
+func Test_CorimSplit_creates_output_dir(t *testing.T) {
+	writeCorimSplitFixture(t, 1)
+
+	files, err := split("corim.cbor", "new/out", splitOptions{byType: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new/out/corim-000.cbor", "new/out/corim-001.cbor"}, files)
+
+	loadSplitCorims(t, files)
+}
+
+func Test_CorimSplit_rerun_with_fewer_parts(t *testing.T) {
+	writeCorimSplitFixture(t, 1)
+
+	_, err := split("corim.cbor", "out", splitOptions{byType: true})
+	require.NoError(t, err)
+
+	// the stale second part is reported but left in place
+	files, err := split("corim.cbor", "out", splitOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"out/corim-000.cbor"}, files)
+
+	_, err = fs.Stat("out/corim-001.cbor")
+	assert.NoError(t, err)
+
+	u, _, err := loadCorim(files[0])
+	require.NoError(t, err)
+	assert.Len(t, u.Tags, 2)
+	assert.Nil(t, u.DependentRims)
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var (
+	corimTimestampCorimFile  *string
+	corimTimestampTsaURL     *string
+	corimTimestampOutputFile *string
+)
+
+var corimTimestampCmd = NewCorimTimestampCmd()
+
+func NewCorimTimestampCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "timestamp",
+		Short: "add RFC 3161 timestamp tokens to a signed CoRIM",
+		Long: `add RFC 3161 timestamp tokens to a signed CoRIM
+
+    Obtain from the TSA at the supplied URL a timestamp token over each
+    signature of the signed CoRIM in signed-corim.cbor, and embed it in the
+    unprotected header of the signature.  Any existing token is replaced.  The
+    file is updated in place.
+
+      cocli corim timestamp --file=signed-corim.cbor \
+                        --tsa-url=http://timestamp.example.com/tsa
+
+    Save the result to a different file:
+
+      cocli corim timestamp --file=signed-corim.cbor \
+                        --tsa-url=http://timestamp.example.com/tsa \
+                        --output=timestamped-corim.cbor
+
+    Detached signatures can be timestamped without their payload, since the
+    token only covers the signature.
+    `,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkCorimTimestampArgs(); err != nil {
+				return err
+			}
+
+			outputFile := *corimTimestampOutputFile
+			if outputFile == "" {
+				outputFile = *corimTimestampCorimFile
+			}
+
+			if err := timestampCorimFile(*corimTimestampCorimFile, *corimTimestampTsaURL, outputFile); err != nil {
+				return err
+			}
+
+			fmt.Printf(">> %q timestamped and saved to %q\n", *corimTimestampCorimFile, outputFile)
+
+			return nil
+		},
+	}
+
+	corimTimestampCorimFile = cmd.Flags().StringP("file", "f", "", "a signed CoRIM file (in CBOR format)")
+	corimTimestampTsaURL = cmd.Flags().String("tsa-url", "", "URL of an RFC 3161 Time Stamping Authority")
+	corimTimestampOutputFile = cmd.Flags().StringP(
+		"output", "o", "", "name of the timestamped CoRIM file (default: the input file)",
+	)
+
+	return cmd
+}
+
+func checkCorimTimestampArgs() error {
+	if corimTimestampCorimFile == nil || *corimTimestampCorimFile == "" {
+		return errors.New("no CoRIM supplied")
+	}
+
+	if corimTimestampTsaURL == nil || *corimTimestampTsaURL == "" {
+		return errors.New("no TSA URL supplied")
+	}
+
+	return nil
+}
+
+func timestampCorimFile(signedCorimFile, tsaURL, outputFile string) error {
+	signedCorimCBOR, err := afero.ReadFile(fs, signedCorimFile)
+	if err != nil {
+		return fmt.Errorf("error loading signed CoRIM from %s: %w", signedCorimFile, err)
+	}
+
+	if signedCorimCBOR, err = timestampCorim(signedCorimCBOR, tsaURL); err != nil {
+		return fmt.Errorf("error timestamping %s with TSA at %s: %w", signedCorimFile, tsaURL, err)
+	}
+
+	if err = afero.WriteFile(fs, outputFile, signedCorimCBOR, 0644); err != nil {
+		return fmt.Errorf("error saving signed CoRIM to file %s: %w", outputFile, err)
+	}
+
+	return nil
+}
+
+func init() {
+	corimCmd.AddCommand(corimTimestampCmd)
+}
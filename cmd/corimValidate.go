@@ -0,0 +1,137 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/corim/corim"
+	"github.com/veraison/corim/cots"
+	"github.com/veraison/swid"
+)
+
+var (
+	corimValidateCorimFile *string
+)
+
+var corimValidateCmd = NewCorimValidateCmd()
+
+func NewCorimValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "validate a signed or unsigned CoRIM, including its embedded tags",
+		Long: `validate a signed or unsigned CoRIM, including its embedded tags
+
+	Validate the CoRIM in file corim.cbor.  The input can be either a signed
+	(COSE Sign1 or COSE Sign) or an unsigned CoRIM.  The CoRIM (and its Meta, if signed) is
+	validated, and then each embedded CoMID, CoSWID and CoTS is decoded and
+	validated in turn.  A report line is printed for each tag.
+
+	  cocli corim validate --file=corim.cbor
+	`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkCorimValidateArgs(); err != nil {
+				return err
+			}
+
+			return validateCorim(*corimValidateCorimFile)
+		},
+	}
+
+	corimValidateCorimFile = cmd.Flags().StringP("file", "f", "", "a CoRIM file (in CBOR format)")
+
+	return cmd
+}
+
+func checkCorimValidateArgs() error {
+	if corimValidateCorimFile == nil || *corimValidateCorimFile == "" {
+		return errors.New("no CoRIM supplied")
+	}
+
+	return nil
+}
+
+func validateCorim(corimFile string) error {
+	// signed (COSE Sign1 or COSE Sign) or unsigned CoRIM
+	u, m, err := loadCorim(corimFile)
+	if err != nil {
+		return err
+	}
+
+	// the per-tag report is produced even if the CoRIM or its Meta are invalid,
+	// and all failures are returned together
+	var errs []error
+
+	if m != nil {
+		if err = m.Valid(); err != nil {
+			errs = append(errs, fmt.Errorf("error validating CoRIM Meta from %s: %w", corimFile, err))
+		}
+		fmt.Printf(">> [signed] %q\n", corimFile)
+	} else {
+		fmt.Printf(">> [unsigned] %q\n", corimFile)
+	}
+
+	if err = u.Valid(); err != nil {
+		errs = append(errs, fmt.Errorf("error validating CoRIM from %s: %w", corimFile, err))
+	}
+
+	failed := 0
+	for i, t := range u.Tags {
+		typ, err := validateTag(t)
+		if err != nil {
+			fmt.Printf("[invalid] tag %d (%s): %v\n", i, typ, err)
+			failed++
+			continue
+		}
+		fmt.Printf("[valid] tag %d (%s)\n", i, typ)
+	}
+
+	if failed != 0 {
+		errs = append(errs, fmt.Errorf("%d/%d tag validation(s) failed", failed, len(u.Tags)))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateTag decodes and validates the supplied embedded tag, returning a
+// human readable description of its type alongside any validation error
+func validateTag(t corim.Tag) (string, error) {
+	typ, cborData, err := splitCorimTag(t)
+	if err != nil {
+		return "unknown", err
+	}
+
+	name := corimTagNames[typ]
+
+	switch typ {
+	case "comid":
+		var c comid.Comid
+		if err = c.FromCBOR(cborData); err != nil {
+			return name, fmt.Errorf("decoding failed: %w", err)
+		}
+		err = c.Valid()
+	case "coswid":
+		var s swid.SoftwareIdentity
+		if err = s.FromCBOR(cborData); err != nil {
+			return name, fmt.Errorf("decoding failed: %w", err)
+		}
+		err = validCoswid(&s)
+	case "cots":
+		var c cots.ConciseTaStore
+		if err = c.FromCBOR(cborData); err != nil {
+			return name, fmt.Errorf("decoding failed: %w", err)
+		}
+		err = validCots(&c)
+	}
+
+	return name, err
+}
+
+func init() {
+	corimCmd.AddCommand(corimValidateCmd)
+}
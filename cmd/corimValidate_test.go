@@ -0,0 +1,193 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/corim/corim"
+)
+
+func Test_CorimValidateCmd_unknown_argument(t *testing.T) {
+	cmd := NewCorimValidateCmd()
+
+	args := []string{"--unknown-argument=val"}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "unknown flag: --unknown-argument")
+}
+
+func Test_CorimValidateCmd_mandatory_args_missing_corim_file(t *testing.T) {
+	cmd := NewCorimValidateCmd()
+
+	// no args
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "no CoRIM supplied")
+}
+
+func Test_CorimValidateCmd_non_existent_corim_file(t *testing.T) {
+	cmd := NewCorimValidateCmd()
+
+	args := []string{
+		"--file=nonexistent.cbor",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "error loading CoRIM from nonexistent.cbor: open nonexistent.cbor: file does not exist")
+}
+
+func Test_CorimValidateCmd_bad_corim(t *testing.T) {
+	cmd := NewCorimValidateCmd()
+
+	args := []string{
+		"--file=bad.txt",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "bad.txt", []byte("hello!"), 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, "error decoding CoRIM (signed or unsigned) from bad.txt: expected map (CBOR Major Type 5), found Major Type 3")
+}
+
+func Test_CorimValidateCmd_invalid_unsigned_corim(t *testing.T) {
+	cmd := NewCorimValidateCmd()
+
+	args := []string{
+		"--file=invalid.cbor",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "invalid.cbor", testCorimInvalid, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, `error decoding CoRIM (signed or unsigned) from invalid.cbor: missing mandatory field "Tags" (1)`)
+}
+
+func Test_CorimValidateCmd_unsigned_corim_with_invalid_tag(t *testing.T) {
+	cmd := NewCorimValidateCmd()
+
+	args := []string{
+		"--file=unsigned.cbor",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "unsigned.cbor", testCorimValid, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, "1/1 tag validation(s) failed")
+}
+
+func Test_CorimValidateCmd_invalid_corim_still_validates_tags(t *testing.T) {
+	var u corim.UnsignedCorim
+	require.NoError(t, u.FromCBOR(makeDiffCorim(t, [][]byte{testComidACMEWileE}, nil)))
+	u.Tags = append(u.Tags, corim.Tag{0xd9, 0x01})
+
+	// not-after precedes not-before
+	notBefore := time.Now()
+	u.RimValidity = &corim.Validity{NotBefore: &notBefore, NotAfter: notBefore.Add(-time.Hour)}
+
+	data, err := u.ToCBOR()
+	require.NoError(t, err)
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "invalid.cbor", data, 0644))
+
+	cmd := NewCorimValidateCmd()
+	cmd.SetArgs([]string{"--file=invalid.cbor"})
+
+	err = cmd.Execute()
+	assert.ErrorContains(t, err, "error validating CoRIM from invalid.cbor: RIM validity validation failed")
+	assert.ErrorContains(t, err, "1/2 tag validation(s) failed")
+}
+
+func Test_CorimValidateCmd_unsigned_corim_ok(t *testing.T) {
+	cmd := NewCorimValidateCmd()
+
+	args := []string{
+		"--file=unsigned.cbor",
+	}
+	cmd.SetArgs(args)
+
+	var s corim.SignedCorim
+	err := s.FromCOSE(testSignedCorimValidWithCots)
+	require.NoError(t, err)
+
+	data, err := s.UnsignedCorim.ToCBOR()
+	require.NoError(t, err)
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "unsigned.cbor", data, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+}
+
+func Test_CorimValidateCmd_signed_corim_ok(t *testing.T) {
+	cmd := NewCorimValidateCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "ok.cbor", testSignedCorimValidWithCots, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+}
+
+func Test_CorimValidateCmd_multi_signed_corim_ok(t *testing.T) {
+	writeMultiSignedFixtures(t, makeTestPKI(t), makeTestPKI(t))
+
+	cmd := NewCorimValidateCmd()
+	cmd.SetArgs([]string{"--file=signed.cbor"})
+
+	err := cmd.Execute()
+	assert.NoError(t, err)
+}
+
+func Test_splitCorimTag(t *testing.T) {
+	tvs := []struct {
+		tag  corim.Tag
+		typ  string
+		data []byte
+		err  string
+	}{
+		{tag: corim.Tag{0xd9, 0x01, 0xfa, 0xa0}, typ: "comid", data: []byte{0xa0}},
+		{tag: corim.Tag{0xd9, 0x01, 0xf9, 0xa0}, typ: "coswid", data: []byte{0xa0}},
+		{tag: corim.Tag{0xd9, 0x01, 0xfb, 0xa0}, typ: "cots", data: []byte{0xa0}},
+		{tag: corim.Tag{0xd9, 0x01, 0xfa}, err: "malformed tag"},
+		{tag: corim.Tag{0xd9, 0x01, 0xf8, 0xa0}, err: "unmatched CBOR tag d901f8"},
+	}
+
+	for _, tv := range tvs {
+		typ, data, err := splitCorimTag(tv.tag)
+		if tv.err != "" {
+			assert.EqualError(t, err, tv.err)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tv.typ, typ)
+		assert.Equal(t, tv.data, data)
+	}
+}
@@ -4,18 +4,43 @@
 package cmd
 
 import (
+	"bytes"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
+	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"github.com/veraison/corim/comid"
 	"github.com/veraison/corim/corim"
+	"github.com/veraison/corim/cots"
+	"github.com/veraison/swid"
 )
 
+// the CoTS purpose that identifies trust anchors usable for CoRIM verification
+const cotsPurposeCorim = "corim"
+
 var (
-	corimVerifyCorimFile *string
-	corimVerifyKeyFile   *string
+	corimVerifyCorimFile   *string
+	corimVerifyKeyFiles    *[]string
+	corimVerifyJwksFile    *string
+	corimVerifyTaCerts     []string
+	corimVerifyTaCertDirs  []string
+	corimVerifyTsaCerts    []string
+	corimVerifyTsaCertDirs []string
+	corimVerifyCotsFile    *string
+	corimVerifyPayload     *string
+	corimVerifyAt          *string
+	corimVerifySkipValid   *bool
+	corimVerifyPolicy      *string
 )
 
 var corimVerifyCmd = NewCorimVerifyCmd()
@@ -23,13 +48,73 @@ var corimVerifyCmd = NewCorimVerifyCmd()
 func NewCorimVerifyCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "verify",
-		Short: "verify a signed CoRIM using the supplied key",
-		Long: `verify a signed CoRIM using the supplied key
+		Short: "verify a signed CoRIM using the supplied key or trust anchors",
+		Long: `verify a signed CoRIM using the supplied key or trust anchors
 
 	Verify the signed CoRIM signed-corim.cbor using the key in JWK format from
 	file key.jwk
 	
 	  cocli corim verify --file=signed-corim.cbor --key=key.jwk
+
+	The key can also be a PEM or DER-encoded public key, private key or X.509
+	certificate.
+
+	  cocli corim verify --file=signed-corim.cbor --key=signing-cert.pem
+
+	Verify the signed CoRIM signed-corim.cbor using the key from the JWK Set in
+	keys.json whose "kid" matches the kid header of the CoRIM.  If the CoRIM
+	has no kid, each key in the set is tried in turn.  The identifier of the
+	key that verified the CoRIM is printed.
+
+	  cocli corim verify --file=signed-corim.cbor --jwks=keys.json
+
+	Verify the signed CoRIM signed-corim.cbor using the certificate chain
+	embedded in its x5chain header.  The chain must lead to one of the trust
+	anchor certificates in root.der or in the tas/ directory (DER or PEM).
+	The signature is then checked using the signing certificate's public key.
+
+	  cocli corim verify --file=signed-corim.cbor --ta-cert=root.der --ta-cert-dir=tas
+
+	Verify the signed CoRIM signed-corim.cbor using the trust anchors in the
+	CoTS file store.cbor.  Only the trust anchors from a store that lists the
+	"corim" purpose, and whose environments match the environments described
+	by the CoRIM, are used.  The CAs in the store can be used as intermediates.
+
+	  cocli corim verify --file=signed-corim.cbor --cots=store.cbor
+
+	Verify the detached signature in signature.cbor over the unsigned CoRIM in
+	payload.cbor (as produced by corim sign --detached).  Any of the above
+	verification methods can be used.
+
+	  cocli corim verify --file=signature.cbor --payload=payload.cbor \
+	                     --key=key.jwk
+
+	Verify the multi-signed CoRIM signed-corim.cbor (a COSE Sign, as produced
+	by corim sign with more than one key, or by corim cosign).  The outcome is
+	reported for each signature, identified by its kid or by the subject of its
+	signing certificate.  By default, all signatures must verify; use --policy
+	to require only one ("any") or a given number of them.  --key can be
+	repeated, in which case each signature is checked against all the keys.
+
+	  cocli corim verify --file=signed-corim.cbor \
+	                     --key=vendor.jwk --key=oem.jwk --policy=1
+
+	Verify the RFC 3161 timestamp token carried by each signature against the
+	TSA root certificates in tsa-root.der or in the tsa/ directory, before
+	verifying the signature itself.  Certificate paths from the x5chain are
+	then validated at the time of the timestamp rather than the current time,
+	so that the CoRIM can still be verified after its signing certificate has
+	expired.
+
+	  cocli corim verify --file=signed-corim.cbor --ta-cert=root.der \
+	                     --tsa-cert=tsa-root.der --tsa-cert-dir=tsa
+
+	Unless the --skip-validity-check switch is given, the validity period in
+	the CoRIM Meta is checked against the current time or, if supplied, the
+	instant given via --at (in RFC 3339 format).
+
+	  cocli corim verify --file=signed-corim.cbor --key=key.jwk \
+	                     --at=2024-06-01T00:00:00Z
 	`,
 
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -37,11 +122,95 @@ func NewCorimVerifyCmd() *cobra.Command {
 				return err
 			}
 
-			// checkCorimVerifyArgs makes sure corimVerifyCorimFile is not nil
-			err := verify(*corimVerifyCorimFile, *corimVerifyKeyFile)
+			var (
+				at  = time.Now()
+				err error
+				vc  *verifiableCorim
+			)
+
+			// checkCorimVerifyArgs makes sure the flag variables are not nil
+			if *corimVerifyAt != "" {
+				if at, err = time.Parse(time.RFC3339, *corimVerifyAt); err != nil {
+					return fmt.Errorf("invalid --at time: %w", err)
+				}
+			}
+
+			useTas := len(*corimVerifyKeyFiles) == 0 && *corimVerifyJwksFile == "" && *corimVerifyCotsFile == ""
+
+			var taFilesList []string
+			if useTas {
+				taFilesList = certFilesList(corimVerifyTaCerts, corimVerifyTaCertDirs)
+				if len(taFilesList) == 0 {
+					return errors.New("no trust anchor files found")
+				}
+			}
+
+			var tsaRoots *x509.CertPool
+			if len(corimVerifyTsaCerts)+len(corimVerifyTsaCertDirs) != 0 {
+				tsaFilesList := certFilesList(corimVerifyTsaCerts, corimVerifyTsaCertDirs)
+				if len(tsaFilesList) == 0 {
+					return errors.New("no TSA certificate files found")
+				}
+
+				if tsaRoots, err = loadCertPool(tsaFilesList); err != nil {
+					return fmt.Errorf("error loading TSA certificates: %w", err)
+				}
+			}
+
+			if vc, err = loadSignedCorim(*corimVerifyCorimFile, *corimVerifyPayload); err != nil {
+				return err
+			}
+
+			verifyOne := func(sig *corimSignature) (string, error) {
+				if tsaRoots != nil {
+					if err := verifyTimestamp(sig, *corimVerifyCorimFile, tsaRoots); err != nil {
+						return "", err
+					}
+				}
+
+				switch {
+				case len(*corimVerifyKeyFiles) != 0:
+					return verifyWithKeys(sig, *corimVerifyCorimFile, *corimVerifyKeyFiles)
+				case *corimVerifyJwksFile != "":
+					return verifyWithJWKS(sig, *corimVerifyCorimFile, *corimVerifyJwksFile)
+				case *corimVerifyCotsFile != "":
+					return "", verifyWithCots(sig, &vc.UnsignedCorim, *corimVerifyCorimFile, *corimVerifyCotsFile)
+				default:
+					return "", verifyWithTrustAnchors(sig, *corimVerifyCorimFile, taFilesList)
+				}
+			}
+
+			required, err := requiredSignatures(*corimVerifyPolicy, len(vc.Signatures))
 			if err != nil {
 				return err
 			}
+
+			if required > len(vc.Signatures) {
+				return fmt.Errorf("error verifying %s: policy %q requires %d signatures, found %d",
+					*corimVerifyCorimFile, *corimVerifyPolicy, required, len(vc.Signatures))
+			}
+
+			if vc.MultiSigned {
+				err = verifySignatures(vc.Signatures, *corimVerifyCorimFile, required, verifyOne)
+			} else {
+				var key string
+				if key, err = verifyOne(vc.Signatures[0]); err == nil && *corimVerifyJwksFile != "" {
+					fmt.Printf(">> %q verified with key %q from %q\n", *corimVerifyCorimFile, key, *corimVerifyJwksFile)
+				}
+				if ts := vc.Signatures[0].timestamp; err == nil && ts != nil {
+					fmt.Printf(">> %q timestamped at %s by %q\n",
+						*corimVerifyCorimFile, ts.genTime.Format(time.RFC3339), ts.tsaCert.Subject.String())
+				}
+			}
+			if err != nil {
+				return err
+			}
+
+			if *corimVerifySkipValid {
+				fmt.Printf(">> validity period check skipped for %q\n", *corimVerifyCorimFile)
+			} else if err = checkValidity(vc.Meta.Validity, at); err != nil {
+				return fmt.Errorf("error verifying %s: %w", *corimVerifyCorimFile, err)
+			}
 			fmt.Printf(">> %q verified\n", *corimVerifyCorimFile)
 
 			return nil
@@ -49,7 +218,40 @@ func NewCorimVerifyCmd() *cobra.Command {
 	}
 
 	corimVerifyCorimFile = cmd.Flags().StringP("file", "f", "", "a signed CoRIM file (in CBOR format)")
-	corimVerifyKeyFile = cmd.Flags().StringP("key", "k", "", "verification key in JWK format")
+	corimVerifyKeyFiles = cmd.Flags().StringArrayP(
+		"key", "k", nil,
+		"verification key (JWK, PEM/DER public or private key, or X.509 certificate), can be repeated",
+	)
+	corimVerifyJwksFile = cmd.Flags().String("jwks", "", "a JWK Set file, from which the verification key is selected by kid")
+	corimVerifyCotsFile = cmd.Flags().String("cots", "", "a CoTS file (in CBOR format) to be used as trust anchor store")
+	corimVerifyPayload = cmd.Flags().String(
+		"payload", "", "the unsigned CoRIM file (in CBOR format) for a detached signature",
+	)
+	corimVerifyAt = cmd.Flags().String(
+		"at", "", "time (in RFC 3339 format) at which the validity period is checked (default: now)",
+	)
+	corimVerifySkipValid = cmd.Flags().Bool(
+		"skip-validity-check", false, "do not check the validity period of the CoRIM",
+	)
+	corimVerifyPolicy = cmd.Flags().String(
+		"policy", "all", `how many signatures of a multi-signed CoRIM must verify: "all", "any" or a number`,
+	)
+
+	cmd.Flags().StringArrayVar(
+		&corimVerifyTaCerts, "ta-cert", []string{}, "a trust anchor certificate file (in DER or PEM format)",
+	)
+
+	cmd.Flags().StringArrayVar(
+		&corimVerifyTaCertDirs, "ta-cert-dir", []string{}, "a directory containing trust anchor certificate files",
+	)
+
+	cmd.Flags().StringArrayVar(
+		&corimVerifyTsaCerts, "tsa-cert", []string{}, "a TSA root certificate file (in DER or PEM format)",
+	)
+
+	cmd.Flags().StringArrayVar(
+		&corimVerifyTsaCertDirs, "tsa-cert-dir", []string{}, "a directory containing TSA root certificate files",
+	)
 
 	return cmd
 }
@@ -59,45 +261,662 @@ func checkCorimVerifyArgs() error {
 		return errors.New("no CoRIM supplied")
 	}
 
-	if corimVerifyKeyFile == nil || *corimVerifyKeyFile == "" {
-		return errors.New("no key supplied")
+	hasKey := corimVerifyKeyFiles != nil && len(*corimVerifyKeyFiles) != 0
+	hasJwks := corimVerifyJwksFile != nil && *corimVerifyJwksFile != ""
+	hasTas := len(corimVerifyTaCerts)+len(corimVerifyTaCertDirs) != 0
+	hasCots := corimVerifyCotsFile != nil && *corimVerifyCotsFile != ""
+
+	n := 0
+	for _, has := range []bool{hasKey, hasJwks, hasTas, hasCots} {
+		if has {
+			n++
+		}
+	}
+
+	if n == 0 {
+		return errors.New("no key or trust anchors supplied")
+	}
+
+	if n > 1 {
+		return errors.New("only one of --key, --jwks, --ta-cert/--ta-cert-dir and --cots can be used at the same time")
+	}
+
+	if corimVerifyAt != nil && *corimVerifyAt != "" && corimVerifySkipValid != nil && *corimVerifySkipValid {
+		return errors.New("--at and --skip-validity-check cannot be used at the same time")
+	}
+
+	if corimVerifyPolicy != nil {
+		if _, err := requiredSignatures(*corimVerifyPolicy, 1); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func verify(signedCorimFile, keyFile string) error {
+// requiredSignatures returns how many out of n signatures must verify
+// according to the supplied policy
+func requiredSignatures(policy string, n int) (int, error) {
+	switch policy {
+	case "all":
+		return n, nil
+	case "any":
+		return 1, nil
+	}
+
+	required, err := strconv.Atoi(policy)
+	if err != nil || required < 1 {
+		return 0, fmt.Errorf(`invalid --policy %q: expecting "all", "any" or a positive number`, policy)
+	}
+
+	return required, nil
+}
+
+// verifySignatures verifies each of the signatures of a multi-signed CoRIM,
+// reporting the outcome for each of them, and then checks that at least
+// required of them verified
+func verifySignatures(
+	sigs []*corimSignature, signedCorimFile string, required int,
+	verifyOne func(*corimSignature) (string, error),
+) error {
+	verified := 0
+
+	for i, sig := range sigs {
+		key, err := verifyOne(sig)
+		switch {
+		case err != nil:
+			fmt.Printf(">> signature #%d (%s) failed: %v\n", i, sig, err)
+		case key != "":
+			fmt.Printf(">> signature #%d (%s) verified with key %q\n", i, sig, key)
+			verified++
+		default:
+			fmt.Printf(">> signature #%d (%s) verified\n", i, sig)
+			verified++
+		}
+
+		if ts := sig.timestamp; err == nil && ts != nil {
+			fmt.Printf(">> signature #%d (%s) timestamped at %s by %q\n",
+				i, sig, ts.genTime.Format(time.RFC3339), ts.tsaCert.Subject.String())
+		}
+	}
+
+	if verified < required {
+		return fmt.Errorf("error verifying %s: %d of %d signatures verified, %d required",
+			signedCorimFile, verified, len(sigs), required)
+	}
+
+	fmt.Printf(">> %d of %d signatures verified\n", verified, len(sigs))
+
+	return nil
+}
+
+// verifiableCorim is a signed CoRIM, either single-signed (COSE Sign1) or
+// multi-signed (COSE Sign), along with its signatures
+type verifiableCorim struct {
+	UnsignedCorim corim.UnsignedCorim
+	Meta          corim.Meta
+	Signatures    []*corimSignature
+	MultiSigned   bool
+}
+
+// loadSignedCorim loads the signed CoRIM from signedCorimFile.  If payloadFile
+// is supplied, the signature is expected to be detached and the payload is
+// taken from payloadFile.
+func loadSignedCorim(signedCorimFile, payloadFile string) (*verifiableCorim, error) {
 	var (
 		signedCorimCBOR []byte
-		keyJWK          []byte
 		err             error
-		pkey            crypto.PublicKey
-		s               corim.SignedCorim
 	)
 
 	if signedCorimCBOR, err = afero.ReadFile(fs, signedCorimFile); err != nil {
-		return fmt.Errorf("error loading signed CoRIM from %s: %w", signedCorimFile, err)
+		return nil, fmt.Errorf("error loading signed CoRIM from %s: %w", signedCorimFile, err)
 	}
 
+	if payloadFile != "" {
+		payload, err := afero.ReadFile(fs, payloadFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading payload from %s: %w", payloadFile, err)
+		}
+
+		if signedCorimCBOR, err = attachPayload(signedCorimCBOR, payload); err != nil {
+			return nil, fmt.Errorf("error attaching payload from %s to %s: %w", payloadFile, signedCorimFile, err)
+		}
+	}
+
+	if isMultiSigned(signedCorimCBOR) {
+		return loadMultiSignedCorim(signedCorimCBOR, signedCorimFile)
+	}
+
+	var s corim.SignedCorim
+
 	if err = s.FromCOSE(signedCorimCBOR); err != nil {
-		return fmt.Errorf("error decoding signed CoRIM from %s: %w", signedCorimFile, err)
+		if msg, decErr := decodeSign1(signedCorimCBOR); decErr == nil && msg.Payload == nil {
+			return nil, fmt.Errorf("%s has a detached payload: use --payload", signedCorimFile)
+		}
+		return nil, fmt.Errorf("error decoding signed CoRIM from %s: %w", signedCorimFile, err)
+	}
+
+	// FromCOSE has already decoded the envelope successfully
+	msg, _ := decodeSign1(signedCorimCBOR)
+
+	return &verifiableCorim{
+		UnsignedCorim: s.UnsignedCorim,
+		Meta:          s.Meta,
+		Signatures: []*corimSignature{{
+			kid:               keyID(msg.Headers),
+			signingCert:       s.SigningCert,
+			intermediateCerts: s.IntermediateCerts,
+			signature:         msg.Signature,
+			timestampToken:    timestampTokenFromHeaders(msg.Headers),
+			verify:            s.Verify,
+		}},
+	}, nil
+}
+
+func loadMultiSignedCorim(signedCorimCBOR []byte, signedCorimFile string) (*verifiableCorim, error) {
+	var ms multiSignedCorim
+
+	if err := ms.FromCOSE(signedCorimCBOR); err != nil {
+		if errors.Is(err, errDetachedPayload) {
+			return nil, fmt.Errorf("%s has a detached payload: use --payload", signedCorimFile)
+		}
+		return nil, fmt.Errorf("error decoding signed CoRIM from %s: %w", signedCorimFile, err)
+	}
+
+	sigs, err := ms.Signatures()
+	if err != nil {
+		return nil, fmt.Errorf("error decoding signed CoRIM from %s: %w", signedCorimFile, err)
+	}
+
+	return &verifiableCorim{
+		UnsignedCorim: ms.UnsignedCorim,
+		Meta:          ms.Meta,
+		Signatures:    sigs,
+		MultiSigned:   true,
+	}, nil
+}
+
+// verifyTimestamp verifies the timestamp token of the signature against the
+// supplied TSA roots and, on success, records the time of the timestamp in the
+// signature
+func verifyTimestamp(sig *corimSignature, signedCorimFile string, tsaRoots *x509.CertPool) error {
+	if sig.timestampToken == nil {
+		return fmt.Errorf("error verifying %s: no timestamp token", signedCorimFile)
+	}
+
+	tst, err := parseTimestampToken(sig.timestampToken)
+	if err != nil {
+		return fmt.Errorf("error verifying timestamp of %s: %w", signedCorimFile, err)
+	}
+
+	if sig.timestamp, err = tst.verify(sig.signature, tsaRoots); err != nil {
+		return fmt.Errorf("error verifying timestamp of %s: %w", signedCorimFile, err)
+	}
+
+	return nil
+}
+
+// verifyWithKeys verifies the signature using the key from each of the
+// supplied files in turn, and returns the name of the file with the key that
+// verified it
+func verifyWithKeys(sig *corimSignature, signedCorimFile string, keyFiles []string) (string, error) {
+	if len(keyFiles) == 1 {
+		return keyFiles[0], verify(sig, signedCorimFile, keyFiles[0])
 	}
 
-	if keyJWK, err = afero.ReadFile(fs, keyFile); err != nil {
-		return fmt.Errorf("error loading verifying key from %s: %w", keyFile, err)
+	for _, keyFile := range keyFiles {
+		pkey, err := loadVerifyingKey(keyFile)
+		if err != nil {
+			return "", err
+		}
+
+		if sig.verify(pkey) == nil {
+			return keyFile, nil
+		}
 	}
 
-	if pkey, err = corim.NewPublicKeyFromJWK(keyJWK); err != nil {
-		return fmt.Errorf("error loading verifying key from %s: %w", keyFile, err)
+	return "", fmt.Errorf("error verifying %s: none of the supplied keys verifies the signature", signedCorimFile)
+}
+
+func verify(sig *corimSignature, signedCorimFile, keyFile string) error {
+	pkey, err := loadVerifyingKey(keyFile)
+	if err != nil {
+		return err
 	}
 
-	if err = s.Verify(pkey); err != nil {
+	if err = sig.verify(pkey); err != nil {
 		return fmt.Errorf("error verifying %s with key %s: %w", signedCorimFile, keyFile, err)
 	}
 
 	return nil
 }
 
+func loadVerifyingKey(keyFile string) (crypto.PublicKey, error) {
+	var (
+		keyData []byte
+		err     error
+		pkey    crypto.PublicKey
+	)
+
+	if keyData, err = afero.ReadFile(fs, keyFile); err != nil {
+		return nil, fmt.Errorf("error loading verifying key from %s: %w", keyFile, err)
+	}
+
+	if pkey, err = newPublicKeyFromKeyData(keyData); err != nil {
+		return nil, fmt.Errorf("error loading verifying key from %s: %w", keyFile, err)
+	}
+
+	return pkey, nil
+}
+
+// verifyWithJWKS verifies the signature using the key from the JWK Set whose
+// "kid" matches the signature's kid header or, if there is no kid, using the
+// first key in the set that verifies it.  It returns the identifier of the key
+// that was used (its "kid", or its position in the set if it has none).
+func verifyWithJWKS(sig *corimSignature, signedCorimFile, jwksFile string) (string, error) {
+	data, err := afero.ReadFile(fs, jwksFile)
+	if err != nil {
+		return "", fmt.Errorf("error loading JWK Set from %s: %w", jwksFile, err)
+	}
+
+	set, err := jwk.Parse(data)
+	if err != nil {
+		return "", fmt.Errorf("error decoding JWK Set from %s: %w", jwksFile, err)
+	}
+
+	if kid := sig.kid; kid != nil {
+		k, ok := set.LookupKeyID(string(kid))
+		if !ok {
+			return "", fmt.Errorf("no key with kid %q in JWK Set %s", kid, jwksFile)
+		}
+
+		pkey, err := jwkPublicKey(k)
+		if err != nil {
+			return "", fmt.Errorf("error loading key %q from %s: %w", kid, jwksFile, err)
+		}
+
+		if err = sig.verify(pkey); err != nil {
+			return "", fmt.Errorf("error verifying %s with key %q: %w", signedCorimFile, kid, err)
+		}
+
+		return string(kid), nil
+	}
+
+	for i := 0; i < set.Len(); i++ {
+		k, _ := set.Key(i)
+
+		pkey, err := jwkPublicKey(k)
+		if err != nil {
+			continue
+		}
+
+		if sig.verify(pkey) == nil {
+			if kid := k.KeyID(); kid != "" {
+				return kid, nil
+			}
+			return fmt.Sprintf("#%d", i), nil
+		}
+	}
+
+	return "", fmt.Errorf("error verifying %s: no kid header and no key in JWK Set %s verifies it", signedCorimFile, jwksFile)
+}
+
+func verifyWithTrustAnchors(sig *corimSignature, signedCorimFile string, taFiles []string) error {
+	var (
+		err   error
+		roots *x509.CertPool
+	)
+
+	if roots, err = loadCertPool(taFiles); err != nil {
+		return fmt.Errorf("error loading trust anchors: %w", err)
+	}
+
+	if err = verifyX5Chain(sig, roots, nil); err != nil {
+		return fmt.Errorf("error verifying %s: %w", signedCorimFile, err)
+	}
+
+	return nil
+}
+
+func verifyWithCots(sig *corimSignature, u *corim.UnsignedCorim, signedCorimFile, cotsFile string) error {
+	var (
+		cotsCBOR []byte
+		err      error
+		store    cots.ConciseTaStore
+	)
+
+	if cotsCBOR, err = afero.ReadFile(fs, cotsFile); err != nil {
+		return fmt.Errorf("error loading CoTS from %s: %w", cotsFile, err)
+	}
+
+	if err = store.FromCBOR(cotsCBOR); err != nil {
+		return fmt.Errorf("error decoding CoTS from %s: %w", cotsFile, err)
+	}
+
+	if err = validCots(&store); err != nil {
+		return fmt.Errorf("error validating CoTS %s: %w", cotsFile, err)
+	}
+
+	if !hasPurpose(store.Purposes, cotsPurposeCorim) {
+		return fmt.Errorf("CoTS %s does not have the %q purpose", cotsFile, cotsPurposeCorim)
+	}
+
+	envs, tagIDs, err := corimTargets(u)
+	if err != nil {
+		return fmt.Errorf("error extracting target environments from %s: %w", signedCorimFile, err)
+	}
+
+	if !cotsMatchesTargets(store.Environments, envs, tagIDs) {
+		return fmt.Errorf("no environment in CoTS %s matches the CoRIM in %s", cotsFile, signedCorimFile)
+	}
+
+	if err = verifyWithTrustAnchorStore(sig, store.Keys); err != nil {
+		return fmt.Errorf("error verifying %s: %w", signedCorimFile, err)
+	}
+
+	return nil
+}
+
+// verifyWithTrustAnchorStore verifies the signature against the trust
+// anchors and CAs from a CoTS.  Certificate trust anchors are used as roots
+// for path validation of the x5chain.  Trust anchors that only carry a public
+// key (SPKI or TrustAnchorInfo) must have issued the topmost certificate in the
+// x5chain or, if there is no x5chain, must verify the signature directly.
+func verifyWithTrustAnchorStore(sig *corimSignature, keys *cots.TasAndCas) error {
+	var (
+		roots    = x509.NewCertPool()
+		pubKeys  []crypto.PublicKey
+		cas      []*x509.Certificate
+		hasRoots bool
+	)
+
+	for i, ta := range keys.Tas {
+		pk, cert, err := parseTrustAnchor(ta)
+		if err != nil {
+			return fmt.Errorf("trust anchor at index %d: %w", i, err)
+		}
+
+		if cert != nil {
+			roots.AddCert(cert)
+			hasRoots = true
+		} else {
+			pubKeys = append(pubKeys, pk)
+		}
+	}
+
+	for i, ca := range keys.Cas {
+		cert, err := x509.ParseCertificate(ca)
+		if err != nil {
+			return fmt.Errorf("CA at index %d: %w", i, err)
+		}
+		cas = append(cas, cert)
+	}
+
+	if sig.signingCert == nil {
+		for _, pk := range pubKeys {
+			if err := sig.verify(pk); err == nil {
+				return nil
+			}
+		}
+		return errors.New("no signing certificate found in x5chain and no trust anchor key verifies the signature")
+	}
+
+	// the topmost certificate in the x5chain is promoted to root if it has
+	// been issued by one of the public key trust anchors
+	top := sig.signingCert
+	if n := len(sig.intermediateCerts); n > 0 {
+		top = sig.intermediateCerts[n-1]
+	}
+
+	for _, pk := range pubKeys {
+		issuer := &x509.Certificate{
+			PublicKey:          pk,
+			PublicKeyAlgorithm: publicKeyAlgorithm(pk),
+		}
+		if top.CheckSignatureFrom(issuer) == nil {
+			roots.AddCert(top)
+			hasRoots = true
+			break
+		}
+	}
+
+	if !hasRoots {
+		return errors.New("no trust anchor matches the x5chain")
+	}
+
+	return verifyX5Chain(sig, roots, cas)
+}
+
+func publicKeyAlgorithm(pk crypto.PublicKey) x509.PublicKeyAlgorithm {
+	switch pk.(type) {
+	case *ecdsa.PublicKey:
+		return x509.ECDSA
+	case *rsa.PublicKey:
+		return x509.RSA
+	case ed25519.PublicKey:
+		return x509.Ed25519
+	default:
+		return x509.UnknownPublicKeyAlgorithm
+	}
+}
+
+func hasPurpose(purposes []string, purpose string) bool {
+	for _, p := range purposes {
+		if p == purpose {
+			return true
+		}
+	}
+	return false
+}
+
+// corimTargets collects the environments described by the CoMIDs, and the tag
+// identifiers of the CoSWIDs, embedded in the supplied CoRIM
+func corimTargets(u *corim.UnsignedCorim) ([]comid.Environment, []swid.TagID, error) {
+	var (
+		envs   []comid.Environment
+		tagIDs []swid.TagID
+	)
+
+	for i, t := range u.Tags {
+		// only CoMIDs and CoSWIDs describe targets, other tags are skipped
+		typ, cborData, err := splitCorimTag(t)
+		if errors.Is(err, errMalformedTag) {
+			return nil, nil, fmt.Errorf("tag at index %d: %w", i, err)
+		}
+
+		switch typ {
+		case "comid":
+			var c comid.Comid
+			if err := c.FromCBOR(cborData); err != nil {
+				return nil, nil, fmt.Errorf("tag at index %d: decoding CoMID failed: %w", i, err)
+			}
+			envs = append(envs, comidEnvironments(&c.Triples)...)
+		case "coswid":
+			var s swid.SoftwareIdentity
+			if err := s.FromCBOR(cborData); err != nil {
+				return nil, nil, fmt.Errorf("tag at index %d: decoding CoSWID failed: %w", i, err)
+			}
+			tagIDs = append(tagIDs, s.TagID)
+		}
+	}
+
+	return envs, tagIDs, nil
+}
+
+func comidEnvironments(t *comid.Triples) []comid.Environment {
+	var envs []comid.Environment
+
+	for _, vts := range []*comid.ValueTriples{t.ReferenceValues, t.EndorsedValues} {
+		if vts == nil {
+			continue
+		}
+		for _, vt := range vts.Values {
+			envs = append(envs, vt.Environment)
+		}
+	}
+
+	for _, kts := range []*comid.KeyTriples{t.DevIdentityKeys, t.AttestVerifKeys} {
+		if kts == nil {
+			continue
+		}
+		for _, kt := range *kts {
+			envs = append(envs, kt.Environment)
+		}
+	}
+
+	return envs
+}
+
+// cotsMatchesTargets checks whether any of the CoTS environment groups applies
+// to the CoRIM.  A group with an environment or a swid tag applies if they
+// match the CoRIM; a group with neither (e.g., with just a named trust anchor
+// store, which is only a label) applies to any CoRIM.  A CoTS with no
+// environment groups applies to none.
+func cotsMatchesTargets(groups cots.EnvironmentGroups, envs []comid.Environment, tagIDs []swid.TagID) bool {
+	for _, g := range groups {
+		hasSwidTag := g.SwidTag != nil && g.SwidTag.TagID != nil
+
+		if g.Environment == nil && !hasSwidTag {
+			return true
+		}
+
+		if g.Environment != nil {
+			for _, env := range envs {
+				if environmentMatches(g.Environment, &env) {
+					return true
+				}
+			}
+		}
+
+		if hasSwidTag {
+			for _, tagID := range tagIDs {
+				if tagID.String() == g.SwidTag.TagID.String() {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// environmentMatches treats the CoTS environment as a pattern: each field that
+// is set in the pattern must be present, with the same value, in the target
+// environment
+func environmentMatches(pattern, target *comid.Environment) bool {
+	if pattern.Class != nil {
+		if target.Class == nil {
+			return false
+		}
+
+		p, t := pattern.Class, target.Class
+
+		if p.ClassID != nil && (t.ClassID == nil || !jsonEqual(p.ClassID, t.ClassID)) {
+			return false
+		}
+		if p.Vendor != nil && (t.Vendor == nil || *p.Vendor != *t.Vendor) {
+			return false
+		}
+		if p.Model != nil && (t.Model == nil || *p.Model != *t.Model) {
+			return false
+		}
+		if p.Layer != nil && (t.Layer == nil || *p.Layer != *t.Layer) {
+			return false
+		}
+		if p.Index != nil && (t.Index == nil || *p.Index != *t.Index) {
+			return false
+		}
+	}
+
+	if pattern.Instance != nil && (target.Instance == nil || !jsonEqual(pattern.Instance, target.Instance)) {
+		return false
+	}
+
+	if pattern.Group != nil && (target.Group == nil || !jsonEqual(pattern.Group, target.Group)) {
+		return false
+	}
+
+	return true
+}
+
+func jsonEqual(a, b any) bool {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(ja, jb)
+}
+
+// checkValidity makes sure that the supplied time falls within the validity
+// period of the CoRIM, if one is present
+func checkValidity(v *corim.Validity, at time.Time) error {
+	if v == nil {
+		return nil
+	}
+
+	if v.NotBefore != nil && at.Before(*v.NotBefore) {
+		return fmt.Errorf(
+			"CoRIM not yet valid: not-before %s is after %s",
+			v.NotBefore.Format(time.RFC3339), at.Format(time.RFC3339),
+		)
+	}
+
+	if at.After(v.NotAfter) {
+		return fmt.Errorf(
+			"CoRIM expired: not-after %s is before %s",
+			v.NotAfter.Format(time.RFC3339), at.Format(time.RFC3339),
+		)
+	}
+
+	return nil
+}
+
+// verifyX5Chain validates the certificate path from the x5chain of the
+// signature to one of the supplied roots, and then checks the
+// signature using the public key of the leaf certificate.  Any extra
+// intermediates (e.g., the CAs from a CoTS) are also used to build the path.
+// If the signature has a verified timestamp, the path is validated at the time
+// of the timestamp.
+func verifyX5Chain(sig *corimSignature, roots *x509.CertPool, extra []*x509.Certificate) error {
+	if sig.signingCert == nil {
+		return errors.New("no signing certificate found in x5chain")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range sig.intermediateCerts {
+		intermediates.AddCert(c)
+	}
+	for _, c := range extra {
+		intermediates.AddCert(c)
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+
+	if sig.timestamp != nil {
+		opts.CurrentTime = sig.timestamp.genTime
+	}
+
+	if _, err := sig.signingCert.Verify(opts); err != nil {
+		return fmt.Errorf("certificate path validation failed: %w", err)
+	}
+
+	if err := sig.verify(sig.signingCert.PublicKey); err != nil {
+		return fmt.Errorf("signature verification failed: %w", err)
+	}
+
+	return nil
+}
+
 func init() {
 	corimCmd.AddCommand(corimVerifyCmd)
 }
@@ -4,11 +4,26 @@
 package cmd
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"fmt"
+	"math/big"
 	"testing"
+	"time"
 
+	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/corim/corim"
+	"github.com/veraison/corim/cots"
+	cose "github.com/veraison/go-cose"
 )
 
 func Test_CorimVerifyCmd_unknown_argument(t *testing.T) {
@@ -42,7 +57,21 @@ func Test_CorimVerifyCmd_mandatory_args_missing_key_file(t *testing.T) {
 	cmd.SetArgs(args)
 
 	err := cmd.Execute()
-	assert.EqualError(t, err, "no key supplied")
+	assert.EqualError(t, err, "no key or trust anchors supplied")
+}
+
+func Test_CorimVerifyCmd_key_and_ta_cert(t *testing.T) {
+	cmd := NewCorimVerifyCmd()
+
+	args := []string{
+		"--file=ignored.cbor",
+		"--key=ignored.jwk",
+		"--ta-cert=ignored.der",
+	}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "only one of --key, --jwks, --ta-cert/--ta-cert-dir and --cots can be used at the same time")
 }
 
 func Test_CorimVerifyCmd_non_existent_signed_corim_file(t *testing.T) {
@@ -119,6 +148,7 @@ func Test_CorimVerifyCmd_ok(t *testing.T) {
 	args := []string{
 		"--file=ok.cbor",
 		"--key=ok.jwk",
+		"--at=2024-06-01T00:00:00Z",
 	}
 	cmd.SetArgs(args)
 
@@ -131,3 +161,891 @@ func Test_CorimVerifyCmd_ok(t *testing.T) {
 	err = cmd.Execute()
 	assert.NoError(t, err)
 }
+
+type testPKI struct {
+	Root, Intermediate, Leaf []byte
+	LeafKey                  crypto.Signer
+}
+
+func makeTestCert(
+	t *testing.T, cn string, isCA bool, pub crypto.PublicKey, parent *x509.Certificate, parentKey crypto.Signer,
+) (*x509.Certificate, []byte) {
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+
+	if parent == nil {
+		parent = tmpl
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, pub, parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, der
+}
+
+func makeTestPKI(t *testing.T) testPKI {
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	intKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	root, rootDER := makeTestCert(t, "Test Root", true, rootKey.Public(), nil, rootKey)
+	inter, intDER := makeTestCert(t, "Test Intermediate", true, intKey.Public(), root, rootKey)
+	_, leafDER := makeTestCert(t, "Test Signer", false, leafKey.Public(), inter, intKey)
+
+	return testPKI{Root: rootDER, Intermediate: intDER, Leaf: leafDER, LeafKey: leafKey}
+}
+
+func makeTestCorimWithComid(t *testing.T) *corim.UnsignedCorim {
+	var m comid.Comid
+	require.NoError(t, m.FromJSON(testComidACMERoadRunner))
+
+	c := corim.NewUnsignedCorim().
+		SetID("test-corim").
+		AddComid(&m)
+	require.NotNil(t, c)
+
+	return c
+}
+
+func makeTestSignedCorimWithChain(t *testing.T, pki testPKI) []byte {
+	c := *makeTestCorimWithComid(t)
+
+	var m corim.Meta
+	require.NoError(t, m.FromJSON(testMetaValid))
+
+	notBefore := time.Now().Add(-time.Hour)
+	m.SetValidity(notBefore.Add(24*time.Hour), &notBefore)
+
+	s := corim.SignedCorim{UnsignedCorim: c, Meta: m}
+	require.NoError(t, s.AddSigningCert(pki.Leaf))
+	require.NoError(t, s.AddIntermediateCerts(pki.Intermediate))
+
+	signer, err := cose.NewSigner(cose.AlgorithmES256, pki.LeafKey)
+	require.NoError(t, err)
+
+	data, err := s.Sign(signer)
+	require.NoError(t, err)
+
+	return data
+}
+
+func Test_CorimVerifyCmd_ta_cert_ok(t *testing.T) {
+	pki := makeTestPKI(t)
+
+	cmd := NewCorimVerifyCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--ta-cert=root.der",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "ok.cbor", makeTestSignedCorimWithChain(t, pki), 0644)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, "root.der", pki.Root, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+}
+
+func Test_CorimVerifyCmd_ta_cert_dir_untrusted_root(t *testing.T) {
+	pki := makeTestPKI(t)
+	otherPKI := makeTestPKI(t)
+
+	cmd := NewCorimVerifyCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--ta-cert-dir=tas",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "ok.cbor", makeTestSignedCorimWithChain(t, pki), 0644)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, "tas/other-root.der", otherPKI.Root, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.ErrorContains(t, err, "error verifying ok.cbor: certificate path validation failed")
+}
+
+func Test_CorimVerifyCmd_ta_cert_no_x5chain(t *testing.T) {
+	pki := makeTestPKI(t)
+
+	cmd := NewCorimVerifyCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--ta-cert=root.der",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "ok.cbor", testSignedCorimValid, 0644)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, "root.der", pki.Root, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, "error verifying ok.cbor: no signing certificate found in x5chain")
+}
+
+func Test_CorimVerifyCmd_ta_cert_not_found(t *testing.T) {
+	cmd := NewCorimVerifyCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--ta-cert=nonexistent.der",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "no trust anchor files found")
+}
+
+func makeTestCots(t *testing.T, purpose string, env *comid.Environment, tas ...[]byte) []byte {
+	store := cots.NewConciseTaStore().AddPurpose(purpose)
+
+	eg := cots.NewEnvironmentGroup()
+	if env != nil {
+		eg.SetEnvironment(*env)
+	} else {
+		eg.SetNamedTaStore("test store")
+	}
+	store.AddEnvironmentGroup(*eg)
+
+	var keys cots.TasAndCas
+	for _, ta := range tas {
+		keys.Tas = append(keys.Tas, *cots.NewTrustAnchor().SetFormat(cots.TaFormatCertificate).SetData(ta))
+	}
+	store.SetKeys(keys)
+
+	data, err := store.ToCBOR()
+	require.NoError(t, err)
+
+	return data
+}
+
+func testACMEEnvironment(vendor string) *comid.Environment {
+	return &comid.Environment{
+		Class: comid.NewClassUUID(comid.TestUUID).SetVendor(vendor),
+	}
+}
+
+func Test_CorimVerifyCmd_key_and_cots(t *testing.T) {
+	cmd := NewCorimVerifyCmd()
+
+	args := []string{
+		"--file=ignored.cbor",
+		"--key=ignored.jwk",
+		"--cots=ignored.cbor",
+	}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "only one of --key, --jwks, --ta-cert/--ta-cert-dir and --cots can be used at the same time")
+}
+
+func Test_CorimVerifyCmd_cots_ok(t *testing.T) {
+	pki := makeTestPKI(t)
+	otherPKI := makeTestPKI(t)
+
+	cmd := NewCorimVerifyCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--cots=store.cbor",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "ok.cbor", makeTestSignedCorimWithChain(t, pki), 0644)
+	require.NoError(t, err)
+	store := makeTestCots(t, "corim", testACMEEnvironment("ACME"), otherPKI.Root, pki.Root)
+	err = afero.WriteFile(fs, "store.cbor", store, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+}
+
+func Test_CorimVerifyCmd_cots_named_store_ok(t *testing.T) {
+	pki := makeTestPKI(t)
+
+	cmd := NewCorimVerifyCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--cots=store.cbor",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "ok.cbor", makeTestSignedCorimWithChain(t, pki), 0644)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, "store.cbor", makeTestCots(t, "corim", nil, pki.Root), 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+}
+
+func Test_CorimVerifyCmd_cots_wrong_purpose(t *testing.T) {
+	pki := makeTestPKI(t)
+
+	cmd := NewCorimVerifyCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--cots=store.cbor",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "ok.cbor", makeTestSignedCorimWithChain(t, pki), 0644)
+	require.NoError(t, err)
+	store := makeTestCots(t, "eat", testACMEEnvironment("ACME"), pki.Root)
+	err = afero.WriteFile(fs, "store.cbor", store, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, `CoTS store.cbor does not have the "corim" purpose`)
+}
+
+func Test_CorimVerifyCmd_cots_environment_mismatch(t *testing.T) {
+	pki := makeTestPKI(t)
+
+	cmd := NewCorimVerifyCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--cots=store.cbor",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "ok.cbor", makeTestSignedCorimWithChain(t, pki), 0644)
+	require.NoError(t, err)
+	store := makeTestCots(t, "corim", testACMEEnvironment("EMCA"), pki.Root)
+	err = afero.WriteFile(fs, "store.cbor", store, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, "no environment in CoTS store.cbor matches the CoRIM in ok.cbor")
+}
+
+func Test_CorimVerifyCmd_cots_named_store_environment_mismatch(t *testing.T) {
+	pki := makeTestPKI(t)
+
+	cmd := NewCorimVerifyCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--cots=store.cbor",
+	}
+	cmd.SetArgs(args)
+
+	// the named store is only a label: the environment must still match
+	eg := cots.NewEnvironmentGroup().SetEnvironment(*testACMEEnvironment("EMCA"))
+	eg.SetNamedTaStore("test store")
+
+	store := cots.NewConciseTaStore().AddPurpose("corim").AddEnvironmentGroup(*eg)
+	store.SetKeys(cots.TasAndCas{Tas: []cots.TrustAnchor{
+		*cots.NewTrustAnchor().SetFormat(cots.TaFormatCertificate).SetData(pki.Root),
+	}})
+
+	storeCBOR, err := store.ToCBOR()
+	require.NoError(t, err)
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "ok.cbor", makeTestSignedCorimWithChain(t, pki), 0644)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, "store.cbor", storeCBOR, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, "no environment in CoTS store.cbor matches the CoRIM in ok.cbor")
+}
+
+func Test_cotsMatchesTargets_no_groups(t *testing.T) {
+	envs := []comid.Environment{*testACMEEnvironment("ACME")}
+
+	assert.False(t, cotsMatchesTargets(nil, envs, nil))
+}
+
+func Test_CorimVerifyCmd_cots_untrusted_root(t *testing.T) {
+	pki := makeTestPKI(t)
+	otherPKI := makeTestPKI(t)
+
+	cmd := NewCorimVerifyCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--cots=store.cbor",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "ok.cbor", makeTestSignedCorimWithChain(t, pki), 0644)
+	require.NoError(t, err)
+	store := makeTestCots(t, "corim", testACMEEnvironment("ACME"), otherPKI.Root)
+	err = afero.WriteFile(fs, "store.cbor", store, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.ErrorContains(t, err, "error verifying ok.cbor: certificate path validation failed")
+}
+
+func Test_environmentMatches(t *testing.T) {
+	target := testACMEEnvironment("ACME")
+	target.Class.SetModel("RoadRunner")
+
+	assert.True(t, environmentMatches(&comid.Environment{}, target))
+	assert.True(t, environmentMatches(testACMEEnvironment("ACME"), target))
+	assert.False(t, environmentMatches(testACMEEnvironment("EMCA"), target))
+	assert.False(t, environmentMatches(target, testACMEEnvironment("ACME")))
+}
+
+func Test_CorimVerifyCmd_cots_spki_ok(t *testing.T) {
+	pki := makeTestPKI(t)
+
+	root, err := x509.ParseCertificate(pki.Root)
+	require.NoError(t, err)
+	spki, err := x509.MarshalPKIXPublicKey(root.PublicKey)
+	require.NoError(t, err)
+
+	store := cots.NewConciseTaStore().
+		AddPurpose("corim").
+		AddEnvironmentGroup(*cots.NewEnvironmentGroup().SetNamedTaStore("test store")).
+		SetKeys(cots.TasAndCas{
+			Tas: []cots.TrustAnchor{
+				*cots.NewTrustAnchor().SetFormat(cots.TaFormatSubjectPublicKeyInfo).SetData(spki),
+			},
+		})
+	storeCBOR, err := store.ToCBOR()
+	require.NoError(t, err)
+
+	cmd := NewCorimVerifyCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--cots=store.cbor",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "ok.cbor", makeTestSignedCorimWithChain(t, pki), 0644)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, "store.cbor", storeCBOR, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+}
+
+func Test_CorimVerifyCmd_expired(t *testing.T) {
+	cmd := NewCorimVerifyCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--key=ok.jwk",
+		"--at=2026-01-01T00:00:00Z",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "ok.cbor", testSignedCorimValid, 0644)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, "ok.jwk", testECKey, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err,
+		"error verifying ok.cbor: CoRIM expired: not-after 2025-12-31T00:00:00Z is before 2026-01-01T00:00:00Z")
+}
+
+func Test_CorimVerifyCmd_not_yet_valid(t *testing.T) {
+	cmd := NewCorimVerifyCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--key=ok.jwk",
+		"--at=2020-01-01T00:00:00Z",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "ok.cbor", testSignedCorimValid, 0644)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, "ok.jwk", testECKey, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err,
+		"error verifying ok.cbor: CoRIM not yet valid: not-before 2021-12-31T00:00:00Z is after 2020-01-01T00:00:00Z")
+}
+
+func Test_CorimVerifyCmd_skip_validity_check(t *testing.T) {
+	cmd := NewCorimVerifyCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--key=ok.jwk",
+		"--skip-validity-check",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "ok.cbor", testSignedCorimValid, 0644)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, "ok.jwk", testECKey, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+}
+
+func Test_CorimVerifyCmd_at_and_skip_validity_check(t *testing.T) {
+	cmd := NewCorimVerifyCmd()
+
+	args := []string{
+		"--file=ignored.cbor",
+		"--key=ignored.jwk",
+		"--at=2024-06-01T00:00:00Z",
+		"--skip-validity-check",
+	}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "--at and --skip-validity-check cannot be used at the same time")
+}
+
+func Test_CorimVerifyCmd_bad_at(t *testing.T) {
+	cmd := NewCorimVerifyCmd()
+
+	args := []string{
+		"--file=ignored.cbor",
+		"--key=ignored.jwk",
+		"--at=yesterday",
+	}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "invalid --at time: ")
+}
+
+func Test_CorimVerifyCmd_pem_certificate_as_key_ok(t *testing.T) {
+	pki := makeTestPKI(t)
+
+	cmd := NewCorimVerifyCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--key=leaf.pem",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "ok.cbor", makeTestSignedCorimWithChain(t, pki), 0644)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, "leaf.pem", pemEncode("CERTIFICATE", pki.Leaf), 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+}
+
+// makeTestJWKS returns a JWK Set with the supplied public keys, each with the
+// corresponding kid (no kid if empty)
+func makeTestJWKS(t *testing.T, kids []string, pubs []crypto.PublicKey) []byte {
+	set := jwk.NewSet()
+
+	for i, pub := range pubs {
+		k, err := jwk.FromRaw(pub)
+		require.NoError(t, err)
+		if kids[i] != "" {
+			require.NoError(t, k.Set(jwk.KeyIDKey, kids[i]))
+		}
+		require.NoError(t, set.AddKey(k))
+	}
+
+	data, err := json.Marshal(set)
+	require.NoError(t, err)
+
+	return data
+}
+
+func makeTestSignedCorimWithKid(t *testing.T, key crypto.Signer, kid string) []byte {
+	signer, err := cose.NewSigner(cose.AlgorithmES256, key)
+	require.NoError(t, err)
+
+	s := corim.SignedCorim{UnsignedCorim: *makeTestCorimWithComid(t)}
+	s.Meta.SetSigner("ACME Ltd.", nil)
+
+	data, _, err := signCorim(&s, signer, coseSignOptions{kid: []byte(kid)})
+	require.NoError(t, err)
+
+	return data
+}
+
+func Test_CorimVerifyCmd_jwks_ok(t *testing.T) {
+	old, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	current, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "signed.cbor", makeTestSignedCorimWithKid(t, current, "2025-q3"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "keys.json",
+		makeTestJWKS(t, []string{"2025-q2", "2025-q3"}, []crypto.PublicKey{old.Public(), current.Public()}), 0644))
+
+	cmd := NewCorimVerifyCmd()
+	cmd.SetArgs([]string{"--file=signed.cbor", "--jwks=keys.json"})
+
+	assert.NoError(t, cmd.Execute())
+
+	vc, err := loadSignedCorim("signed.cbor", "")
+	require.NoError(t, err)
+
+	kid, err := verifyWithJWKS(vc.Signatures[0], "signed.cbor", "keys.json")
+	require.NoError(t, err)
+	assert.Equal(t, "2025-q3", kid)
+}
+
+func Test_CorimVerifyCmd_jwks_kid_not_found(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "signed.cbor", makeTestSignedCorimWithKid(t, key, "2025-q4"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "keys.json",
+		makeTestJWKS(t, []string{"2025-q3"}, []crypto.PublicKey{key.Public()}), 0644))
+
+	cmd := NewCorimVerifyCmd()
+	cmd.SetArgs([]string{"--file=signed.cbor", "--jwks=keys.json"})
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, `no key with kid "2025-q4" in JWK Set keys.json`)
+}
+
+func Test_CorimVerifyCmd_jwks_wrong_key_for_kid(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "signed.cbor", makeTestSignedCorimWithKid(t, key, "k1"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "keys.json",
+		makeTestJWKS(t, []string{"k1"}, []crypto.PublicKey{other.Public()}), 0644))
+
+	cmd := NewCorimVerifyCmd()
+	cmd.SetArgs([]string{"--file=signed.cbor", "--jwks=keys.json"})
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, `error verifying signed.cbor with key "k1": verification error`)
+}
+
+func Test_CorimVerifyCmd_jwks_no_kid(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "signed.cbor", makeTestSignedCorimWithKid(t, key, ""), 0644))
+
+	vc, err := loadSignedCorim("signed.cbor", "")
+	require.NoError(t, err)
+
+	require.NoError(t, afero.WriteFile(fs, "keys.json",
+		makeTestJWKS(t, []string{"other", ""}, []crypto.PublicKey{other.Public(), key.Public()}), 0644))
+	kid, err := verifyWithJWKS(vc.Signatures[0], "signed.cbor", "keys.json")
+	require.NoError(t, err)
+	assert.Equal(t, "#1", kid)
+
+	require.NoError(t, afero.WriteFile(fs, "keys.json",
+		makeTestJWKS(t, []string{"other"}, []crypto.PublicKey{other.Public()}), 0644))
+	_, err = verifyWithJWKS(vc.Signatures[0], "signed.cbor", "keys.json")
+	assert.EqualError(t, err,
+		"error verifying signed.cbor: no kid header and no key in JWK Set keys.json verifies it")
+}
+
+func Test_CorimVerifyCmd_bad_jwks(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "signed.cbor", makeTestSignedCorimWithKid(t, key, "k1"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "keys.json", []byte("[]"), 0644))
+
+	cmd := NewCorimVerifyCmd()
+	cmd.SetArgs([]string{"--file=signed.cbor", "--jwks=keys.json"})
+
+	err = cmd.Execute()
+	assert.ErrorContains(t, err, "error decoding JWK Set from keys.json")
+}
+
+func writeDetachedFixtures(t *testing.T) {
+	writeSignFixtures(t)
+
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{"--file=ok.cbor", "--key=ok.jwk", "--meta=ok.json", "--detached"})
+	require.NoError(t, cmd.Execute())
+}
+
+func Test_CorimVerifyCmd_detached_ok(t *testing.T) {
+	writeDetachedFixtures(t)
+
+	cmd := NewCorimVerifyCmd()
+	cmd.SetArgs([]string{
+		"--file=signed-ok.cbor",
+		"--payload=signed-ok.payload.cbor",
+		"--key=ok.jwk",
+		"--skip-validity-check",
+	})
+
+	assert.NoError(t, cmd.Execute())
+}
+
+func Test_CorimVerifyCmd_detached_jwks_ok(t *testing.T) {
+	writeDetachedFixtures(t)
+
+	pk, err := newPublicKeyFromKeyData(testECKey)
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, "keys.json",
+		makeTestJWKS(t, []string{"1"}, []crypto.PublicKey{pk}), 0644))
+
+	cmd := NewCorimVerifyCmd()
+	cmd.SetArgs([]string{
+		"--file=signed-ok.cbor",
+		"--payload=signed-ok.payload.cbor",
+		"--jwks=keys.json",
+		"--skip-validity-check",
+	})
+
+	assert.NoError(t, cmd.Execute())
+}
+
+func Test_CorimVerifyCmd_detached_missing_payload(t *testing.T) {
+	writeDetachedFixtures(t)
+
+	cmd := NewCorimVerifyCmd()
+	cmd.SetArgs([]string{
+		"--file=signed-ok.cbor",
+		"--key=ok.jwk",
+	})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "signed-ok.cbor has a detached payload: use --payload")
+}
+
+func Test_CorimVerifyCmd_detached_wrong_payload(t *testing.T) {
+	writeDetachedFixtures(t)
+
+	other := makeTestCorimWithComid(t)
+	otherCBOR, err := other.ToCBOR()
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, "other.cbor", otherCBOR, 0644))
+
+	cmd := NewCorimVerifyCmd()
+	cmd.SetArgs([]string{
+		"--file=signed-ok.cbor",
+		"--payload=other.cbor",
+		"--key=ok.jwk",
+	})
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, "error verifying signed-ok.cbor with key ok.jwk: verification error")
+}
+
+func Test_CorimVerifyCmd_payload_with_attached_signature(t *testing.T) {
+	writeDetachedFixtures(t)
+
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{"--file=ok.cbor", "--key=ok.jwk", "--meta=ok.json", "--output=attached.cbor"})
+	require.NoError(t, cmd.Execute())
+
+	cmd = NewCorimVerifyCmd()
+	cmd.SetArgs([]string{
+		"--file=attached.cbor",
+		"--payload=ok.cbor",
+		"--key=ok.jwk",
+	})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err,
+		"error attaching payload from ok.cbor to attached.cbor: the COSE Sign1 payload is not detached")
+}
+
+// writeMultiSignedFixtures saves a CoRIM signed by the leaf keys of the
+// supplied PKIs, with kids "signer0", "signer1", etc., along with the leaf
+// keys in PEM format and the roots in DER format
+func writeMultiSignedFixtures(t *testing.T, pkis ...testPKI) {
+	var (
+		m       corim.Meta
+		signers []coseSigner
+	)
+
+	require.NoError(t, m.FromJSON(testMetaValid))
+	notBefore := time.Now().Add(-time.Hour)
+	m.SetValidity(notBefore.Add(24*time.Hour), &notBefore)
+
+	writeSignFixtures(t)
+
+	for i, pki := range pkis {
+		name := fmt.Sprintf("signer%d", i)
+
+		signer, err := cose.NewSigner(cose.AlgorithmES256, pki.LeafKey)
+		require.NoError(t, err)
+
+		leaf, err := x509.ParseCertificate(pki.Leaf)
+		require.NoError(t, err)
+		inter, err := x509.ParseCertificate(pki.Intermediate)
+		require.NoError(t, err)
+
+		signers = append(signers, coseSigner{
+			signer:            signer,
+			kid:               []byte(name),
+			signingCert:       leaf,
+			intermediateCerts: []*x509.Certificate{inter},
+		})
+
+		require.NoError(t, afero.WriteFile(fs, name+".pem", pemEncode("PRIVATE KEY", mustPKCS8(t, pki.LeafKey)), 0644))
+		require.NoError(t, afero.WriteFile(fs, name+"-root.der", pki.Root, 0644))
+	}
+
+	data, _, err := signMultiCorim(makeTestCorimWithComid(t), &m, signers, false)
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, "signed.cbor", data, 0644))
+}
+
+func Test_CorimVerifyCmd_multi_signed_keys_ok(t *testing.T) {
+	writeMultiSignedFixtures(t, makeTestPKI(t), makeTestPKI(t))
+
+	cmd := NewCorimVerifyCmd()
+	cmd.SetArgs([]string{"--file=signed.cbor", "--key=signer0.pem", "--key=signer1.pem"})
+
+	assert.NoError(t, cmd.Execute())
+}
+
+func Test_CorimVerifyCmd_multi_signed_ta_cert_ok(t *testing.T) {
+	writeMultiSignedFixtures(t, makeTestPKI(t), makeTestPKI(t))
+
+	cmd := NewCorimVerifyCmd()
+	cmd.SetArgs([]string{"--file=signed.cbor", "--ta-cert=signer0-root.der", "--ta-cert=signer1-root.der"})
+
+	assert.NoError(t, cmd.Execute())
+}
+
+func Test_CorimVerifyCmd_multi_signed_jwks_ok(t *testing.T) {
+	pkis := []testPKI{makeTestPKI(t), makeTestPKI(t)}
+	writeMultiSignedFixtures(t, pkis...)
+	require.NoError(t, afero.WriteFile(fs, "keys.json", makeTestJWKS(t,
+		[]string{"signer1", "signer0"},
+		[]crypto.PublicKey{pkis[1].LeafKey.Public(), pkis[0].LeafKey.Public()},
+	), 0644))
+
+	cmd := NewCorimVerifyCmd()
+	cmd.SetArgs([]string{"--file=signed.cbor", "--jwks=keys.json"})
+
+	assert.NoError(t, cmd.Execute())
+}
+
+func Test_CorimVerifyCmd_multi_signed_policy(t *testing.T) {
+	tvs := []struct {
+		policy   string
+		expected string
+	}{
+		{"all", "error verifying signed.cbor: 1 of 2 signatures verified, 2 required"},
+		{"2", "error verifying signed.cbor: 1 of 2 signatures verified, 2 required"},
+		{"3", `error verifying signed.cbor: policy "3" requires 3 signatures, found 2`},
+		{"any", ""},
+		{"1", ""},
+	}
+
+	writeMultiSignedFixtures(t, makeTestPKI(t), makeTestPKI(t))
+
+	for _, tv := range tvs {
+		t.Run(tv.policy, func(t *testing.T) {
+			cmd := NewCorimVerifyCmd()
+			cmd.SetArgs([]string{"--file=signed.cbor", "--ta-cert=signer1-root.der", "--policy=" + tv.policy})
+
+			err := cmd.Execute()
+			if tv.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tv.expected)
+			}
+		})
+	}
+}
+
+func Test_CorimVerifyCmd_invalid_policy(t *testing.T) {
+	cmd := NewCorimVerifyCmd()
+	cmd.SetArgs([]string{"--file=signed.cbor", "--key=key.jwk", "--policy=some"})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, `invalid --policy "some": expecting "all", "any" or a positive number`)
+}
+
+func Test_CorimVerifyCmd_single_signed_policy_too_strict(t *testing.T) {
+	writeDetachedFixtures(t)
+
+	cmd := NewCorimVerifyCmd()
+	cmd.SetArgs([]string{
+		"--file=signed-ok.cbor",
+		"--payload=signed-ok.payload.cbor",
+		"--key=ok.jwk",
+		"--policy=2",
+	})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, `error verifying signed-ok.cbor: policy "2" requires 2 signatures, found 1`)
+}
+
+func Test_corimSignature_String(t *testing.T) {
+	pki := makeTestPKI(t)
+	leaf, err := x509.ParseCertificate(pki.Leaf)
+	require.NoError(t, err)
+
+	assert.Equal(t, `kid "k1"`, (&corimSignature{kid: []byte("k1"), signingCert: leaf}).String())
+	assert.Equal(t, `subject "CN=Test Signer"`, (&corimSignature{signingCert: leaf}).String())
+	assert.Equal(t, "anonymous", (&corimSignature{}).String())
+}
+
+func Test_corimTargets(t *testing.T) {
+	u := makeTestCorimWithComid(t)
+
+	// tags that describe no target are skipped
+	u.Tags = append(u.Tags, corim.Tag(append([]byte{0xd9, 0x01, 0xfb}, testCots...)))
+
+	envs, tagIDs, err := corimTargets(u)
+	require.NoError(t, err)
+	assert.NotEmpty(t, envs)
+	assert.Empty(t, tagIDs)
+
+	// a tag with no payload is malformed
+	u.Tags = append(u.Tags, corim.Tag{0xd9, 0x01, 0xfa})
+
+	_, _, err = corimTargets(u)
+	assert.EqualError(t, err, "tag at index 2: malformed tag")
+}
@@ -0,0 +1,218 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/veraison/corim/corim"
+	cose "github.com/veraison/go-cose"
+)
+
+// coseSignOptions controls the COSE envelope produced by signCorim
+type coseSignOptions struct {
+	// key identifier, set in the protected header if not empty
+	kid []byte
+	// leave the payload out of the envelope
+	detached bool
+	// URL of the TSA from which to obtain a timestamp token for each
+	// signature, if not empty (applied by sign, once the envelope is complete)
+	tsaURL string
+}
+
+// signCorim wraps the CoRIM in a COSE Sign1 envelope and signs it using
+// corim.SignedCorim.Sign, adding the key identifier, if supplied, and detaching
+// the payload, if requested.  The encoded payload (i.e., the unsigned CoRIM) is
+// returned alongside the envelope, so that it can be shipped separately if the
+// signature is detached.
+func signCorim(s *corim.SignedCorim, signer cose.Signer, opts coseSignOptions) ([]byte, []byte, error) {
+	// SignedCorim.Sign spots signers without an algorithm by the name go-cose
+	// used to give to unknown algorithms, which has since changed
+	if !slices.Contains(coseAlgorithms, signer.Algorithm()) {
+		return nil, nil, fmt.Errorf("signer has no algorithm (got %s)", signer.Algorithm())
+	}
+
+	// SignedCorim.Sign does not allow adding other protected header
+	// parameters, so with a key identifier the envelope it produces is only
+	// used as a template, and signed once the key identifier is added
+	envSigner := signer
+	if len(opts.kid) != 0 {
+		envSigner = placeholderSigner{alg: signer.Algorithm()}
+	}
+
+	signed, err := s.Sign(envSigner)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	msg, err := decodeSign1(signed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error decoding signed CoRIM: %w", err)
+	}
+
+	if len(opts.kid) != 0 {
+		msg.Headers.Protected[cose.HeaderLabelKeyID] = opts.kid
+		msg.Headers.RawProtected = nil
+		msg.Signature = nil
+
+		if err = msg.Sign(rand.Reader, corim.NoExternalData, signer); err != nil {
+			return nil, nil, fmt.Errorf("COSE Sign1 signature failed: %w", err)
+		}
+	}
+
+	payload := msg.Payload
+
+	if opts.detached {
+		msg.Payload = nil
+	}
+
+	if signed, err = msg.MarshalCBOR(); err != nil {
+		return nil, nil, fmt.Errorf("signed-corim marshaling failed: %w", err)
+	}
+
+	return signed, payload, nil
+}
+
+// placeholderSigner stands in for the actual signer when only the COSE Sign1
+// envelope produced by corim.SignedCorim.Sign is needed
+type placeholderSigner struct {
+	alg cose.Algorithm
+}
+
+func (o placeholderSigner) Algorithm() cose.Algorithm {
+	return o.alg
+}
+
+func (o placeholderSigner) Sign(io.Reader, []byte) ([]byte, error) {
+	return []byte{0}, nil
+}
+
+// x5chain encodes the signing certificate and the intermediate certificates as
+// an x5chain header value:
+//
+//	COSE_X509 = bstr / [ 2*certs: bstr ]
+func x5chain(signingCert *x509.Certificate, intermediateCerts []*x509.Certificate) any {
+	if len(intermediateCerts) == 0 {
+		return signingCert.Raw
+	}
+
+	certChain := [][]byte{signingCert.Raw}
+	for _, cert := range intermediateCerts {
+		certChain = append(certChain, cert.Raw)
+	}
+
+	return certChain
+}
+
+// parseX5Chain decodes an x5chain header value into the signing certificate
+// and the intermediate certificates
+func parseX5Chain(v any) (*x509.Certificate, []*x509.Certificate, error) {
+	var ders [][]byte
+
+	switch t := v.(type) {
+	case []byte:
+		ders = [][]byte{t}
+	case []any:
+		for i, e := range t {
+			der, ok := e.([]byte)
+			if !ok {
+				return nil, nil, fmt.Errorf("x5chain entry at index %d: expecting bstr, got %T", i, e)
+			}
+			ders = append(ders, der)
+		}
+	default:
+		return nil, nil, fmt.Errorf("x5chain: expecting bstr or array, got %T", v)
+	}
+
+	if len(ders) == 0 {
+		return nil, nil, errors.New("empty x5chain")
+	}
+
+	certs := make([]*x509.Certificate, 0, len(ders))
+	for i, der := range ders {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, nil, fmt.Errorf("x5chain entry at index %d: %w", i, err)
+		}
+		certs = append(certs, cert)
+	}
+
+	return certs[0], certs[1:], nil
+}
+
+// legacy #6.500(#6.502(...)) prefix of signed CoRIMs, which is stripped by
+// corim.SignedCorim.FromCOSE
+var legacySignedCorimPrefix = []byte("\xd9\x01\xf4\xd9\x01\xf6")
+
+// isSign1 tells whether the supplied data looks like a COSE Sign1, i.e., starts
+// with tag #6.18, possibly after the legacy signed CoRIM prefix
+func isSign1(data []byte) bool {
+	data, _ = bytes.CutPrefix(data, legacySignedCorimPrefix)
+
+	return len(data) != 0 && data[0] == 0xd2
+}
+
+// decodeSign1 decodes the COSE Sign1 envelope of a signed CoRIM, without
+// looking into its payload
+func decodeSign1(data []byte) (*cose.Sign1Message, error) {
+	data, _ = bytes.CutPrefix(data, legacySignedCorimPrefix)
+
+	msg := cose.NewSign1Message()
+	if err := msg.UnmarshalCBOR(data); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// attachPayload embeds the supplied payload into a COSE Sign1 or COSE Sign
+// envelope with a detached payload, so that the result can be processed as a
+// regular signed CoRIM
+func attachPayload(signed, payload []byte) ([]byte, error) {
+	if isMultiSigned(signed) {
+		msg := cose.NewSignMessage()
+		if err := msg.UnmarshalCBOR(signed); err != nil {
+			return nil, err
+		}
+
+		if msg.Payload != nil {
+			return nil, errors.New("the COSE Sign payload is not detached")
+		}
+
+		msg.Payload = payload
+
+		return msg.MarshalCBOR()
+	}
+
+	msg, err := decodeSign1(signed)
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.Payload != nil {
+		return nil, errors.New("the COSE Sign1 payload is not detached")
+	}
+
+	msg.Payload = payload
+
+	return msg.MarshalCBOR()
+}
+
+// keyID returns the key identifier found in the protected or, failing that,
+// the unprotected header
+func keyID(hdr cose.Headers) []byte {
+	for _, h := range []map[any]any{hdr.Protected, hdr.Unprotected} {
+		if kid, ok := h[cose.HeaderLabelKeyID].([]byte); ok {
+			return kid
+		}
+	}
+
+	return nil
+}
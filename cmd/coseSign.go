@@ -0,0 +1,273 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/veraison/corim/corim"
+	cose "github.com/veraison/go-cose"
+)
+
+// the tag of COSE_Sign_Tagged, i.e., #6.98
+var coseSignPrefix = []byte{0xd8, 0x62}
+
+// isMultiSigned tells whether the supplied data looks like a COSE_Sign, as
+// opposed to a COSE_Sign1
+func isMultiSigned(data []byte) bool {
+	return bytes.HasPrefix(data, coseSignPrefix)
+}
+
+// coseSigner holds what is needed to produce one of the signatures of a
+// multi-signed CoRIM
+type coseSigner struct {
+	signer            cose.Signer
+	kid               []byte
+	signingCert       *x509.Certificate
+	intermediateCerts []*x509.Certificate
+}
+
+// newCoseSignature creates the (yet unsigned) COSE_Signature for the signer,
+// with alg, kid and x5chain in its protected header
+func (o coseSigner) newCoseSignature() *cose.Signature {
+	sig := cose.NewSignature()
+
+	sig.Headers.Protected.SetAlgorithm(o.signer.Algorithm())
+
+	if len(o.kid) != 0 {
+		sig.Headers.Protected[cose.HeaderLabelKeyID] = o.kid
+	}
+
+	if o.signingCert != nil {
+		sig.Headers.Protected[cose.HeaderLabelX5Chain] = x5chain(o.signingCert, o.intermediateCerts)
+	}
+
+	return sig
+}
+
+// signMultiCorim wraps the CoRIM in a COSE_Sign envelope with one signature per
+// signer.  The content type and corim-meta header parameters, which are common
+// to all signers, go in the body protected header, while alg, kid and x5chain
+// go in the protected header of each signature.  As for signCorim, the encoded
+// payload is returned alongside the envelope.
+func signMultiCorim(
+	c *corim.UnsignedCorim, m *corim.Meta, signers []coseSigner, detached bool,
+) ([]byte, []byte, error) {
+	if err := c.Valid(); err != nil {
+		return nil, nil, fmt.Errorf("failed validation of unsigned CoRIM: %w", err)
+	}
+
+	payload, err := c.ToCBOR()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed CBOR encoding of unsigned CoRIM: %w", err)
+	}
+
+	metaCBOR, err := m.ToCBOR()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed CBOR encoding of CoRIM Meta: %w", err)
+	}
+
+	msg := cose.NewSignMessage()
+	msg.Payload = payload
+
+	msg.Headers.Protected[cose.HeaderLabelContentType] = corim.ContentType
+	msg.Headers.Protected[corim.HeaderLabelCorimMeta] = metaCBOR
+
+	coseSigners := make([]cose.Signer, 0, len(signers))
+	for _, o := range signers {
+		msg.Signatures = append(msg.Signatures, o.newCoseSignature())
+		coseSigners = append(coseSigners, o.signer)
+	}
+
+	if err = msg.Sign(rand.Reader, corim.NoExternalData, coseSigners...); err != nil {
+		return nil, nil, fmt.Errorf("COSE Sign signature failed: %w", err)
+	}
+
+	if detached {
+		msg.Payload = nil
+	}
+
+	signed, err := msg.MarshalCBOR()
+	if err != nil {
+		return nil, nil, fmt.Errorf("signed-corim marshaling failed: %w", err)
+	}
+
+	return signed, payload, nil
+}
+
+// cosignCorim appends a signature to a multi-signed CoRIM.  The payload must be
+// supplied if, and only if, the CoRIM has a detached payload.
+func cosignCorim(signed, payload []byte, o coseSigner) ([]byte, error) {
+	msg := cose.NewSignMessage()
+	if err := msg.UnmarshalCBOR(signed); err != nil {
+		return nil, err
+	}
+
+	detached := msg.Payload == nil
+	if detached {
+		msg.Payload = payload
+	}
+
+	protected, err := msg.Headers.MarshalProtected()
+	if err != nil {
+		return nil, err
+	}
+
+	sig := o.newCoseSignature()
+	if err = sig.Sign(rand.Reader, o.signer, protected, msg.Payload, corim.NoExternalData); err != nil {
+		return nil, fmt.Errorf("COSE Sign signature failed: %w", err)
+	}
+
+	msg.Signatures = append(msg.Signatures, sig)
+
+	if detached {
+		msg.Payload = nil
+	}
+
+	return msg.MarshalCBOR()
+}
+
+// multiSignedCorim is a CoRIM wrapped in a COSE_Sign envelope.  The corim
+// package only deals with COSE_Sign1, so the envelope is processed here, with
+// the header parameters placed as described in signMultiCorim.
+type multiSignedCorim struct {
+	UnsignedCorim corim.UnsignedCorim
+	Meta          corim.Meta
+	message       *cose.SignMessage
+}
+
+// FromCOSE decodes and validates the supplied COSE_Sign signed CoRIM, including
+// the embedded unsigned CoRIM and CoRIM Meta
+func (o *multiSignedCorim) FromCOSE(buf []byte) error {
+	o.message = cose.NewSignMessage()
+
+	if err := o.message.UnmarshalCBOR(buf); err != nil {
+		return fmt.Errorf("failed CBOR decoding for COSE-Sign signed CoRIM: %w", err)
+	}
+
+	if err := o.processHdrs(); err != nil {
+		return fmt.Errorf("processing COSE headers: %w", err)
+	}
+
+	if o.message.Payload == nil {
+		return errDetachedPayload
+	}
+
+	if err := o.UnsignedCorim.FromCBOR(o.message.Payload); err != nil {
+		return fmt.Errorf("failed CBOR decoding of unsigned CoRIM: %w", err)
+	}
+
+	if err := o.UnsignedCorim.Valid(); err != nil {
+		return fmt.Errorf("failed validation of unsigned CoRIM: %w", err)
+	}
+
+	return nil
+}
+
+func (o *multiSignedCorim) processHdrs() error {
+	hdr := o.message.Headers.Protected
+
+	v, ok := hdr[cose.HeaderLabelContentType]
+	if !ok {
+		return errors.New("missing mandatory content type")
+	}
+
+	if v != corim.ContentType {
+		return fmt.Errorf("expecting content type %q, got %q instead", corim.ContentType, v)
+	}
+
+	v, ok = hdr[corim.HeaderLabelCorimMeta]
+	if !ok {
+		return errors.New("missing mandatory corim.meta")
+	}
+
+	metaCBOR, ok := v.([]byte)
+	if !ok {
+		return fmt.Errorf("expecting CBOR-encoded CoRIM Meta, got %T instead", v)
+	}
+
+	if err := o.Meta.FromCBOR(metaCBOR); err != nil {
+		return fmt.Errorf("unable to decode CoRIM Meta: %w", err)
+	}
+
+	return nil
+}
+
+// Signatures returns the signatures of the CoRIM, in order
+func (o *multiSignedCorim) Signatures() ([]*corimSignature, error) {
+	protected, err := o.message.Headers.MarshalProtected()
+	if err != nil {
+		return nil, err
+	}
+
+	var sigs []*corimSignature
+
+	for i, sig := range o.message.Signatures {
+		cs := &corimSignature{
+			kid:            keyID(sig.Headers),
+			signature:      sig.Signature,
+			timestampToken: timestampTokenFromHeaders(sig.Headers),
+		}
+
+		if v, ok := sig.Headers.Protected[cose.HeaderLabelX5Chain]; ok {
+			if cs.signingCert, cs.intermediateCerts, err = parseX5Chain(v); err != nil {
+				return nil, fmt.Errorf("signature #%d: %w", i, err)
+			}
+		}
+
+		sig := sig
+		cs.verify = func(pk crypto.PublicKey) error {
+			alg, err := sig.Headers.Protected.Algorithm()
+			if err != nil {
+				return fmt.Errorf("unable to get verification algorithm: %w", err)
+			}
+
+			verifier, err := cose.NewVerifier(alg, pk)
+			if err != nil {
+				return fmt.Errorf("unable to instantiate verifier: %w", err)
+			}
+
+			return sig.Verify(verifier, protected, o.message.Payload, corim.NoExternalData)
+		}
+
+		sigs = append(sigs, cs)
+	}
+
+	return sigs, nil
+}
+
+var errDetachedPayload = errors.New("the COSE payload is detached")
+
+// corimSignature is a single signature over a CoRIM, i.e., the one in a
+// COSE_Sign1 or one of those in a COSE_Sign, along with the key identifier and
+// the certificates and timestamp token found in its headers
+type corimSignature struct {
+	kid               []byte
+	signingCert       *x509.Certificate
+	intermediateCerts []*x509.Certificate
+	signature         []byte
+	timestampToken    []byte
+	verify            func(pk crypto.PublicKey) error
+
+	// set once the timestamp token has been verified
+	timestamp *timestamp
+}
+
+// String identifies the signer by key identifier or, failing that, by the
+// subject of its signing certificate
+func (o *corimSignature) String() string {
+	switch {
+	case len(o.kid) != 0:
+		return fmt.Sprintf("kid %q", o.kid)
+	case o.signingCert != nil:
+		return fmt.Sprintf("subject %q", o.signingCert.Subject.String())
+	default:
+		return "anonymous"
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/veraison/swid"
+)
+
+var (
+	coswidCreateFiles     []string
+	coswidCreateDirs      []string
+	coswidCreateOutputDir string
+)
+
+var coswidCreateCmd = NewCoswidCreateCmd()
+
+func NewCoswidCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "create one or more CBOR-encoded CoSWID(s) from the supplied JSON or YAML template(s)",
+		Long: `create one or more CBOR-encoded CoSWID(s) from the supplied JSON or YAML template(s)
+
+	Create CoSWIDs from templates t1.json and t2.json, plus any template found in
+	the templates/ directory.  Save them to the current working directory.
+
+		cocli coswid create --template=t1.json \
+	    			--template=t2.json \
+	    			--template-dir=templates
+
+	Create one CoSWID from template t3.json and save it to the coswids/
+	directory.  Note that the output directory must exist.
+
+		cocli coswid create --template=t3.json --output-dir=coswids
+
+	Templates can be written in JSON (.json) or YAML (.yaml, .yml), which
+	allows comments.  YAML templates have the same structure as JSON ones.
+
+	Note: since the output file is deterministically generated from the template
+	file name, all the template file names (when from different directories)
+	MUST be different.
+	`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkCoswidCreateArgs(); err != nil {
+				return err
+			}
+
+			filesList := templateFilesList(coswidCreateFiles, coswidCreateDirs)
+			if len(filesList) == 0 {
+				return errors.New("no files found")
+			}
+
+			errs := 0
+			for _, tmplFile := range filesList {
+				cborFile, err := coswidTemplateToCBOR(tmplFile, coswidCreateOutputDir)
+				if err != nil {
+					fmt.Printf(">> creation failed for %q: %v\n", cborFile, err)
+					errs++
+					continue
+				}
+				fmt.Printf(">> created %q from %q\n", cborFile, tmplFile)
+			}
+
+			if errs != 0 {
+				return fmt.Errorf("%d/%d creations(s) failed", errs, len(filesList))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(
+		&coswidCreateFiles, "template", "t", []string{}, "a CoSWID template file (in JSON or YAML format)",
+	)
+
+	cmd.Flags().StringArrayVarP(
+		&coswidCreateDirs, "template-dir", "T", []string{}, "a directory containing CoSWID template files",
+	)
+
+	cmd.Flags().StringVarP(
+		&coswidCreateOutputDir, "output-dir", "o", ".", "directory where the created files are stored",
+	)
+
+	return cmd
+}
+
+func checkCoswidCreateArgs() error {
+	if len(coswidCreateFiles) == 0 && len(coswidCreateDirs) == 0 {
+		return errors.New("no templates supplied")
+	}
+	return nil
+}
+
+func coswidTemplateToCBOR(tmplFile, outputDir string) (string, error) {
+	var (
+		tmplData, cborData []byte
+		cborFile           string
+		s                  swid.SoftwareIdentity
+		err                error
+	)
+
+	if tmplData, err = afero.ReadFile(fs, tmplFile); err != nil {
+		return "", fmt.Errorf("error loading template from %s: %w", tmplFile, err)
+	}
+
+	if err = decodeTemplate(&s, tmplData, tmplFile); err != nil {
+		return "", fmt.Errorf("error decoding template from %s: %w", tmplFile, err)
+	}
+
+	if err = validCoswid(&s); err != nil {
+		return "", fmt.Errorf("error validating template %s: %w", tmplFile, err)
+	}
+
+	cborData, err = s.ToCBOR()
+	if err != nil {
+		return "", fmt.Errorf("error encoding template %s to CBOR: %w", tmplFile, err)
+	}
+
+	cborFile = makeFileName(outputDir, tmplFile, ".cbor")
+
+	err = afero.WriteFile(fs, cborFile, cborData, 0644)
+	if err != nil {
+		return "", fmt.Errorf("error saving CBOR file %s: %w", cborFile, err)
+	}
+
+	return cborFile, nil
+}
+
+func init() {
+	coswidCmd.AddCommand(coswidCreateCmd)
+}
@@ -0,0 +1,127 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CoswidCreateCmd_unknown_argument(t *testing.T) {
+	cmd := NewCoswidCreateCmd()
+
+	args := []string{"--unknown-argument=val"}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "unknown flag: --unknown-argument")
+}
+
+func Test_CoswidCreateCmd_no_templates(t *testing.T) {
+	cmd := NewCoswidCreateCmd()
+
+	// no args
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "no templates supplied")
+}
+
+func Test_CoswidCreateCmd_no_files_found(t *testing.T) {
+	cmd := NewCoswidCreateCmd()
+
+	args := []string{
+		"--template=unknown",
+		"--template-dir=unsure",
+	}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "no files found")
+}
+
+func Test_CoswidCreateCmd_template_with_invalid_json(t *testing.T) {
+	var err error
+
+	cmd := NewCoswidCreateCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "invalid.json", []byte("..."), 0644)
+	require.NoError(t, err)
+
+	args := []string{
+		"--template=invalid.json",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, "1/1 creations(s) failed")
+}
+
+func Test_CoswidCreateCmd_template_with_invalid_coswid(t *testing.T) {
+	var err error
+
+	cmd := NewCoswidCreateCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "bad-coswid.json", []byte(`{"tag-id": "x"}`), 0644)
+	require.NoError(t, err)
+
+	args := []string{
+		"--template=bad-coswid.json",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, "1/1 creations(s) failed")
+}
+
+func Test_CoswidCreateCmd_template_from_file_to_default_dir(t *testing.T) {
+	var err error
+
+	cmd := NewCoswidCreateCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "ok.json", testCoswidTemplate, 0644)
+	require.NoError(t, err)
+
+	args := []string{
+		"--template=ok.json",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+
+	expectedFileName := "ok.cbor"
+
+	_, err = fs.Stat(expectedFileName)
+	assert.NoError(t, err)
+}
+
+func Test_CoswidCreateCmd_template_from_dir_to_custom_dir(t *testing.T) {
+	var err error
+
+	cmd := NewCoswidCreateCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "testdir/ok.json", testCoswidTemplate, 0644)
+	require.NoError(t, err)
+
+	args := []string{
+		"--template-dir=testdir",
+		"--output-dir=testdir",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+
+	expectedFileName := "testdir/ok.cbor"
+
+	_, err = fs.Stat(expectedFileName)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,122 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var (
+	coswidDisplayFiles    []string
+	coswidDisplayDirs     []string
+	coswidDisplayFormat   *string
+	coswidDisplayAnnotate *bool
+)
+
+var coswidDisplayCmd = NewCoswidDisplayCmd()
+
+func NewCoswidDisplayCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "display",
+		Short: "display one or more CBOR-encoded CoSWID(s) in human readable (JSON) format",
+		Long: `display one or more CBOR-encoded CoSWID(s) in human readable (JSON) format.
+	You can supply individual CoSWID files or directories containing CoSWID files.
+
+	Display CoSWID in file c.cbor.
+
+	  cocli coswid display --file=c.cbor
+
+	Display CoSWIDs in files c1.cbor, c2.cbor and any cbor file in the coswids/
+	directory.
+	
+	  cocli coswid display --file=c1.cbor --file=c2.cbor --dir=coswids
+
+	Display CoSWID in file c.cbor as YAML, or as a one-line summary table
+
+	  cocli coswid display --file=c.cbor --format=yaml
+	  cocli coswid display --file=c.cbor --format=table
+
+	Display CoSWID in file c.cbor in CBOR extended diagnostic notation (EDN),
+	with map keys annotated with their CDDL names
+
+	  cocli coswid display --file=c.cbor --format=edn --annotate
+	`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkCoswidDisplayArgs(); err != nil {
+				return err
+			}
+
+			filesList := filesList(coswidDisplayFiles, coswidDisplayDirs, ".cbor")
+			if len(filesList) == 0 {
+				return errors.New("no files found")
+			}
+
+			errs := 0
+			for _, file := range filesList {
+				if err := displayCoswidFile(file, *coswidDisplayFormat, *coswidDisplayAnnotate); err != nil {
+					fmt.Printf(">> failed displaying %q: %v\n", file, err)
+					errs++
+					continue
+				}
+			}
+
+			if errs != 0 {
+				return fmt.Errorf("%d/%d display(s) failed", errs, len(filesList))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(
+		&coswidDisplayFiles, "file", "f", []string{}, "a CoSWID file (in CBOR format)",
+	)
+
+	cmd.Flags().StringArrayVarP(
+		&coswidDisplayDirs, "dir", "d", []string{}, "a directory containing CoSWID files (in CBOR format)",
+	)
+
+	coswidDisplayFormat = cmd.Flags().String(
+		"format", displayFormatJSON, "output format: json, yaml, table or edn",
+	)
+	coswidDisplayAnnotate = cmd.Flags().Bool(
+		"annotate", false, "annotate map keys with their CDDL names (edn format only)",
+	)
+
+	return cmd
+}
+
+func displayCoswidFile(file, format string, annotate bool) error {
+	var (
+		data []byte
+		err  error
+	)
+
+	if data, err = afero.ReadFile(fs, file); err != nil {
+		return fmt.Errorf("error loading CoSWID from %s: %w", file, err)
+	}
+
+	// use file name as heading
+	if format == displayFormatEDN {
+		return printEDN(data, ednCoswid, annotate, ">> ["+file+"]")
+	}
+
+	return printCoswid(data, format, ">> ["+file+"]")
+}
+
+func checkCoswidDisplayArgs() error {
+	if len(coswidDisplayFiles) == 0 && len(coswidDisplayDirs) == 0 {
+		return errors.New("no files supplied")
+	}
+
+	return checkDisplayFormat(*coswidDisplayFormat, *coswidDisplayAnnotate)
+}
+
+func init() {
+	coswidCmd.AddCommand(coswidDisplayCmd)
+}
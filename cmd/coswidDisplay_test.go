@@ -0,0 +1,131 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/swid"
+)
+
+func Test_CoswidDisplayCmd_unknown_argument(t *testing.T) {
+	cmd := NewCoswidDisplayCmd()
+
+	args := []string{"--unknown-argument=val"}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "unknown flag: --unknown-argument")
+}
+
+func Test_CoswidDisplayCmd_no_files(t *testing.T) {
+	cmd := NewCoswidDisplayCmd()
+
+	// no args
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "no files supplied")
+}
+
+func Test_CoswidDisplayCmd_no_files_found(t *testing.T) {
+	cmd := NewCoswidDisplayCmd()
+
+	args := []string{
+		"--file=unknown",
+		"--dir=unsure",
+	}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "no files found")
+}
+
+func Test_CoswidDisplayCmd_file_with_invalid_cbor(t *testing.T) {
+	var err error
+
+	cmd := NewCoswidDisplayCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "invalid.cbor", []byte{0xff, 0xff}, 0400)
+	require.NoError(t, err)
+
+	args := []string{
+		"--file=invalid.cbor",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, "1/1 display(s) failed")
+}
+
+func Test_CoswidDisplayCmd_file_with_valid_coswid(t *testing.T) {
+	var err error
+
+	cmd := NewCoswidDisplayCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "ok.cbor", testCoswid, 0400)
+	require.NoError(t, err)
+
+	args := []string{
+		"--file=ok.cbor",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+}
+
+func Test_CoswidDisplayCmd_file_with_valid_coswid_from_dir(t *testing.T) {
+	var err error
+
+	cmd := NewCoswidDisplayCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "testdir/ok.cbor", testCoswid, 0400)
+	require.NoError(t, err)
+
+	args := []string{
+		"--dir=testdir",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+}
+
+func Test_CoswidDisplayCmd_bad_format(t *testing.T) {
+	cmd := NewCoswidDisplayCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--format=xml",
+	}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, `invalid --format "xml": expecting "json", "yaml", "table" or "edn"`)
+}
+
+func Test_CoswidDisplayCmd_file_with_valid_coswid_all_formats(t *testing.T) {
+	var s swid.SoftwareIdentity
+	require.NoError(t, s.FromJSON(testCoswidTemplate))
+
+	data, err := s.ToCBOR()
+	require.NoError(t, err)
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "ok.cbor", data, 0400))
+
+	for _, format := range []string{"json", "yaml", "table", "edn"} {
+		cmd := NewCoswidDisplayCmd()
+		cmd.SetArgs([]string{"--file=ok.cbor", "--format=" + format})
+
+		err = cmd.Execute()
+		assert.NoError(t, err, format)
+	}
+}
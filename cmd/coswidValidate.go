@@ -0,0 +1,137 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/veraison/swid"
+)
+
+var (
+	coswidValidateFiles []string
+	coswidValidateDirs  []string
+)
+
+var coswidValidateCmd = NewCoswidValidateCmd()
+
+func NewCoswidValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "validate one or more CBOR-encoded CoSWID(s)",
+		Long: `validate one or more CBOR-encoded CoSWID(s)
+
+	Validate CoSWID in file s.cbor.
+
+	  cocli coswid validate --file=s.cbor
+
+	Validate CoSWIDs in files s1.cbor, s2.cbor and any cbor file in the coswids/
+	directory.
+
+	  cocli coswid validate --file=s1.cbor --file=s2.cbor --dir=coswids
+	`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkCoswidValidateArgs(); err != nil {
+				return err
+			}
+
+			filesList := filesList(coswidValidateFiles, coswidValidateDirs, ".cbor")
+			if len(filesList) == 0 {
+				return errors.New("no files found")
+			}
+
+			errs := 0
+			for _, file := range filesList {
+				err := validateCoswid(file)
+				if err != nil {
+					fmt.Printf("[invalid] %q: %v\n", file, err)
+					errs++
+					continue
+				}
+				fmt.Printf("[valid] %q\n", file)
+			}
+
+			if errs != 0 {
+				return fmt.Errorf("%d/%d validation(s) failed", errs, len(filesList))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(
+		&coswidValidateFiles, "file", "f", []string{}, "a CoSWID file (in CBOR format)",
+	)
+
+	cmd.Flags().StringArrayVarP(
+		&coswidValidateDirs, "dir", "d", []string{}, "a directory containing CoSWID files (in CBOR format)",
+	)
+
+	return cmd
+}
+
+func validateCoswid(file string) error {
+	var (
+		data []byte
+		err  error
+		s    swid.SoftwareIdentity
+	)
+
+	if data, err = afero.ReadFile(fs, file); err != nil {
+		return fmt.Errorf("error loading CoSWID from %s: %w", file, err)
+	}
+
+	if err = s.FromCBOR(data); err != nil {
+		return fmt.Errorf("error decoding CoSWID from %s: %w", file, err)
+	}
+
+	if err = validCoswid(&s); err != nil {
+		return fmt.Errorf("error validating CoSWID %s: %w", file, err)
+	}
+
+	return nil
+}
+
+// validCoswid checks the mandatory fields of a CoSWID (RFC 9393, Section 2.3).
+// The swid package does not offer a validation interface of its own (see
+// https://github.com/veraison/swid/issues/23), so this is done here.
+func validCoswid(s *swid.SoftwareIdentity) error {
+	if s.TagID == (swid.TagID{}) {
+		return errors.New("missing mandatory tag-id")
+	}
+
+	if s.SoftwareName == "" {
+		return errors.New("missing mandatory software-name")
+	}
+
+	if len(s.Entities) == 0 {
+		return errors.New("no entities present, must have at least 1 entity")
+	}
+
+	for i, e := range s.Entities {
+		if e.EntityName == "" {
+			return fmt.Errorf("entity at index %d: missing mandatory entity-name", i)
+		}
+	}
+
+	if s.Payload != nil && s.Evidence != nil {
+		return errors.New("payload and evidence are mutually exclusive")
+	}
+
+	return nil
+}
+
+func checkCoswidValidateArgs() error {
+	if len(coswidValidateFiles) == 0 && len(coswidValidateDirs) == 0 {
+		return errors.New("no files supplied")
+	}
+	return nil
+}
+
+func init() {
+	coswidCmd.AddCommand(coswidValidateCmd)
+}
@@ -0,0 +1,116 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CoswidValidateCmd_unknown_argument(t *testing.T) {
+	cmd := NewCoswidValidateCmd()
+
+	args := []string{"--unknown-argument=val"}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "unknown flag: --unknown-argument")
+}
+
+func Test_CoswidValidateCmd_no_files(t *testing.T) {
+	cmd := NewCoswidValidateCmd()
+
+	// no args
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "no files supplied")
+}
+
+func Test_CoswidValidateCmd_no_files_found(t *testing.T) {
+	cmd := NewCoswidValidateCmd()
+
+	args := []string{
+		"--file=unknown",
+		"--dir=unsure",
+	}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "no files found")
+}
+
+func Test_CoswidValidateCmd_file_with_invalid_cbor(t *testing.T) {
+	var err error
+
+	cmd := NewCoswidValidateCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "invalid.cbor", []byte{0xff, 0xff}, 0400)
+	require.NoError(t, err)
+
+	args := []string{
+		"--file=invalid.cbor",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, "1/1 validation(s) failed")
+}
+
+func Test_CoswidValidateCmd_file_with_invalid_coswid(t *testing.T) {
+	var err error
+
+	cmd := NewCoswidValidateCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "bad-coswid.cbor", invalidCoswid, 0400)
+	require.NoError(t, err)
+
+	args := []string{
+		"--file=bad-coswid.cbor",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, "1/1 validation(s) failed")
+}
+
+func Test_CoswidValidateCmd_file_with_valid_coswid(t *testing.T) {
+	var err error
+
+	cmd := NewCoswidValidateCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "ok.cbor", testCoswid, 0400)
+	require.NoError(t, err)
+
+	args := []string{
+		"--file=ok.cbor",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+}
+
+func Test_CoswidValidateCmd_file_with_valid_coswid_from_dir(t *testing.T) {
+	var err error
+
+	cmd := NewCoswidValidateCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "testdir/ok.cbor", testCoswid, 0400)
+	require.NoError(t, err)
+
+	args := []string{
+		"--dir=testdir",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+}
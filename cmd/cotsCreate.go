@@ -98,9 +98,9 @@ func NewCotsCreateCtsCmd() *cobra.Command {
 	cotsCreateTagUUID = cmd.Flags().BoolP("uuid", "", false, "boolean indicating a random UUID value should be used as tag ID (mutually exclusive from --id and --uuid-str)")
 	cotsCreateTagID = cmd.Flags().StringP("id", "", "", "string value containing a tag ID value (mutually exclusive from --uuid and --uuid-str)")
 	cotsCreateTagVersion = cmd.Flags().UintP("tag-version", "", 0, "integer value indicating version of tag identity (ignored if neither --uuid nor --id are supplied)")
-	cotsCreateCtsEnvFile = cmd.Flags().StringP("environment", "e", "", "an environment template file (in JSON format)")
-	cotsCreateCtsPermClaimsFile = cmd.Flags().StringP("permclaims", "p", "", "a permitted claims template file (in JSON format)")
-	cotsCreateCtsExclClaimsFile = cmd.Flags().StringP("exclclaims", "x", "", "an excluded claims template file (in JSON format)")
+	cotsCreateCtsEnvFile = cmd.Flags().StringP("environment", "e", "", "an environment template file (in JSON or YAML format)")
+	cotsCreateCtsPermClaimsFile = cmd.Flags().StringP("permclaims", "p", "", "a permitted claims template file (in JSON or YAML format)")
+	cotsCreateCtsExclClaimsFile = cmd.Flags().StringP("exclclaims", "x", "", "an excluded claims template file (in JSON or YAML format)")
 
 	cmd.Flags().StringArrayVarP(
 		&cotsCreateCtsPurposes, "purpose", "u", []string{}, "string value indicating purpose: cots,corim,comid,coswid,eat,certificate",
@@ -169,7 +169,7 @@ func ctsTemplateToCBOR(language string, tagID string, genUUID bool, uuidStr stri
 		return "", fmt.Errorf("error loading template from %s: %w", envFile, err)
 	}
 
-	if err = env.FromJSON(envData); err != nil {
+	if err = decodeTemplate(&env, envData, envFile); err != nil {
 		return "", fmt.Errorf("error decoding template from %s: %w", envFile, err)
 	}
 
@@ -196,7 +196,7 @@ func ctsTemplateToCBOR(language string, tagID string, genUUID bool, uuidStr stri
 			return "", fmt.Errorf("error loading template from %s: %w", permClaimsFile, err)
 		}
 
-		if err = permClaims.FromJSON(permClaimsData); err != nil {
+		if err = decodeTemplate(&permClaims, permClaimsData, permClaimsFile); err != nil {
 			return "", fmt.Errorf("error decoding template from %s: %w", permClaimsFile, err)
 		}
 		cts.AddPermClaims(&permClaims)
@@ -206,7 +206,7 @@ func ctsTemplateToCBOR(language string, tagID string, genUUID bool, uuidStr stri
 			return "", fmt.Errorf("error loading template from %s: %w", exclClaimsFile, err)
 		}
 
-		if err = exclClaims.FromJSON(exclClaimsData); err != nil {
+		if err = decodeTemplate(&exclClaims, exclClaimsData, exclClaimsFile); err != nil {
 			return "", fmt.Errorf("error decoding template from %s: %w", exclClaimsFile, err)
 		}
 		cts.AddExclClaims(&exclClaims)
@@ -4,10 +4,12 @@
 package cmd
 
 import (
+	"os"
 	"testing"
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_CotsCreateCtsCmd_unknown_argument(t *testing.T) {
@@ -144,3 +146,33 @@ func Test_CotsCreateCtsCmd_ok(t *testing.T) {
 	err := cmd.Execute()
 	assert.Nil(t, err)
 }
+
+func Test_CotsCreateCtsCmd_yaml_environment(t *testing.T) {
+	ta, err := os.ReadFile("../data/cots/shared_ta.ta")
+	require.NoError(t, err)
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "shared_ta.ta", ta, 0644))
+	require.NoError(t, afero.WriteFile(fs, "vendor.yaml", []byte("- environment:\n    class:\n      vendor: Zesty Hands, Inc.\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "bad.yaml", []byte("- environment:\n    class:\n      vendor: [1]\n"), 0644))
+
+	cmd := NewCotsCreateCtsCmd()
+	cmd.SetArgs([]string{
+		"--output=output.cbor",
+		"--environment=vendor.yaml",
+		"--tafile=shared_ta.ta",
+	})
+	require.NoError(t, cmd.Execute())
+
+	_, err = fs.Stat("output.cbor")
+	assert.NoError(t, err)
+
+	cmd = NewCotsCreateCtsCmd()
+	cmd.SetArgs([]string{
+		"--output=output.cbor",
+		"--environment=bad.yaml",
+		"--tafile=shared_ta.ta",
+	})
+	err = cmd.Execute()
+	assert.ErrorContains(t, err, "error decoding template from bad.yaml: line 3: ")
+}
@@ -12,8 +12,10 @@ import (
 )
 
 var (
-	cotsDisplayFiles []string
-	cotsDisplayDirs  []string
+	cotsDisplayFiles    []string
+	cotsDisplayDirs     []string
+	cotsDisplayFormat   *string
+	cotsDisplayAnnotate *bool
 )
 
 var cotsDisplayCmd = NewCotsDisplayCmd()
@@ -29,6 +31,15 @@ func NewCotsDisplayCmd() *cobra.Command {
 	
 	  cocli cots display --file=cots.cbor
 
+	Display a summary of the trust anchors in cots.cbor, with a row per trust
+	anchor (subject, public key and expiry)
+
+	  cocli cots display --file=cots.cbor --format=table
+
+	Display CoTS in cots.cbor in CBOR extended diagnostic notation (EDN), with
+	map keys annotated with their CDDL names
+
+	  cocli cots display --file=cots.cbor --format=edn --annotate
 	`,
 
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -43,7 +54,7 @@ func NewCotsDisplayCmd() *cobra.Command {
 
 			errs := 0
 			for _, file := range filesList {
-				if err := displayCotsFile(file); err != nil {
+				if err := displayCotsFile(file, *cotsDisplayFormat, *cotsDisplayAnnotate); err != nil {
 					fmt.Printf(">> failed displaying %q: %v\n", file, err)
 					errs++
 					continue
@@ -65,10 +76,17 @@ func NewCotsDisplayCmd() *cobra.Command {
 		&cotsDisplayDirs, "dir", "d", []string{}, "a directory containing CoTS files (in CBOR format)",
 	)
 
+	cotsDisplayFormat = cmd.Flags().String(
+		"format", displayFormatJSON, "output format: json, yaml, table or edn",
+	)
+	cotsDisplayAnnotate = cmd.Flags().Bool(
+		"annotate", false, "annotate map keys with their CDDL names (edn format only)",
+	)
+
 	return cmd
 }
 
-func displayCotsFile(file string) error {
+func displayCotsFile(file, format string, annotate bool) error {
 	var (
 		data []byte
 		err  error
@@ -79,8 +97,11 @@ func displayCotsFile(file string) error {
 	}
 
 	// use file name as heading
-	return printCots(data, ">> ["+file+"]")
+	if format == displayFormatEDN {
+		return printEDN(data, ednCots, annotate, ">> ["+file+"]")
+	}
 
+	return printCots(data, format, ">> ["+file+"]")
 }
 
 func checkCotsDisplayArgs() error {
@@ -88,7 +109,7 @@ func checkCotsDisplayArgs() error {
 		return errors.New("no files supplied")
 	}
 
-	return nil
+	return checkDisplayFormat(*cotsDisplayFormat, *cotsDisplayAnnotate)
 }
 
 func init() {
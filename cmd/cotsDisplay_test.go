@@ -99,3 +99,55 @@ func Test_CotsDisplayCmd_file_with_valid_cots_from_dir(t *testing.T) {
 	err = cmd.Execute()
 	assert.NoError(t, err)
 }
+
+func Test_CotsDisplayCmd_bad_format(t *testing.T) {
+	cmd := NewCotsDisplayCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--format=xml",
+	}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, `invalid --format "xml": expecting "json", "yaml", "table" or "edn"`)
+}
+
+func Test_CotsDisplayCmd_file_with_valid_cots_edn(t *testing.T) {
+	var err error
+
+	cmd := NewCotsDisplayCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "ok.cbor", testCots, 0400)
+	require.NoError(t, err)
+
+	args := []string{
+		"--file=ok.cbor",
+		"--format=edn",
+		"--annotate",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+}
+
+func Test_CotsDisplayCmd_file_with_valid_cots_table(t *testing.T) {
+	var err error
+
+	cmd := NewCotsDisplayCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "ok.cbor", testCots, 0400)
+	require.NoError(t, err)
+
+	args := []string{
+		"--file=ok.cbor",
+		"--format=table",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,236 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/veraison/corim/cots"
+)
+
+var (
+	cotsValidateFiles []string
+	cotsValidateDirs  []string
+)
+
+var cotsValidateCmd = NewCotsValidateCmd()
+
+func NewCotsValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "validate one or more CBOR-encoded CoTS(s)",
+		Long: `validate one or more CBOR-encoded CoTS(s)
+
+	Besides the structural checks, make sure that each trust anchor can be
+	parsed according to its declared format (certificate, SPKI or
+	TrustAnchorInfo) and that each CA is a parseable X.509 certificate.
+
+	Validate CoTS in file cots.cbor.
+
+	  cocli cots validate --file=cots.cbor
+
+	Validate CoTSs in files c1.cbor, c2.cbor and any cbor file in the cots/
+	directory.
+
+	  cocli cots validate --file=c1.cbor --file=c2.cbor --dir=cots
+	`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkCotsValidateArgs(); err != nil {
+				return err
+			}
+
+			filesList := filesList(cotsValidateFiles, cotsValidateDirs, ".cbor")
+			if len(filesList) == 0 {
+				return errors.New("no files found")
+			}
+
+			errs := 0
+			for _, file := range filesList {
+				err := validateCotsFile(file)
+				if err != nil {
+					fmt.Printf("[invalid] %q: %v\n", file, err)
+					errs++
+					continue
+				}
+				fmt.Printf("[valid] %q\n", file)
+			}
+
+			if errs != 0 {
+				return fmt.Errorf("%d/%d validation(s) failed", errs, len(filesList))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(
+		&cotsValidateFiles, "file", "f", []string{}, "a CoTS file (in CBOR format)",
+	)
+
+	cmd.Flags().StringArrayVarP(
+		&cotsValidateDirs, "dir", "d", []string{}, "a directory containing CoTS files (in CBOR format)",
+	)
+
+	return cmd
+}
+
+func validateCotsFile(file string) error {
+	var (
+		data []byte
+		err  error
+		t    cots.ConciseTaStore
+	)
+
+	if data, err = afero.ReadFile(fs, file); err != nil {
+		return fmt.Errorf("error loading CoTS from %s: %w", file, err)
+	}
+
+	if err = t.FromCBOR(data); err != nil {
+		return fmt.Errorf("error decoding CoTS from %s: %w", file, err)
+	}
+
+	if err = validCots(&t); err != nil {
+		return fmt.Errorf("error validating CoTS %s: %w", file, err)
+	}
+
+	return nil
+}
+
+// validCots runs the structural validation provided by the cots package and
+// then checks that the trust anchors and CA certificates are parseable
+func validCots(t *cots.ConciseTaStore) error {
+	if err := t.Valid(); err != nil {
+		return err
+	}
+
+	for i, ta := range t.Keys.Tas {
+		if _, _, err := parseTrustAnchor(ta); err != nil {
+			return fmt.Errorf("trust anchor at index %d: %w", i, err)
+		}
+	}
+
+	for i, ca := range t.Keys.Cas {
+		if _, err := x509.ParseCertificate(ca); err != nil {
+			return fmt.Errorf("CA at index %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// parseTrustAnchor decodes the trust anchor data according to its declared
+// format and returns the public key.  If the trust anchor is a certificate,
+// the parsed certificate is also returned.
+func parseTrustAnchor(ta cots.TrustAnchor) (crypto.PublicKey, *x509.Certificate, error) {
+	switch ta.Format {
+	case cots.TaFormatCertificate:
+		cert, err := x509.ParseCertificate(ta.Data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid certificate: %w", err)
+		}
+		return cert.PublicKey, cert, nil
+	case cots.TaFormatSubjectPublicKeyInfo:
+		pk, err := x509.ParsePKIXPublicKey(ta.Data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid SPKI: %w", err)
+		}
+		return pk, nil, nil
+	case cots.TaFormatTrustAnchorInfo:
+		pk, err := parseTrustAnchorInfo(ta.Data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid TrustAnchorInfo: %w", err)
+		}
+		return pk, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown trust anchor format %d", ta.Format)
+	}
+}
+
+// parseTrustAnchorInfo extracts the public key from a DER-encoded
+// TrustAnchorInfo (RFC 5914), optionally wrapped in the taInfo alternative of
+// TrustAnchorChoice:
+//
+//	TrustAnchorChoice ::= CHOICE {
+//	  certificate Certificate,
+//	  tbsCert     [1] EXPLICIT TBSCertificate,
+//	  taInfo      [2] EXPLICIT TrustAnchorInfo }
+//
+//	TrustAnchorInfo ::= SEQUENCE {
+//	  version  TrustAnchorInfoVersion DEFAULT v1,
+//	  pubKey   SubjectPublicKeyInfo,
+//	  keyId    KeyIdentifier,
+//	  ... }
+func parseTrustAnchorInfo(data []byte) (crypto.PublicKey, error) {
+	var (
+		outer, tai, elem asn1.RawValue
+		rest             []byte
+		err              error
+	)
+
+	if rest, err = asn1.Unmarshal(data, &outer); err != nil {
+		return nil, err
+	}
+
+	if len(rest) != 0 {
+		return nil, errors.New("trailing data")
+	}
+
+	switch {
+	case outer.Class == asn1.ClassContextSpecific && outer.Tag == 2:
+		if _, err = asn1.Unmarshal(outer.Bytes, &tai); err != nil {
+			return nil, err
+		}
+	case outer.Class == asn1.ClassUniversal && outer.Tag == asn1.TagSequence:
+		tai = outer
+	default:
+		return nil, fmt.Errorf("unexpected ASN.1 tag %d (class %d)", outer.Tag, outer.Class)
+	}
+
+	if tai.Class != asn1.ClassUniversal || tai.Tag != asn1.TagSequence {
+		return nil, errors.New("expecting a SEQUENCE")
+	}
+
+	rest = tai.Bytes
+
+	if rest, err = asn1.Unmarshal(rest, &elem); err != nil {
+		return nil, fmt.Errorf("decoding version or pubKey: %w", err)
+	}
+
+	// skip the optional version
+	if elem.Class == asn1.ClassUniversal && elem.Tag == asn1.TagInteger {
+		if rest, err = asn1.Unmarshal(rest, &elem); err != nil {
+			return nil, fmt.Errorf("decoding pubKey: %w", err)
+		}
+	}
+
+	pk, err := x509.ParsePKIXPublicKey(elem.FullBytes)
+	if err != nil {
+		return nil, fmt.Errorf("decoding pubKey: %w", err)
+	}
+
+	var keyID []byte
+	if _, err = asn1.Unmarshal(rest, &keyID); err != nil {
+		return nil, fmt.Errorf("decoding keyId: %w", err)
+	}
+
+	return pk, nil
+}
+
+func checkCotsValidateArgs() error {
+	if len(cotsValidateFiles) == 0 && len(cotsValidateDirs) == 0 {
+		return errors.New("no files supplied")
+	}
+
+	return nil
+}
+
+func init() {
+	cotsCmd.AddCommand(cotsValidateCmd)
+}
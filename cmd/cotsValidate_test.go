@@ -0,0 +1,182 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/corim/cots"
+)
+
+func Test_CotsValidateCmd_unknown_argument(t *testing.T) {
+	cmd := NewCotsValidateCmd()
+
+	args := []string{"--unknown-argument=val"}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "unknown flag: --unknown-argument")
+}
+
+func Test_CotsValidateCmd_no_files(t *testing.T) {
+	cmd := NewCotsValidateCmd()
+
+	// no args
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "no files supplied")
+}
+
+func Test_CotsValidateCmd_no_files_found(t *testing.T) {
+	cmd := NewCotsValidateCmd()
+
+	args := []string{
+		"--file=unknown",
+		"--dir=unsure",
+	}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "no files found")
+}
+
+func Test_CotsValidateCmd_file_with_invalid_cbor(t *testing.T) {
+	var err error
+
+	cmd := NewCotsValidateCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "invalid.cbor", []byte{0xff, 0xff}, 0400)
+	require.NoError(t, err)
+
+	args := []string{
+		"--file=invalid.cbor",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, "1/1 validation(s) failed")
+}
+
+func Test_CotsValidateCmd_file_with_invalid_cots(t *testing.T) {
+	var err error
+
+	cmd := NewCotsValidateCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "bad-cots.cbor", invalidCots, 0400)
+	require.NoError(t, err)
+
+	args := []string{
+		"--file=bad-cots.cbor",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, "1/1 validation(s) failed")
+}
+
+func Test_CotsValidateCmd_file_with_valid_cots(t *testing.T) {
+	var err error
+
+	cmd := NewCotsValidateCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "ok.cbor", testCots, 0400)
+	require.NoError(t, err)
+
+	args := []string{
+		"--file=ok.cbor",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+}
+
+func Test_CotsValidateCmd_file_with_valid_cots_from_dir(t *testing.T) {
+	var err error
+
+	cmd := NewCotsValidateCmd()
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "testdir/ok.cbor", testCots, 0400)
+	require.NoError(t, err)
+
+	args := []string{
+		"--dir=testdir",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.NoError(t, err)
+}
+
+func Test_CotsValidateCmd_file_with_unparseable_ta(t *testing.T) {
+	var err error
+
+	cmd := NewCotsValidateCmd()
+
+	tas := cots.NewTasAndCas().AddTaCert([]byte("not a certificate"))
+	eg := cots.NewEnvironmentGroup().SetNamedTaStore("test store")
+	c := cots.NewConciseTaStore().AddEnvironmentGroup(*eg).SetKeys(*tas)
+
+	data, err := c.ToCBOR()
+	require.NoError(t, err)
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "bad-ta.cbor", data, 0400)
+	require.NoError(t, err)
+
+	args := []string{
+		"--file=bad-ta.cbor",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, "1/1 validation(s) failed")
+}
+
+func Test_CotsValidateCmd_file_with_unparseable_ca(t *testing.T) {
+	var err error
+
+	cmd := NewCotsValidateCmd()
+
+	var c cots.ConciseTaStore
+	err = c.FromCBOR(testCots)
+	require.NoError(t, err)
+
+	c.Keys.AddCaCert([]byte("not a certificate"))
+
+	data, err := c.ToCBOR()
+	require.NoError(t, err)
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "bad-ca.cbor", data, 0400)
+	require.NoError(t, err)
+
+	args := []string{
+		"--file=bad-ca.cbor",
+	}
+	cmd.SetArgs(args)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err, "1/1 validation(s) failed")
+}
+
+func Test_parseTrustAnchorInfo_ok(t *testing.T) {
+	var c cots.ConciseTaStore
+
+	err := c.FromCBOR(testCots)
+	require.NoError(t, err)
+
+	for _, ta := range c.Keys.Tas {
+		pk, _, err := parseTrustAnchor(ta)
+		assert.NoError(t, err)
+		assert.NotNil(t, pk)
+	}
+}
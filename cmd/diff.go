@@ -0,0 +1,302 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/veraison/corim/comid"
+)
+
+// kinds of difference between two versions of an item
+const (
+	diffAdded    = "added"
+	diffRemoved  = "removed"
+	diffModified = "modified"
+)
+
+// names of the CoMID triples that are compared item by item, i.e., measurement
+// by measurement for value triples, and key by key for key triples
+var (
+	diffValueTriples = []string{"reference-values", "endorsed-values"}
+	diffKeyTriples   = []string{"dev-identity-keys", "attester-verification-keys"}
+)
+
+// tripleDiff describes the difference in a measurement of a value triple, or a
+// key of a key triple, between two CoMIDs
+type tripleDiff struct {
+	Triple      string          `json:"triple"`
+	Change      string          `json:"change"`
+	Environment json.RawMessage `json:"environment"`
+	// measurement key, if any, for value triples; verification key for key
+	// triples
+	Key json.RawMessage `json:"key,omitempty"`
+	Old json.RawMessage `json:"old,omitempty"`
+	New json.RawMessage `json:"new,omitempty"`
+}
+
+// tripleItem is a single measurement of a value triple, or key of a key
+// triple, together with the environment it applies to
+type tripleItem struct {
+	triple string
+	env    json.RawMessage
+	key    json.RawMessage
+	val    json.RawMessage
+}
+
+// id identifies the item across CoMIDs, regardless of the position of the
+// triple it belongs to
+func (o tripleItem) id() string {
+	return strings.Join([]string{o.triple, string(o.env), string(o.key)}, "\x00")
+}
+
+// comidTripleItems flattens the value and key triples of the CoMID into a list
+// of items, in the order in which they appear.  The JSON encoding of the
+// triples is used, so that the items can be compared and reported without
+// looking into each type of environment, measurement and key.
+func comidTripleItems(c *comid.Comid) ([]tripleItem, error) {
+	triples, err := jsonFields(&c.Triples)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []tripleItem
+
+	for _, name := range diffValueTriples {
+		var vts []struct {
+			Environment  json.RawMessage              `json:"environment"`
+			Measurements []map[string]json.RawMessage `json:"measurements"`
+		}
+
+		if err := unmarshalField(triples, name, &vts); err != nil {
+			return nil, err
+		}
+
+		for _, vt := range vts {
+			for _, m := range vt.Measurements {
+				key := m["key"]
+				delete(m, "key")
+
+				val, err := json.Marshal(m)
+				if err != nil {
+					return nil, fmt.Errorf("JSON encoding of %s measurement failed: %w", name, err)
+				}
+
+				items = append(items, tripleItem{triple: name, env: vt.Environment, key: key, val: val})
+			}
+		}
+	}
+
+	for _, name := range diffKeyTriples {
+		var kts []struct {
+			Environment json.RawMessage   `json:"environment"`
+			VerifKeys   []json.RawMessage `json:"verification-keys"`
+		}
+
+		if err := unmarshalField(triples, name, &kts); err != nil {
+			return nil, err
+		}
+
+		for _, kt := range kts {
+			for _, k := range kt.VerifKeys {
+				items = append(items, tripleItem{triple: name, env: kt.Environment, key: k})
+			}
+		}
+	}
+
+	return items, nil
+}
+
+// diffComidTriples compares the value and key triples of two CoMIDs.
+// Measurements are aligned by environment and measurement key, keys by
+// environment and value.  Measurements without a key, or sharing the same key
+// in the same environment, are aligned in the order in which they appear.
+func diffComidTriples(a, b *comid.Comid) ([]tripleDiff, error) {
+	aItems, err := comidTripleItems(a)
+	if err != nil {
+		return nil, err
+	}
+
+	bItems, err := comidTripleItems(b)
+	if err != nil {
+		return nil, err
+	}
+
+	aKeys, aPositions := indexTripleItems(aItems)
+	bKeys, bPositions := indexTripleItems(bItems)
+
+	var diffs []tripleDiff
+
+	for i, item := range aItems {
+		j, ok := bPositions[aKeys[i]]
+		if !ok {
+			diffs = append(diffs, tripleDiff{
+				Triple: item.triple, Change: diffRemoved, Environment: item.env, Key: item.key, Old: item.val,
+			})
+			continue
+		}
+
+		if other := bItems[j]; !bytes.Equal(item.val, other.val) {
+			diffs = append(diffs, tripleDiff{
+				Triple: item.triple, Change: diffModified, Environment: item.env, Key: item.key,
+				Old: item.val, New: other.val,
+			})
+		}
+	}
+
+	for i, item := range bItems {
+		if _, ok := aPositions[bKeys[i]]; !ok {
+			diffs = append(diffs, tripleDiff{
+				Triple: item.triple, Change: diffAdded, Environment: item.env, Key: item.key, New: item.val,
+			})
+		}
+	}
+
+	return diffs, nil
+}
+
+// indexTripleItems returns the alignment key of each item, which includes the
+// number of previous items with the same id, so that repeated items are
+// aligned in order, and the position of each key
+func indexTripleItems(items []tripleItem) ([]string, map[string]int) {
+	seen := map[string]int{}
+	keys := make([]string, len(items))
+	positions := map[string]int{}
+
+	for i, item := range items {
+		id := item.id()
+		keys[i] = fmt.Sprintf("%s\x00%d", id, seen[id])
+		positions[keys[i]] = i
+		seen[id]++
+	}
+
+	return keys, positions
+}
+
+// diffComidFields returns the names of the top-level CoMID fields, other than
+// the value and key triples compared by diffComidTriples, that differ between
+// the two CoMIDs
+func diffComidFields(a, b *comid.Comid) ([]string, error) {
+	fields, err := diffJSONFields(a, b, "triples")
+	if err != nil {
+		return nil, err
+	}
+
+	// other triples (e.g., extensions) are compared as a whole
+	compared := append(append([]string{}, diffValueTriples...), diffKeyTriples...)
+
+	triples, err := diffJSONFields(&a.Triples, &b.Triples, compared...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(triples) != 0 {
+		fields = append(fields, "triples")
+	}
+
+	return fields, nil
+}
+
+// diffJSONFields returns, in alphabetical order, the names of the top-level
+// members of the JSON encodings of a and b that differ, ignoring the excluded
+// ones
+func diffJSONFields(a, b any, exclude ...string) ([]string, error) {
+	aFields, err := jsonFields(a, exclude...)
+	if err != nil {
+		return nil, err
+	}
+
+	bFields, err := jsonFields(b, exclude...)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+
+	for name, v := range aFields {
+		if !bytes.Equal(v, bFields[name]) {
+			names = append(names, name)
+		}
+	}
+
+	for name := range bFields {
+		if _, ok := aFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// jsonFields returns the top-level members of the JSON encoding of v, minus the
+// excluded ones
+func jsonFields(v any, exclude ...string) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("JSON encoding failed: %w", err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("JSON decoding failed: %w", err)
+	}
+
+	for _, name := range exclude {
+		delete(fields, name)
+	}
+
+	return fields, nil
+}
+
+func unmarshalField(fields map[string]json.RawMessage, name string, v any) error {
+	data, ok := fields[name]
+	if !ok {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("JSON decoding of %s failed: %w", name, err)
+	}
+
+	return nil
+}
+
+// diffSymbol returns the prefix used in the text output for the kind of change
+func diffSymbol(change string) string {
+	switch change {
+	case diffAdded:
+		return "+"
+	case diffRemoved:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// writeTripleDiffs writes the triple differences in human-readable form,
+// indented by the supplied prefix
+func writeTripleDiffs(w *strings.Builder, diffs []tripleDiff, indent string) {
+	for _, d := range diffs {
+		fmt.Fprintf(w, "%s%s %s\n", indent, diffSymbol(d.Change), d.Triple)
+		fmt.Fprintf(w, "%s    environment: %s\n", indent, d.Environment)
+
+		if len(d.Key) != 0 {
+			fmt.Fprintf(w, "%s    key: %s\n", indent, d.Key)
+		}
+
+		if len(d.Old) != 0 {
+			fmt.Fprintf(w, "%s    - %s\n", indent, d.Old)
+		}
+
+		if len(d.New) != 0 {
+			fmt.Fprintf(w, "%s    + %s\n", indent, d.New)
+		}
+	}
+}
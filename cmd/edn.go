@@ -0,0 +1,641 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ednSchema describes the structure of a CBOR data item, so that map keys and
+// array elements can be annotated with their CDDL names when the item is
+// rendered in diagnostic notation
+type ednSchema struct {
+	// the entries of a map, by integer key
+	fields map[int64]ednField
+	// the leading elements of an array
+	items []ednField
+	// the remaining elements of an array
+	elem *ednSchema
+	// whether the item is a byte string wrapping a CBOR-encoded data item,
+	// which is described by the rest of the schema
+	embedded bool
+}
+
+// ednField is a named component of a map or array
+type ednField struct {
+	name   string
+	schema *ednSchema
+}
+
+// CDDL names from the CoRIM, CoSWID (RFC 9393), CoTS and COSE (RFC 9052)
+// specifications
+var (
+	ednEmbedded = &ednSchema{embedded: true}
+
+	ednValidity = &ednSchema{fields: map[int64]ednField{
+		0: {"not-before", nil},
+		1: {"not-after", nil},
+	}}
+
+	ednEntity = &ednSchema{fields: map[int64]ednField{
+		0: {"entity-name", nil},
+		1: {"reg-id", nil},
+		2: {"role", nil},
+	}}
+
+	ednTagIdentity = &ednSchema{fields: map[int64]ednField{
+		0: {"tag-id", nil},
+		1: {"tag-version", nil},
+	}}
+
+	ednClass = &ednSchema{fields: map[int64]ednField{
+		0: {"class-id", nil},
+		1: {"vendor", nil},
+		2: {"model", nil},
+		3: {"layer", nil},
+		4: {"index", nil},
+	}}
+
+	ednEnvironment = &ednSchema{fields: map[int64]ednField{
+		0: {"class", ednClass},
+		1: {"instance", nil},
+		2: {"group", nil},
+	}}
+
+	ednMval = &ednSchema{fields: map[int64]ednField{
+		0: {"version", &ednSchema{fields: map[int64]ednField{
+			0: {"version", nil},
+			1: {"version-scheme", nil},
+		}}},
+		1: {"svn", nil},
+		2: {"digests", &ednSchema{elem: &ednSchema{items: []ednField{
+			{"alg", nil},
+			{"val", nil},
+		}}}},
+		3: {"flags", &ednSchema{fields: map[int64]ednField{
+			0: {"is-configured", nil},
+			1: {"is-secure", nil},
+			2: {"is-recovery", nil},
+			3: {"is-debug", nil},
+			4: {"is-replay-protected", nil},
+			5: {"is-integrity-protected", nil},
+			6: {"is-runtime-meas", nil},
+			7: {"is-immutable", nil},
+			8: {"is-tcb", nil},
+			9: {"is-confidentiality-protected", nil},
+		}}},
+		4:  {"raw-value", nil},
+		5:  {"raw-value-mask", nil},
+		6:  {"mac-addr", nil},
+		7:  {"ip-addr", nil},
+		8:  {"serial-number", nil},
+		9:  {"ueid", nil},
+		10: {"uuid", nil},
+		11: {"name", nil},
+		13: {"cryptokeys", nil},
+		14: {"integrity-registers", nil},
+	}}
+
+	ednMeasurements = &ednSchema{elem: &ednSchema{fields: map[int64]ednField{
+		0: {"mkey", nil},
+		1: {"mval", ednMval},
+		2: {"authorized-by", nil},
+	}}}
+
+	ednValueTriples = &ednSchema{elem: &ednSchema{items: []ednField{
+		{"environment", ednEnvironment},
+		{"measurements", ednMeasurements},
+	}}}
+
+	ednKeyTriples = &ednSchema{elem: &ednSchema{items: []ednField{
+		{"environment", ednEnvironment},
+		{"key-list", nil},
+	}}}
+
+	ednComid = &ednSchema{fields: map[int64]ednField{
+		0: {"language", nil},
+		1: {"tag-identity", ednTagIdentity},
+		2: {"entities", &ednSchema{elem: ednEntity}},
+		3: {"linked-tags", &ednSchema{elem: &ednSchema{fields: map[int64]ednField{
+			0: {"linked-tag-id", nil},
+			1: {"tag-rel", nil},
+		}}}},
+		4: {"triples", &ednSchema{fields: map[int64]ednField{
+			0:  {"reference-triples", ednValueTriples},
+			1:  {"endorsed-triples", ednValueTriples},
+			2:  {"identity-triples", ednKeyTriples},
+			3:  {"attest-key-triples", ednKeyTriples},
+			4:  {"dependency-triples", nil},
+			5:  {"membership-triples", nil},
+			6:  {"coswid-triples", nil},
+			8:  {"conditional-endorsement-series-triples", nil},
+			10: {"conditional-endorsement-triples", nil},
+		}}},
+	}}
+
+	ednCoswidEntityFields = map[int64]ednField{
+		31: {"entity-name", nil},
+		32: {"reg-id", nil},
+		33: {"role", nil},
+		34: {"thumbprint", nil},
+	}
+
+	ednCoswid = &ednSchema{fields: map[int64]ednField{
+		0: {"tag-id", nil},
+		1: {"software-name", nil},
+		// a single entity, or an array of them
+		2: {"entity", &ednSchema{
+			fields: ednCoswidEntityFields,
+			elem:   &ednSchema{fields: ednCoswidEntityFields},
+		}},
+		3:  {"evidence", nil},
+		4:  {"link", nil},
+		5:  {"software-meta", nil},
+		6:  {"payload", nil},
+		8:  {"corpus", nil},
+		9:  {"patch", nil},
+		10: {"media", nil},
+		11: {"supplemental", nil},
+		12: {"tag-version", nil},
+		13: {"software-version", nil},
+		14: {"version-scheme", nil},
+		15: {"lang", nil},
+	}}
+
+	ednCots = &ednSchema{fields: map[int64]ednField{
+		0: {"language", nil},
+		1: {"tag-identity", ednTagIdentity},
+		2: {"environments", &ednSchema{elem: &ednSchema{fields: map[int64]ednField{
+			0: {"environment", ednEnvironment},
+			1: {"abbreviated-swid-tag", nil},
+			2: {"named-ta-store", nil},
+		}}}},
+		3: {"purposes", nil},
+		4: {"permclaims", nil},
+		5: {"exclclaims", nil},
+		6: {"keys", &ednSchema{fields: map[int64]ednField{
+			0: {"tas", &ednSchema{elem: &ednSchema{items: []ednField{
+				{"format", nil},
+				{"data", nil},
+			}}}},
+			1: {"cas", nil},
+		}}},
+	}}
+
+	ednCorim = &ednSchema{fields: map[int64]ednField{
+		0: {"corim-id", nil},
+		1: {"tags", &ednSchema{elem: ednEmbedded}},
+		2: {"dependent-rims", &ednSchema{elem: &ednSchema{fields: map[int64]ednField{
+			0: {"href", nil},
+			1: {"thumbprint", nil},
+		}}}},
+		3: {"profile", nil},
+		4: {"rim-validity", ednValidity},
+		5: {"entities", &ednSchema{elem: ednEntity}},
+	}}
+
+	ednCoseHeaderFields = map[int64]ednField{
+		1: {"alg", nil},
+		2: {"crit", nil},
+		3: {"content-type", nil},
+		4: {"kid", nil},
+		5: {"IV", nil},
+		6: {"Partial IV", nil},
+		8: {"corim-meta", &ednSchema{embedded: true, fields: map[int64]ednField{
+			0: {"signer", &ednSchema{fields: map[int64]ednField{
+				0: {"signer-name", nil},
+				1: {"signer-uri", nil},
+			}}},
+			1: {"signature-validity", ednValidity},
+		}}},
+		33:                        {"x5chain", nil},
+		34:                        {"x5t", nil},
+		35:                        {"x5u", nil},
+		headerLabelTimestampToken: {"3161-ctt", nil},
+	}
+
+	ednCoseProtected   = &ednSchema{embedded: true, fields: ednCoseHeaderFields}
+	ednCoseUnprotected = &ednSchema{fields: ednCoseHeaderFields}
+
+	// the payload of a signed CoRIM is a (tagged) unsigned CoRIM
+	ednCosePayload = &ednSchema{embedded: true, fields: ednCorim.fields}
+
+	ednCoseSign1 = &ednSchema{items: []ednField{
+		{"protected", ednCoseProtected},
+		{"unprotected", ednCoseUnprotected},
+		{"payload", ednCosePayload},
+		{"signature", nil},
+	}}
+
+	ednCoseSign = &ednSchema{items: []ednField{
+		{"protected", ednCoseProtected},
+		{"unprotected", ednCoseUnprotected},
+		{"payload", ednCosePayload},
+		{"signatures", &ednSchema{elem: &ednSchema{items: []ednField{
+			{"protected", ednCoseProtected},
+			{"unprotected", ednCoseUnprotected},
+			{"signature", nil},
+		}}}},
+	}}
+
+	// the schema of the content of well-known tags, which overrides that of
+	// the enclosing item
+	ednTags = map[uint64]ednField{
+		18:  {"COSE_Sign1", ednCoseSign1},
+		98:  {"COSE_Sign", ednCoseSign},
+		500: {"tagged-corim", nil},
+		501: {"tagged-unsigned-corim-map", ednCorim},
+		502: {"tagged-signed-corim", nil},
+		505: {"tagged-concise-swid-tag", ednCoswid},
+		506: {"tagged-concise-mid-tag", ednComid},
+		507: {"tagged-concise-ta-store-map", ednCots},
+		600: {"tagged-implementation-id-type", nil},
+		601: {"tagged-psa-refval-id", &ednSchema{fields: map[int64]ednField{
+			1: {"label", nil},
+			4: {"version", nil},
+			5: {"signer-id", nil},
+		}}},
+	}
+)
+
+// maximum nesting of arrays, maps, tags and embedded items
+const ednMaxDepth = 64
+
+// ednWriter renders CBOR data items in extended diagnostic notation
+type ednWriter struct {
+	b        strings.Builder
+	annotate bool
+	// the nesting level of the item being rendered
+	nesting int
+}
+
+// toEDN renders the CBOR data item in data in extended diagnostic notation
+// (RFC 8610, Appendix G).  If annotate is set, map keys and array elements
+// described by the schema, and well-known tags, are preceded by their CDDL
+// names, in comments.
+func toEDN(data []byte, s *ednSchema, annotate bool) (string, error) {
+	w := ednWriter{annotate: annotate}
+
+	rest, err := w.item(data, s, 0)
+	if err != nil {
+		return "", err
+	}
+
+	if len(rest) != 0 {
+		return "", fmt.Errorf("%d bytes of extraneous data", len(rest))
+	}
+
+	return w.b.String(), nil
+}
+
+// ednHead decodes the initial byte and the argument of a data item.  For
+// indefinite length items, ai is 31 and arg is 0.
+func ednHead(data []byte) (major, ai byte, arg uint64, rest []byte, err error) {
+	if len(data) == 0 {
+		return 0, 0, 0, nil, errors.New("unexpected end of data")
+	}
+
+	major, ai, rest = data[0]>>5, data[0]&0x1f, data[1:]
+
+	switch {
+	case ai < 24:
+		arg = uint64(ai)
+	case ai <= 27:
+		n := 1 << (ai - 24)
+		if len(rest) < n {
+			return 0, 0, 0, nil, errors.New("unexpected end of data")
+		}
+		for _, c := range rest[:n] {
+			arg = arg<<8 | uint64(c)
+		}
+		rest = rest[n:]
+	case ai == 31:
+		if major < 2 || major == 6 {
+			return 0, 0, 0, nil, fmt.Errorf("invalid indefinite length for major type %d", major)
+		}
+	default:
+		return 0, 0, 0, nil, fmt.Errorf("reserved additional information %d", ai)
+	}
+
+	return major, ai, arg, rest, nil
+}
+
+func (o *ednWriter) newline(depth int) {
+	o.b.WriteByte('\n')
+	o.b.WriteString(strings.Repeat("  ", depth))
+}
+
+func (o *ednWriter) comment(name string) {
+	if o.annotate && name != "" {
+		fmt.Fprintf(&o.b, "/ %s / ", name)
+	}
+}
+
+// item renders the data item at the start of data, indented by depth, and
+// returns what follows
+func (o *ednWriter) item(data []byte, s *ednSchema, depth int) ([]byte, error) {
+	if o.nesting++; o.nesting > ednMaxDepth {
+		return nil, errors.New("maximum nesting depth exceeded")
+	}
+	defer func() { o.nesting-- }()
+
+	major, ai, arg, rest, err := ednHead(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case 0:
+		o.b.WriteString(strconv.FormatUint(arg, 10))
+	case 1:
+		if arg == math.MaxUint64 {
+			o.b.WriteString("-18446744073709551616")
+		} else {
+			o.b.WriteString("-" + strconv.FormatUint(arg+1, 10))
+		}
+	case 2, 3:
+		return o.str(major, ai, arg, rest, s, depth)
+	case 4:
+		return o.array(ai, arg, rest, s, depth)
+	case 5:
+		return o.dict(ai, arg, rest, s, depth)
+	case 6:
+		t, ok := ednTags[arg]
+		if ok {
+			o.comment(t.name)
+		}
+		fmt.Fprintf(&o.b, "%d(", arg)
+		if rest, err = o.item(rest, t.schema, depth); err != nil {
+			return nil, err
+		}
+		o.b.WriteByte(')')
+	case 7:
+		return o.simple(ai, arg, rest)
+	}
+
+	return rest, nil
+}
+
+// str renders a byte or text string
+func (o *ednWriter) str(major, ai byte, arg uint64, data []byte, s *ednSchema, depth int) ([]byte, error) {
+	if ai == 31 {
+		// indefinite length strings are rendered chunk by chunk
+		o.b.WriteString("(_ ")
+		for i := 0; ; i++ {
+			if len(data) == 0 {
+				return nil, errors.New("unexpected end of data")
+			}
+			if data[0] == 0xff {
+				o.b.WriteByte(')')
+				return data[1:], nil
+			}
+			if i != 0 {
+				o.b.WriteString(", ")
+			}
+			m, cai, carg, rest, err := ednHead(data)
+			if err != nil {
+				return nil, err
+			}
+			if m != major || cai == 31 {
+				return nil, errors.New("invalid chunk in indefinite length string")
+			}
+			if data, err = o.str(m, cai, carg, rest, nil, depth); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if arg > uint64(len(data)) {
+		return nil, errors.New("unexpected end of data")
+	}
+
+	v, rest := data[:arg], data[arg:]
+
+	if major == 3 {
+		var b bytes.Buffer
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(string(v)); err != nil {
+			return nil, err
+		}
+		o.b.WriteString(strings.TrimSuffix(b.String(), "\n"))
+		return rest, nil
+	}
+
+	// byte strings wrapping CBOR are rendered as embedded CBOR, if they can
+	// be decoded as such
+	if s != nil && s.embedded && len(v) != 0 {
+		inner := *s
+		inner.embedded = false
+
+		w := ednWriter{annotate: o.annotate, nesting: o.nesting}
+		if r, err := w.item(v, &inner, depth); err == nil && len(r) == 0 {
+			o.b.WriteString("<< ")
+			o.b.WriteString(w.b.String())
+			o.b.WriteString(" >>")
+			return rest, nil
+		}
+	}
+
+	o.b.WriteString("h'" + hex.EncodeToString(v) + "'")
+
+	return rest, nil
+}
+
+// array renders an array, one element per line
+func (o *ednWriter) array(ai byte, n uint64, data []byte, s *ednSchema, depth int) ([]byte, error) {
+	open := "["
+	if ai == 31 {
+		open = "[_ "
+	}
+
+	return o.container(open, "]", ai == 31, n, data, depth, func(i int, data []byte) ([]byte, error) {
+		var f ednField
+		if s != nil {
+			if i < len(s.items) {
+				f = s.items[i]
+			} else {
+				f.schema = s.elem
+			}
+		}
+
+		o.comment(f.name)
+
+		return o.item(data, f.schema, depth+1)
+	})
+}
+
+// dict renders a map, one entry per line
+func (o *ednWriter) dict(ai byte, n uint64, data []byte, s *ednSchema, depth int) ([]byte, error) {
+	open := "{"
+	if ai == 31 {
+		open = "{_ "
+	}
+
+	return o.container(open, "}", ai == 31, n, data, depth, func(i int, data []byte) ([]byte, error) {
+		var f ednField
+		if s != nil {
+			if key, ok := ednIntKey(data); ok {
+				f = s.fields[key]
+			}
+		}
+
+		o.comment(f.name)
+
+		data, err := o.item(data, nil, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		o.b.WriteString(": ")
+
+		return o.item(data, f.schema, depth+1)
+	})
+}
+
+// container renders the members of an array or map, using the supplied
+// function to render each of them
+func (o *ednWriter) container(
+	open, closing string, indefinite bool, n uint64, data []byte, depth int,
+	member func(int, []byte) ([]byte, error),
+) ([]byte, error) {
+	if !indefinite && n == 0 {
+		o.b.WriteString(open + closing)
+		return data, nil
+	}
+
+	if indefinite && len(data) != 0 && data[0] == 0xff {
+		o.b.WriteString(open + closing)
+		return data[1:], nil
+	}
+
+	o.b.WriteString(strings.TrimSpace(open))
+
+	var err error
+
+	for i := 0; ; i++ {
+		if indefinite {
+			if len(data) == 0 {
+				return nil, errors.New("unexpected end of data")
+			}
+			if data[0] == 0xff {
+				data = data[1:]
+				break
+			}
+		} else if uint64(i) == n {
+			break
+		}
+
+		if i != 0 {
+			o.b.WriteByte(',')
+		}
+
+		o.newline(depth + 1)
+
+		if data, err = member(i, data); err != nil {
+			return nil, err
+		}
+	}
+
+	o.newline(depth)
+	o.b.WriteString(closing)
+
+	return data, nil
+}
+
+// ednIntKey returns the value of the integer map key at the start of data
+func ednIntKey(data []byte) (int64, bool) {
+	major, _, arg, _, err := ednHead(data)
+	if err != nil || major > 1 || arg > math.MaxInt64 {
+		return 0, false
+	}
+
+	if major == 1 {
+		return -1 - int64(arg), true
+	}
+
+	return int64(arg), true
+}
+
+// simple renders simple values and floating-point numbers
+func (o *ednWriter) simple(ai byte, arg uint64, data []byte) ([]byte, error) {
+	var f float64
+
+	switch ai {
+	case 20:
+		o.b.WriteString("false")
+		return data, nil
+	case 21:
+		o.b.WriteString("true")
+		return data, nil
+	case 22:
+		o.b.WriteString("null")
+		return data, nil
+	case 23:
+		o.b.WriteString("undefined")
+		return data, nil
+	case 25:
+		f = halfToFloat(uint16(arg))
+	case 26:
+		f = float64(math.Float32frombits(uint32(arg)))
+	case 27:
+		f = math.Float64frombits(arg)
+	case 31:
+		return nil, errors.New("unexpected break")
+	default:
+		fmt.Fprintf(&o.b, "simple(%d)", arg)
+		return data, nil
+	}
+
+	switch {
+	case math.IsNaN(f):
+		o.b.WriteString("NaN")
+	case math.IsInf(f, 1):
+		o.b.WriteString("Infinity")
+	case math.IsInf(f, -1):
+		o.b.WriteString("-Infinity")
+	default:
+		s := strconv.FormatFloat(f, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+		o.b.WriteString(s)
+	}
+
+	return data, nil
+}
+
+// halfToFloat converts an IEEE 754 half-precision number (RFC 8949, Appendix D)
+func halfToFloat(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	var v float64
+
+	switch exp {
+	case 0:
+		v = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			v = math.Inf(1)
+		} else {
+			v = math.NaN()
+		}
+	default:
+		v = math.Ldexp(mant+1024, exp-25)
+	}
+
+	if h&0x8000 != 0 {
+		return -v
+	}
+
+	return v
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/corim/comid"
+)
+
+func Test_toEDN_scalars(t *testing.T) {
+	// examples from RFC 8949, Appendix A
+	tvs := []struct {
+		cbor string
+		edn  string
+	}{
+		{"00", "0"},
+		{"1903e8", "1000"},
+		{"1bffffffffffffffff", "18446744073709551615"},
+		{"20", "-1"},
+		{"3903e7", "-1000"},
+		{"3bffffffffffffffff", "-18446744073709551616"},
+		{"f93e00", "1.5"},
+		{"f93c00", "1.0"},
+		{"f90001", "5.960464477539063e-08"},
+		{"fa47c35000", "100000.0"},
+		{"fb3ff199999999999a", "1.1"},
+		{"f97c00", "Infinity"},
+		{"f97e00", "NaN"},
+		{"f9fc00", "-Infinity"},
+		{"f4", "false"},
+		{"f5", "true"},
+		{"f6", "null"},
+		{"f7", "undefined"},
+		{"f0", "simple(16)"},
+		{"f8ff", "simple(255)"},
+		{"40", "h''"},
+		{"4401020304", "h'01020304'"},
+		{"60", `""`},
+		{"62225c", `"\"\\"`},
+		{"63e6b0b4", `"水"`},
+		{"c074323031332d30332d32315432303a30343a30305a", `0("2013-03-21T20:04:00Z")`},
+		{"5f42010243030405ff", "(_ h'0102', h'030405')"},
+		{"7f657374726561646d696e67ff", `(_ "strea", "ming")`},
+		{"80", "[]"},
+		{"a0", "{}"},
+		{"9fff", "[_ ]"},
+	}
+
+	for _, tv := range tvs {
+		edn, err := toEDN(comid.MustHexDecode(t, tv.cbor), nil, false)
+		require.NoError(t, err, tv.cbor)
+		assert.Equal(t, tv.edn, edn, tv.cbor)
+	}
+}
+
+func Test_toEDN_containers(t *testing.T) {
+	// {"a": 1, "b": [2, 3]}
+	edn, err := toEDN(comid.MustHexDecode(t, "a26161016162820203"), nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "a": 1,
+  "b": [
+    2,
+    3
+  ]
+}`, edn)
+
+	// [_ 1, [2, 3], [_ 4, 5]]
+	edn, err = toEDN(comid.MustHexDecode(t, "9f018202039f0405ffff"), nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, `[_
+  1,
+  [
+    2,
+    3
+  ],
+  [_
+    4,
+    5
+  ]
+]`, edn)
+}
+
+func Test_toEDN_embedded(t *testing.T) {
+	s := &ednSchema{items: []ednField{
+		{"wrapped", &ednSchema{embedded: true, fields: map[int64]ednField{1: {"one", nil}}}},
+		{"not-cbor", ednEmbedded},
+	}}
+
+	// [h'a10102', h'ff']
+	edn, err := toEDN(comid.MustHexDecode(t, "8243a1010241ff"), s, true)
+	require.NoError(t, err)
+	assert.Equal(t, `[
+  / wrapped / << {
+    / one / 1: 2
+  } >>,
+  / not-cbor / h'ff'
+]`, edn)
+
+	// same, without annotations
+	edn, err = toEDN(comid.MustHexDecode(t, "8243a1010241ff"), s, false)
+	require.NoError(t, err)
+	assert.Equal(t, `[
+  << {
+    1: 2
+  } >>,
+  h'ff'
+]`, edn)
+}
+
+func Test_toEDN_errors(t *testing.T) {
+	tvs := []struct {
+		cbor string
+		err  string
+	}{
+		{"", "unexpected end of data"},
+		{"19ff", "unexpected end of data"},
+		{"43ff", "unexpected end of data"},
+		{"8201", "unexpected end of data"},
+		{"9f01", "unexpected end of data"},
+		{"1c", "reserved additional information 28"},
+		{"1f", "invalid indefinite length for major type 0"},
+		{"ff", "unexpected break"},
+		{"5f01ff", "invalid chunk in indefinite length string"},
+		{"0101", "1 bytes of extraneous data"},
+	}
+
+	for _, tv := range tvs {
+		_, err := toEDN(comid.MustHexDecode(t, tv.cbor), nil, false)
+		assert.EqualError(t, err, tv.err, tv.cbor)
+	}
+
+	// 65 nested arrays
+	deep := make([]byte, 65)
+	for i := range deep {
+		deep[i] = 0x81
+	}
+	_, err := toEDN(append(deep, 0x00), nil, false)
+	assert.EqualError(t, err, "maximum nesting depth exceeded")
+}
+
+func Test_toEDN_signed_corim(t *testing.T) {
+	edn, err := toEDN(testSignedCorimValidWithCots, ednCorim, true)
+	require.NoError(t, err)
+
+	assert.Contains(t, edn, "/ COSE_Sign1 / 18([\n  / protected / << {\n    / alg / 1: -7,\n")
+	assert.Contains(t, edn, `/ content-type / 3: "application/rim+cbor",`)
+	assert.Contains(t, edn, "/ corim-meta / 8: << {\n      / signer / 0: {\n")
+	assert.Contains(t, edn, "/ payload / << {\n    / corim-id / 0: h'")
+	assert.Contains(t, edn, "<< / tagged-concise-ta-store-map / 507({\n")
+	assert.Contains(t, edn, "/ keys / 6: {\n          / tas / 0: [\n")
+}
+
+func Test_toEDN_comid(t *testing.T) {
+	edn, err := toEDN(PSARefValCBOR, ednComid, true)
+	require.NoError(t, err)
+
+	assert.Contains(t, edn, "/ tag-identity / 1: {\n    / tag-id / 0: ")
+	assert.Contains(t, edn, "/ reference-triples / 0: [")
+	assert.Contains(t, edn, "/ mkey / 0: / tagged-psa-refval-id / 601({")
+	assert.Contains(t, edn, "/ digests / 2: [")
+}
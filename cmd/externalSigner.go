@@ -0,0 +1,151 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	cose "github.com/veraison/go-cose"
+)
+
+// externalSignerProtocolVersion is the version of the JSON protocol spoken
+// with the external signer
+const externalSignerProtocolVersion = 1
+
+// operations the external signer is asked to perform
+const (
+	externalSignerOpSign      = "sign"
+	externalSignerOpCertChain = "cert-chain"
+)
+
+// externalSignerRequest is written, as a single JSON object, to the standard
+// input of the external signer.  Operation is either "cert-chain", to retrieve
+// the certificate chain of the signing key, or "sign".  For "sign", TBS is the
+// COSE Sig_structure to be signed (base64-encoded in JSON) and Algorithm is the
+// COSE algorithm name (e.g., "ES256").
+type externalSignerRequest struct {
+	Version   int    `json:"version"`
+	Operation string `json:"operation"`
+	Algorithm string `json:"algorithm,omitempty"`
+	TBS       []byte `json:"tbs,omitempty"`
+}
+
+// externalSignerResponse is read, as a single JSON object, from the standard
+// output of the external signer.  Signature is the signature in its COSE
+// encoding (e.g., r || s for ECDSA), in response to "sign".  CertChain carries
+// the DER-encoded signing certificate followed by any intermediate
+// certificates, in response to "cert-chain", and may be empty.  A non-empty
+// Error makes the operation fail.
+type externalSignerResponse struct {
+	Signature []byte   `json:"signature,omitempty"`
+	CertChain [][]byte `json:"cert-chain,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// externalSigner implements cose.Signer by delegating the signature operation
+// to an external program
+type externalSigner struct {
+	command string
+	args    []string
+	alg     cose.Algorithm
+}
+
+func newExternalSigner(command string, args []string) *externalSigner {
+	return &externalSigner{command: command, args: args}
+}
+
+// Algorithm returns the COSE algorithm the external signer is asked to use
+func (o *externalSigner) Algorithm() cose.Algorithm {
+	return o.alg
+}
+
+// Sign runs the external signer on the supplied to-be-signed bytes
+func (o *externalSigner) Sign(_ io.Reader, content []byte) ([]byte, error) {
+	rsp, err := o.run(externalSignerRequest{
+		Operation: externalSignerOpSign,
+		Algorithm: o.alg.String(),
+		TBS:       content,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rsp.Signature) == 0 {
+		return nil, fmt.Errorf("no signature in response from %s", o.command)
+	}
+
+	return rsp.Signature, nil
+}
+
+// CertChain runs the external signer to retrieve the certificate chain of its
+// signing key, if it has one
+func (o *externalSigner) CertChain() ([][]byte, error) {
+	rsp, err := o.run(externalSignerRequest{Operation: externalSignerOpCertChain})
+	if err != nil {
+		return nil, err
+	}
+
+	return rsp.CertChain, nil
+}
+
+// run sends the request to the external signer and returns its response
+func (o *externalSigner) run(r externalSignerRequest) (*externalSignerResponse, error) {
+	r.Version = externalSignerProtocolVersion
+
+	req, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request: %w", err)
+	}
+
+	var stdout bytes.Buffer
+
+	cmd := exec.Command(o.command, o.args...)
+	cmd.Stdin = bytes.NewReader(req)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	if err = cmd.Run(); err != nil {
+		return nil, fmt.Errorf("error running %s: %w", o.command, err)
+	}
+
+	var rsp externalSignerResponse
+	if err = json.Unmarshal(stdout.Bytes(), &rsp); err != nil {
+		return nil, fmt.Errorf("error decoding response from %s: %w", o.command, err)
+	}
+
+	if rsp.Error != "" {
+		return nil, fmt.Errorf("%s reported an error: %s", o.command, rsp.Error)
+	}
+
+	return &rsp, nil
+}
+
+var coseAlgorithms = []cose.Algorithm{
+	cose.AlgorithmES256,
+	cose.AlgorithmES384,
+	cose.AlgorithmES512,
+	cose.AlgorithmEdDSA,
+	cose.AlgorithmPS256,
+	cose.AlgorithmPS384,
+	cose.AlgorithmPS512,
+}
+
+// algFromName returns the COSE signature algorithm with the supplied name
+func algFromName(name string) (cose.Algorithm, error) {
+	for _, alg := range coseAlgorithms {
+		if alg.String() == name {
+			return alg, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unsupported signature algorithm %q", name)
+}
+
+var errNoSignerAlg = errors.New("no signature algorithm for the external signer: use --signer-alg or --cert")
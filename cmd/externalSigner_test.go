@@ -0,0 +1,394 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/corim/corim"
+	cose "github.com/veraison/go-cose"
+)
+
+// When COCLI_TEST_SIGNER_KEY is set, the test binary acts as the external
+// signer, using the PKCS#8 key in that file.  COCLI_TEST_SIGNER_CHAIN
+// optionally points to a file with the DER certificates to return,
+// COCLI_TEST_SIGNER_ERROR makes the signer report an error when signing, and
+// COCLI_TEST_SIGNER_LOG points to a file to which the operation and arguments
+// of each invocation are appended.
+func TestMain(m *testing.M) {
+	if keyFile := os.Getenv("COCLI_TEST_SIGNER_KEY"); keyFile != "" {
+		os.Exit(runTestExternalSigner(keyFile))
+	}
+
+	os.Exit(m.Run())
+}
+
+func runTestExternalSigner(keyFile string) int {
+	var (
+		req externalSignerRequest
+		rsp externalSignerResponse
+	)
+
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		return 1
+	}
+
+	if logFile := os.Getenv("COCLI_TEST_SIGNER_LOG"); logFile != "" {
+		f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return 1
+		}
+		fmt.Fprintln(f, strings.Join(append([]string{req.Operation}, os.Args[1:]...), " "))
+		f.Close()
+	}
+
+	switch {
+	case req.Operation == externalSignerOpCertChain:
+		if err := addTestSignerChain(&rsp); err != nil {
+			return 1
+		}
+	case req.Operation != externalSignerOpSign:
+		rsp.Error = "unknown operation " + req.Operation
+	case os.Getenv("COCLI_TEST_SIGNER_ERROR") != "":
+		rsp.Error = os.Getenv("COCLI_TEST_SIGNER_ERROR")
+	default:
+		der, err := os.ReadFile(keyFile)
+		if err != nil {
+			return 1
+		}
+
+		key, err := parsePrivateKey(der, nil)
+		if err != nil {
+			return 1
+		}
+
+		alg, err := algFromName(req.Algorithm)
+		if err != nil {
+			return 1
+		}
+
+		signer, err := cose.NewSigner(alg, key)
+		if err != nil {
+			return 1
+		}
+
+		if rsp.Signature, err = signer.Sign(rand.Reader, req.TBS); err != nil {
+			return 1
+		}
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(rsp); err != nil {
+		return 1
+	}
+
+	return 0
+}
+
+func addTestSignerChain(rsp *externalSignerResponse) error {
+	chainFile := os.Getenv("COCLI_TEST_SIGNER_CHAIN")
+	if chainFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(chainFile)
+	if err != nil {
+		return err
+	}
+
+	certs, err := x509.ParseCertificates(data)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range certs {
+		rsp.CertChain = append(rsp.CertChain, c.Raw)
+	}
+
+	return nil
+}
+
+// testSignerLog returns the invocations of the test external signer
+func testSignerLog(t *testing.T) []string {
+	data, err := os.ReadFile(os.Getenv("COCLI_TEST_SIGNER_LOG"))
+	require.NoError(t, err)
+
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+// setupTestExternalSigner configures the test binary as external signer for
+// the leaf key of pki and returns the command to pass to --signer-cmd
+func setupTestExternalSigner(t *testing.T, pki testPKI, withChain bool) string {
+	dir := t.TempDir()
+
+	keyFile := filepath.Join(dir, "key.der")
+	require.NoError(t, os.WriteFile(keyFile, mustPKCS8(t, pki.LeafKey), 0600))
+	t.Setenv("COCLI_TEST_SIGNER_KEY", keyFile)
+	t.Setenv("COCLI_TEST_SIGNER_LOG", filepath.Join(dir, "log"))
+
+	if withChain {
+		chainFile := filepath.Join(dir, "chain.der")
+		chain := bytes.Join([][]byte{pki.Leaf, pki.Intermediate}, nil)
+		require.NoError(t, os.WriteFile(chainFile, chain, 0600))
+		t.Setenv("COCLI_TEST_SIGNER_CHAIN", chainFile)
+	}
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	return exe
+}
+
+func writeExternalSignerFixtures(t *testing.T, pki testPKI) {
+	writeSignFixtures(t)
+	require.NoError(t, afero.WriteFile(fs, "leaf.der", pki.Leaf, 0644))
+}
+
+func Test_CorimSignCmd_signer_cmd_with_alg_ok(t *testing.T) {
+	pki := makeTestPKI(t)
+	exe := setupTestExternalSigner(t, pki, false)
+	writeExternalSignerFixtures(t, pki)
+
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--signer-cmd=" + exe,
+		"--signer-arg=--key-id=corim signer",
+		"--signer-arg=-v",
+		"--signer-alg=ES256",
+	})
+
+	require.NoError(t, cmd.Execute())
+
+	data, err := afero.ReadFile(fs, "signed-ok.cbor")
+	require.NoError(t, err)
+
+	var s corim.SignedCorim
+	require.NoError(t, s.FromCOSE(data))
+	assert.NoError(t, s.Verify(pki.LeafKey.Public()))
+	assert.Nil(t, s.SigningCert)
+
+	assert.Equal(t, []string{
+		"cert-chain --key-id=corim signer -v",
+		"sign --key-id=corim signer -v",
+	}, testSignerLog(t))
+}
+
+func Test_CorimSignCmd_signer_cmd_alg_from_cert_ok(t *testing.T) {
+	pki := makeTestPKI(t)
+	exe := setupTestExternalSigner(t, pki, false)
+	writeExternalSignerFixtures(t, pki)
+
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--signer-cmd=" + exe,
+		"--cert=leaf.der",
+	})
+
+	require.NoError(t, cmd.Execute())
+
+	data, err := afero.ReadFile(fs, "signed-ok.cbor")
+	require.NoError(t, err)
+
+	var s corim.SignedCorim
+	require.NoError(t, s.FromCOSE(data))
+	require.NotNil(t, s.SigningCert)
+	assert.Equal(t, pki.Leaf, s.SigningCert.Raw)
+	assert.NoError(t, s.Verify(pki.LeafKey.Public()))
+
+	// the certificate is supplied, so the chain is not asked for
+	assert.Equal(t, []string{"sign"}, testSignerLog(t))
+}
+
+func Test_CorimSignCmd_signer_cmd_returns_chain_ok(t *testing.T) {
+	pki := makeTestPKI(t)
+	exe := setupTestExternalSigner(t, pki, true)
+	writeExternalSignerFixtures(t, pki)
+
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--signer-cmd=" + exe,
+		"--signer-alg=ES256",
+	})
+
+	require.NoError(t, cmd.Execute())
+
+	data, err := afero.ReadFile(fs, "signed-ok.cbor")
+	require.NoError(t, err)
+
+	var s corim.SignedCorim
+	require.NoError(t, s.FromCOSE(data))
+	require.NotNil(t, s.SigningCert)
+	assert.Equal(t, pki.Leaf, s.SigningCert.Raw)
+	require.Len(t, s.IntermediateCerts, 1)
+	assert.Equal(t, pki.Intermediate, s.IntermediateCerts[0].Raw)
+	assert.NoError(t, s.Verify(pki.LeafKey.Public()))
+
+	// signed once, with the chain already in the protected header
+	assert.Equal(t, []string{"cert-chain", "sign"}, testSignerLog(t))
+}
+
+func Test_CorimSignCmd_signer_cmd_alg_from_returned_chain_ok(t *testing.T) {
+	pki := makeTestPKI(t)
+	exe := setupTestExternalSigner(t, pki, true)
+	writeExternalSignerFixtures(t, pki)
+
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--signer-cmd=" + exe,
+	})
+
+	require.NoError(t, cmd.Execute())
+
+	data, err := afero.ReadFile(fs, "signed-ok.cbor")
+	require.NoError(t, err)
+
+	var s corim.SignedCorim
+	require.NoError(t, s.FromCOSE(data))
+	require.NotNil(t, s.SigningCert)
+	assert.NoError(t, s.Verify(pki.LeafKey.Public()))
+}
+
+func Test_CorimSignCmd_signer_cmd_no_alg(t *testing.T) {
+	pki := makeTestPKI(t)
+	exe := setupTestExternalSigner(t, pki, false)
+	writeExternalSignerFixtures(t, pki)
+
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--signer-cmd=" + exe,
+	})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err,
+		"error setting up external signer: no signature algorithm for the external signer: use --signer-alg or --cert")
+}
+
+func Test_CorimSignCmd_signer_cmd_bad_alg(t *testing.T) {
+	pki := makeTestPKI(t)
+	exe := setupTestExternalSigner(t, pki, false)
+	writeExternalSignerFixtures(t, pki)
+
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--signer-cmd=" + exe,
+		"--signer-alg=HS256",
+	})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, `error setting up external signer: unsupported signature algorithm "HS256"`)
+}
+
+func Test_CorimSignCmd_signer_cmd_reports_error(t *testing.T) {
+	pki := makeTestPKI(t)
+	exe := setupTestExternalSigner(t, pki, false)
+	writeExternalSignerFixtures(t, pki)
+	t.Setenv("COCLI_TEST_SIGNER_ERROR", "key not found")
+
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--signer-cmd=" + exe,
+		"--signer-alg=ES256",
+	})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "error signing CoRIM: COSE Sign1 signature failed: "+exe+" reported an error: key not found")
+}
+
+func Test_CorimSignCmd_signer_cmd_wrong_cert(t *testing.T) {
+	pki := makeTestPKI(t)
+	other := makeTestPKI(t)
+	exe := setupTestExternalSigner(t, pki, false)
+	writeExternalSignerFixtures(t, pki)
+	require.NoError(t, afero.WriteFile(fs, "other.der", other.Leaf, 0644))
+
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--signer-cmd=" + exe,
+		"--cert=other.der",
+	})
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "signature from external signer does not match the signing certificate")
+}
+
+func Test_CorimSignCmd_signer_cmd_not_found(t *testing.T) {
+	pki := makeTestPKI(t)
+	writeExternalSignerFixtures(t, pki)
+
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--signer-cmd=/nonexistent/signer",
+		"--signer-alg=ES256",
+	})
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "error running /nonexistent/signer")
+}
+
+func Test_CorimSignCmd_key_and_signer_cmd(t *testing.T) {
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--key=key.jwk",
+		"--signer-cmd=signer",
+	})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "only one of --key and --signer-cmd can be used at the same time")
+}
+
+func Test_CorimSignCmd_signer_alg_without_signer_cmd(t *testing.T) {
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--key=key.jwk",
+		"--signer-alg=ES256",
+	})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "--signer-alg can only be used with --signer-cmd")
+}
+
+func Test_CorimSignCmd_signer_arg_without_signer_cmd(t *testing.T) {
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--key=key.jwk",
+		"--signer-arg=--key-id=1",
+	})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "--signer-arg can only be used with --signer-cmd")
+}
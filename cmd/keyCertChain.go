@@ -0,0 +1,212 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var (
+	keyCertChainType      *string
+	keyCertChainFormat    *string
+	keyCertChainOutputDir *string
+	keyCertChainDays      *int
+)
+
+var keyCertChainCmd = NewKeyCertChainCmd()
+
+func NewKeyCertChainCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cert-chain",
+		Short: "generate a throwaway root, intermediate and leaf certificate chain for testing",
+		Long: `generate a throwaway root, intermediate and leaf certificate chain for testing
+
+    Generate a root CA, an intermediate CA and a leaf (signing) certificate, each
+    with a fresh EC P-256 key, and save them in DER format to root.der,
+    intermediate.der and leaf.der in the current directory.  The leaf private
+    key is saved to leaf-key.jwk.  The CA private keys are discarded.
+
+      cocli key cert-chain
+
+    The generated files can be used directly to sign and verify a CoRIM:
+
+      cocli corim sign --file=corim.cbor --meta=meta.json \
+                       --key=leaf-key.jwk \
+                       --cert=leaf.der \
+                       --intermediates=intermediate.der
+
+      cocli corim verify --file=signed-corim.cbor --ta-cert=root.der
+
+    Generate RSA keys, save the leaf key as PEM, make the certificates valid for
+    a week and save everything into the pki/ directory
+
+      cocli key cert-chain --type=rsa-2048 --format=pem --days=7 --output-dir=pki
+    `,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkKeyCertChainArgs(); err != nil {
+				return err
+			}
+
+			files, err := generateCertChain(
+				*keyCertChainType, *keyCertChainFormat, *keyCertChainOutputDir, *keyCertChainDays,
+			)
+			if err != nil {
+				return err
+			}
+
+			for _, f := range files {
+				fmt.Printf(">> saved %q\n", f)
+			}
+
+			return nil
+		},
+	}
+
+	keyCertChainType = cmd.Flags().StringP(
+		"type", "t", "ec-p256", fmt.Sprintf("key type, one of %v", keyTypes),
+	)
+	keyCertChainFormat = cmd.Flags().String("format", keyFormatJWK, "leaf key format: jwk or pem")
+	keyCertChainOutputDir = cmd.Flags().StringP(
+		"output-dir", "d", ".", "directory where the certificates and the leaf key are saved",
+	)
+	keyCertChainDays = cmd.Flags().Int("days", 30, "validity period of the certificates, in days")
+
+	return cmd
+}
+
+func checkKeyCertChainArgs() error {
+	if *keyCertChainDays <= 0 {
+		return errors.New("--days must be a positive number")
+	}
+
+	return checkKeyFormat(*keyCertChainFormat)
+}
+
+// generateCertChain mints a root -> intermediate -> leaf certificate chain and
+// returns the names of the saved files
+func generateCertChain(keyType, format, outputDir string, days int) ([]string, error) {
+	notBefore := time.Now().Add(-time.Minute).UTC()
+	notAfter := notBefore.AddDate(0, 0, days)
+
+	rootKey, err := generateKey(keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	rootTmpl := certTemplate("cocli test root CA", notBefore, notAfter, true)
+	rootTmpl.MaxPathLen = 1
+
+	root, err := createCert(rootTmpl, rootTmpl, rootKey.Public(), rootKey)
+	if err != nil {
+		return nil, fmt.Errorf("error creating root certificate: %w", err)
+	}
+
+	intKey, err := generateKey(keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	intTmpl := certTemplate("cocli test intermediate CA", notBefore, notAfter, true)
+	intTmpl.MaxPathLenZero = true
+
+	intermediate, err := createCert(intTmpl, root, intKey.Public(), rootKey)
+	if err != nil {
+		return nil, fmt.Errorf("error creating intermediate certificate: %w", err)
+	}
+
+	leafKey, err := generateKey(keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	leafTmpl := certTemplate("cocli test signer", notBefore, notAfter, false)
+
+	leaf, err := createCert(leafTmpl, intermediate, leafKey.Public(), intKey)
+	if err != nil {
+		return nil, fmt.Errorf("error creating leaf certificate: %w", err)
+	}
+
+	leafKeyData, err := encodePrivateKey(leafKey, format)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding leaf key: %w", err)
+	}
+
+	if err = fs.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating directory %s: %w", outputDir, err)
+	}
+
+	outputs := []struct {
+		name string
+		data []byte
+		perm os.FileMode
+	}{
+		{"root.der", root.Raw, 0644},
+		{"intermediate.der", intermediate.Raw, 0644},
+		{"leaf.der", leaf.Raw, 0644},
+		{"leaf-key." + format, leafKeyData, 0600},
+	}
+
+	var files []string
+
+	for _, o := range outputs {
+		f := filepath.Join(outputDir, o.name)
+		if err = afero.WriteFile(fs, f, o.data, o.perm); err != nil {
+			return nil, fmt.Errorf("error saving %s: %w", f, err)
+		}
+		files = append(files, f)
+	}
+
+	return files, nil
+}
+
+func certTemplate(cn string, notBefore, notAfter time.Time, isCA bool) *x509.Certificate {
+	tmpl := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: cn, Organization: []string{"Veraison"}},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+	}
+
+	if isCA {
+		tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	}
+
+	return tmpl
+}
+
+func createCert(
+	tmpl, parent *x509.Certificate, pub crypto.PublicKey, parentKey crypto.Signer,
+) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	tmpl.SerialNumber = serial
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, pub, parentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return x509.ParseCertificate(der)
+}
+
+func init() {
+	keyCmd.AddCommand(keyCertChainCmd)
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"crypto/x509"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_KeyCertChainCmd_bad_days(t *testing.T) {
+	cmd := NewKeyCertChainCmd()
+
+	args := []string{"--days=0"}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "--days must be a positive number")
+}
+
+func Test_KeyCertChainCmd_bad_type(t *testing.T) {
+	cmd := NewKeyCertChainCmd()
+
+	args := []string{"--type=dsa"}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, `unsupported key type "dsa"`)
+}
+
+func Test_KeyCertChainCmd_ok(t *testing.T) {
+	cmd := NewKeyCertChainCmd()
+
+	args := []string{"--output-dir=pki", "--format=pem", "--days=7"}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	load := func(name string) *x509.Certificate {
+		data, err := afero.ReadFile(fs, filepath.Join("pki", name))
+		require.NoError(t, err)
+		cert, err := x509.ParseCertificate(data)
+		require.NoError(t, err)
+		return cert
+	}
+
+	root, intermediate, leaf := load("root.der"), load("intermediate.der"), load("leaf.der")
+
+	assert.True(t, root.IsCA)
+	assert.True(t, intermediate.IsCA)
+	assert.False(t, leaf.IsCA)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+	intermediates := x509.NewCertPool()
+	intermediates.AddCert(intermediate)
+
+	_, err = leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	assert.NoError(t, err)
+
+	keyData, err := afero.ReadFile(fs, "pki/leaf-key.pem")
+	require.NoError(t, err)
+
+	pub, err := newPublicKeyFromKeyData(keyData)
+	require.NoError(t, err)
+	assert.Equal(t, leaf.PublicKey, pub)
+}
+
+func Test_KeyCertChainCmd_sign_and_verify_ok(t *testing.T) {
+	cmd := NewKeyCertChainCmd()
+	cmd.SetArgs([]string{"--type=ec-p384"})
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, cmd.Execute())
+
+	require.NoError(t, afero.WriteFile(fs, "ok.cbor", testCorimValid, 0644))
+	require.NoError(t, afero.WriteFile(fs, "ok.json", testMetaValid, 0644))
+
+	cmd = NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor",
+		"--meta=ok.json",
+		"--key=leaf-key.jwk",
+		"--cert=leaf.der",
+		"--intermediates=intermediate.der",
+	})
+	require.NoError(t, cmd.Execute())
+
+	cmd = NewCorimVerifyCmd()
+	cmd.SetArgs([]string{
+		"--file=signed-ok.cbor",
+		"--ta-cert=root.der",
+		"--skip-validity-check",
+	})
+	assert.NoError(t, cmd.Execute())
+}
@@ -0,0 +1,90 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var (
+	keyGenerateType       *string
+	keyGenerateFormat     *string
+	keyGenerateOutputFile *string
+)
+
+var keyGenerateCmd = NewKeyGenerateCmd()
+
+func NewKeyGenerateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "generate a signing key that can be used with corim sign",
+		Long: `generate a signing key that can be used with corim sign
+
+    Generate an EC P-256 key in JWK format and save it to ec-p256.jwk
+
+      cocli key generate
+
+    Generate a 3072-bit RSA key as a PKCS#8 PEM file and save it to signer.pem
+
+      cocli key generate --type=rsa-3072 --format=pem --output=signer.pem
+
+    Supported key types are ec-p256, ec-p384, ec-p521, ed25519, rsa-2048,
+    rsa-3072 and rsa-4096.  JWKs carry the "alg" parameter matching the key
+    type (RSA keys use RSASSA-PSS).
+    `,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkKeyFormat(*keyGenerateFormat); err != nil {
+				return err
+			}
+
+			keyFile, err := generateKeyFile(*keyGenerateType, *keyGenerateFormat, *keyGenerateOutputFile)
+			if err != nil {
+				return err
+			}
+			fmt.Printf(">> generated %s key and saved to %q\n", *keyGenerateType, keyFile)
+
+			return nil
+		},
+	}
+
+	keyGenerateType = cmd.Flags().StringP(
+		"type", "t", "ec-p256", fmt.Sprintf("key type, one of %v", keyTypes),
+	)
+	keyGenerateFormat = cmd.Flags().String("format", keyFormatJWK, "key format: jwk or pem")
+	keyGenerateOutputFile = cmd.Flags().StringP(
+		"output", "o", "", "name of the generated key file (default <type>.<format>)",
+	)
+
+	return cmd
+}
+
+func generateKeyFile(keyType, format, outputFile string) (string, error) {
+	key, err := generateKey(keyType)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := encodePrivateKey(key, format)
+	if err != nil {
+		return "", fmt.Errorf("error encoding %s key: %w", keyType, err)
+	}
+
+	if outputFile == "" {
+		outputFile = keyType + "." + format
+	}
+
+	if err = afero.WriteFile(fs, outputFile, data, 0600); err != nil {
+		return "", fmt.Errorf("error saving key to file %s: %w", outputFile, err)
+	}
+
+	return outputFile, nil
+}
+
+func init() {
+	keyCmd.AddCommand(keyGenerateCmd)
+}
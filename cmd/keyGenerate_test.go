@@ -0,0 +1,113 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cose "github.com/veraison/go-cose"
+)
+
+func Test_KeyGenerateCmd_unknown_argument(t *testing.T) {
+	cmd := NewKeyGenerateCmd()
+
+	args := []string{"--unknown-argument=val"}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "unknown flag: --unknown-argument")
+}
+
+func Test_KeyGenerateCmd_bad_type(t *testing.T) {
+	cmd := NewKeyGenerateCmd()
+
+	args := []string{"--type=ec-p224"}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+
+	err := cmd.Execute()
+	assert.EqualError(t, err,
+		`unsupported key type "ec-p224", expecting one of [ec-p256 ec-p384 ec-p521 ed25519 rsa-2048 rsa-3072 rsa-4096]`)
+}
+
+func Test_KeyGenerateCmd_bad_format(t *testing.T) {
+	cmd := NewKeyGenerateCmd()
+
+	args := []string{"--format=der"}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, `unsupported key format "der", expecting "jwk" or "pem"`)
+}
+
+func Test_KeyGenerateCmd_ok(t *testing.T) {
+	tvs := []struct {
+		keyType string
+		alg     cose.Algorithm
+	}{
+		{"ec-p256", cose.AlgorithmES256},
+		{"ec-p384", cose.AlgorithmES384},
+		{"ec-p521", cose.AlgorithmES512},
+		{"ed25519", cose.AlgorithmEdDSA},
+		{"rsa-2048", cose.AlgorithmPS256},
+	}
+
+	for _, tv := range tvs {
+		for _, format := range []string{keyFormatJWK, keyFormatPEM} {
+			t.Run(tv.keyType+"/"+format, func(t *testing.T) {
+				cmd := NewKeyGenerateCmd()
+
+				args := []string{"--type=" + tv.keyType, "--format=" + format}
+				cmd.SetArgs(args)
+
+				fs = afero.NewMemMapFs()
+
+				err := cmd.Execute()
+				require.NoError(t, err)
+
+				data, err := afero.ReadFile(fs, tv.keyType+"."+format)
+				require.NoError(t, err)
+
+				signer, err := newSignerFromKeyData(data, nil)
+				require.NoError(t, err)
+
+				pub, err := newPublicKeyFromKeyData(data)
+				require.NoError(t, err)
+
+				alg, err := algFromKey(pub)
+				require.NoError(t, err)
+				assert.Equal(t, tv.alg, alg)
+
+				// check that the key is usable for COSE signing
+				sig, err := signer.Sign(rand.Reader, []byte("tbs"))
+				require.NoError(t, err)
+
+				verifier, err := cose.NewVerifier(signer.Algorithm(), pub)
+				require.NoError(t, err)
+				assert.NoError(t, verifier.Verify([]byte("tbs"), sig))
+			})
+		}
+	}
+}
+
+func Test_KeyGenerateCmd_custom_output_file(t *testing.T) {
+	cmd := NewKeyGenerateCmd()
+
+	args := []string{"--format=pem", "--output=signer.pem"}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	info, err := fs.Stat("signer.pem")
+	require.NoError(t, err)
+	assert.Equal(t, "-rw-------", info.Mode().Perm().String())
+}
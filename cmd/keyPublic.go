@@ -0,0 +1,126 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var (
+	keyPublicKeyFile    *string
+	keyPublicFormat     *string
+	keyPublicOutputFile *string
+)
+
+var keyPublicCmd = NewKeyPublicCmd()
+
+func NewKeyPublicCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "public",
+		Short: "export the public part of a signing key",
+		Long: `export the public part of a signing key
+
+    Export the public key corresponding to the private key in signer.jwk and
+    save it, in JWK format, to signer-pub.jwk
+
+      cocli key public --key=signer.jwk
+
+    Export the same public key as a PEM SubjectPublicKeyInfo to pub.pem
+
+      cocli key public --key=signer.jwk --format=pem --output=pub.pem
+
+    The key can be in any of the formats accepted by corim sign.  When
+    exporting a JWK to JWK, the key parameters (e.g., "kid" and "alg") are
+    preserved.
+    `,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkKeyPublicArgs(); err != nil {
+				return err
+			}
+
+			pubFile, err := exportPublicKey(*keyPublicKeyFile, *keyPublicFormat, *keyPublicOutputFile)
+			if err != nil {
+				return err
+			}
+			fmt.Printf(">> public key from %q saved to %q\n", *keyPublicKeyFile, pubFile)
+
+			return nil
+		},
+	}
+
+	keyPublicKeyFile = cmd.Flags().StringP("key", "k", "", "private key (JWK, or PEM/DER-encoded)")
+	keyPublicFormat = cmd.Flags().String("format", keyFormatJWK, "public key format: jwk or pem")
+	keyPublicOutputFile = cmd.Flags().StringP(
+		"output", "o", "", "name of the public key file (default <key>-pub.<format>)",
+	)
+
+	return cmd
+}
+
+func checkKeyPublicArgs() error {
+	if keyPublicKeyFile == nil || *keyPublicKeyFile == "" {
+		return errors.New("no key supplied")
+	}
+
+	return checkKeyFormat(*keyPublicFormat)
+}
+
+func exportPublicKey(keyFile, format, outputFile string) (string, error) {
+	keyData, err := afero.ReadFile(fs, keyFile)
+	if err != nil {
+		return "", fmt.Errorf("error loading key from %s: %w", keyFile, err)
+	}
+
+	var data []byte
+
+	if format == keyFormatJWK && isJWK(keyData) {
+		data, err = publicJWK(keyData)
+	} else {
+		pub, loadErr := newPublicKeyFromKeyData(keyData)
+		if loadErr != nil {
+			return "", fmt.Errorf("error loading key from %s: %w", keyFile, loadErr)
+		}
+		data, err = encodePublicKey(pub, format)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("error encoding public key from %s: %w", keyFile, err)
+	}
+
+	if outputFile == "" {
+		outputFile = makeFileName("", keyFile, "-pub."+format)
+	}
+
+	if err = afero.WriteFile(fs, outputFile, data, 0644); err != nil {
+		return "", fmt.Errorf("error saving public key to file %s: %w", outputFile, err)
+	}
+
+	return outputFile, nil
+}
+
+// publicJWK strips the private parameters from the supplied JWK
+func publicJWK(data []byte) ([]byte, error) {
+	k, err := jwk.ParseKey(data)
+	if err != nil {
+		return nil, err
+	}
+
+	pub, err := k.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(pub, "", "  ")
+}
+
+func init() {
+	keyCmd.AddCommand(keyPublicCmd)
+}
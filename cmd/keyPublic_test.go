@@ -0,0 +1,119 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_KeyPublicCmd_no_key(t *testing.T) {
+	cmd := NewKeyPublicCmd()
+
+	args := []string{}
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "no key supplied")
+}
+
+func Test_KeyPublicCmd_non_existent_key_file(t *testing.T) {
+	cmd := NewKeyPublicCmd()
+
+	args := []string{"--key=nonexistent.jwk"}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "error loading key from nonexistent.jwk: open nonexistent.jwk: file does not exist")
+}
+
+func Test_KeyPublicCmd_jwk_to_jwk_ok(t *testing.T) {
+	cmd := NewKeyPublicCmd()
+
+	args := []string{"--key=ec-p256.jwk"}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "ec-p256.jwk", testECKey, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	data, err := afero.ReadFile(fs, "ec-p256-pub.jwk")
+	require.NoError(t, err)
+
+	var jwk map[string]any
+	require.NoError(t, json.Unmarshal(data, &jwk))
+	assert.NotContains(t, jwk, "d")
+	assert.Equal(t, "1", jwk["kid"])
+
+	expected, err := newPublicKeyFromKeyData(testECKey)
+	require.NoError(t, err)
+	actual, err := newPublicKeyFromKeyData(data)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func Test_KeyPublicCmd_pem_to_pem_ok(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	cmd := NewKeyPublicCmd()
+
+	args := []string{"--key=key.pem", "--format=pem", "--output=pub.pem"}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "key.pem", pemEncode("PRIVATE KEY", mustPKCS8(t, key)), 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	data, err := afero.ReadFile(fs, "pub.pem")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "-----BEGIN PUBLIC KEY-----")
+
+	pub, err := newPublicKeyFromKeyData(data)
+	require.NoError(t, err)
+	assert.True(t, key.PublicKey.Equal(pub))
+}
+
+func Test_KeyPublicCmd_pem_to_jwk_ok(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	cmd := NewKeyPublicCmd()
+
+	args := []string{"--key=key.pem"}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "key.pem", pemEncode("PRIVATE KEY", mustPKCS8(t, key)), 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	data, err := afero.ReadFile(fs, "key-pub.jwk")
+	require.NoError(t, err)
+
+	var jwk map[string]any
+	require.NoError(t, json.Unmarshal(data, &jwk))
+	assert.Equal(t, "ES256", jwk["alg"])
+
+	pub, err := newPublicKeyFromKeyData(data)
+	require.NoError(t, err)
+	assert.True(t, key.PublicKey.Equal(pub))
+}
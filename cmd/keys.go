@@ -0,0 +1,377 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/veraison/corim/corim"
+	cose "github.com/veraison/go-cose"
+	"github.com/youmark/pkcs8"
+	"golang.org/x/term"
+)
+
+// passphraseFunc supplies the passphrase of an encrypted private key.  It is
+// only invoked if the key turns out to be encrypted.
+type passphraseFunc func() ([]byte, error)
+
+// keyPassphrase returns a passphraseFunc that reads the passphrase from the
+// supplied file if set, otherwise from the "passphrase" configuration value
+// (e.g., the COCLI_PASSPHRASE environment variable) and, as a last resort,
+// prompts for it on the terminal
+func keyPassphrase(passphraseFile string) passphraseFunc {
+	return func() ([]byte, error) {
+		if passphraseFile != "" {
+			data, err := afero.ReadFile(fs, passphraseFile)
+			if err != nil {
+				return nil, fmt.Errorf("error loading passphrase from %s: %w", passphraseFile, err)
+			}
+			return bytes.TrimRight(data, "\r\n"), nil
+		}
+
+		if p := viper.GetString("passphrase"); p != "" {
+			return []byte(p), nil
+		}
+
+		return promptPassphrase()
+	}
+}
+
+func promptPassphrase() ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+
+	if !term.IsTerminal(fd) {
+		return nil, errors.New(
+			"no passphrase supplied: use --passphrase-file, set COCLI_PASSPHRASE, or run interactively",
+		)
+	}
+
+	fmt.Fprint(os.Stderr, "Enter passphrase for the signing key: ")
+	defer fmt.Fprintln(os.Stderr)
+
+	return term.ReadPassword(fd)
+}
+
+// isJWK tells whether the supplied key data looks like a JSON Web Key
+func isJWK(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("{"))
+}
+
+// newSignerFromKeyData creates a COSE signer from the supplied private key,
+// which can be a JWK, a PEM-encoded (PKCS#8, SEC1 or PKCS#1) private key, or
+// its DER-encoded equivalent.  Encrypted PKCS#8 PEM keys are decrypted in
+// memory using the passphrase from getPassphrase.  Except for JWK, where the
+// "alg" parameter is honoured, the COSE algorithm is derived from the key type.
+func newSignerFromKeyData(data []byte, getPassphrase passphraseFunc) (cose.Signer, error) {
+	if isJWK(data) {
+		return corim.NewSignerFromJWK(data)
+	}
+
+	key, err := parsePrivateKey(data, getPassphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	alg, err := algFromKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	return cose.NewSigner(alg, key)
+}
+
+// newPublicKeyFromKeyData extracts a verification key from the supplied data,
+// which can be a (public or private) JWK, a PEM or DER-encoded public key
+// (SubjectPublicKeyInfo or PKCS#1), an X.509 certificate, or a private key in
+// any of the formats accepted by newSignerFromKeyData
+func newPublicKeyFromKeyData(data []byte) (crypto.PublicKey, error) {
+	if isJWK(data) {
+		pk, err := corim.NewPublicKeyFromJWK(data)
+		if err == nil {
+			return pk, nil
+		}
+
+		// corim.NewPublicKeyFromJWK only deals with private JWKs
+		if pub, jwkErr := publicKeyFromJWK(data); jwkErr == nil {
+			return pub, nil
+		}
+
+		return nil, err
+	}
+
+	if block, _ := pem.Decode(data); block != nil {
+		switch block.Type {
+		case "PUBLIC KEY":
+			return x509.ParsePKIXPublicKey(block.Bytes)
+		case "RSA PUBLIC KEY":
+			return x509.ParsePKCS1PublicKey(block.Bytes)
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			return cert.PublicKey, nil
+		}
+	} else {
+		if pub, err := x509.ParsePKIXPublicKey(data); err == nil {
+			return pub, nil
+		}
+		if pub, err := x509.ParsePKCS1PublicKey(data); err == nil {
+			return pub, nil
+		}
+		if cert, err := x509.ParseCertificate(data); err == nil {
+			return cert.PublicKey, nil
+		}
+	}
+
+	key, err := parsePrivateKey(data, nil)
+	if err != nil {
+		return nil, errors.New("unknown key format: expecting JWK, public key, certificate or private key")
+	}
+
+	return key.Public(), nil
+}
+
+func publicKeyFromJWK(data []byte) (crypto.PublicKey, error) {
+	k, err := jwk.ParseKey(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return jwkPublicKey(k)
+}
+
+// jwkPublicKey returns the public key corresponding to the supplied (public or
+// private) JWK
+func jwkPublicKey(k jwk.Key) (crypto.PublicKey, error) {
+	var raw any
+	if err := k.Raw(&raw); err != nil {
+		return nil, err
+	}
+
+	switch v := raw.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return v, nil
+	case crypto.Signer:
+		return v.Public(), nil
+	default:
+		return nil, fmt.Errorf("unsupported JWK key type %v", reflect.TypeOf(raw))
+	}
+}
+
+// jwkKeyID returns the "kid" parameter of the supplied key data, or an empty
+// string if the data is not a JWK or has no "kid"
+func jwkKeyID(data []byte) string {
+	if !isJWK(data) {
+		return ""
+	}
+
+	k, err := jwk.ParseKey(data)
+	if err != nil {
+		return ""
+	}
+
+	return k.KeyID()
+}
+
+// parsePrivateKey decodes a PEM or DER-encoded private key in PKCS#8, SEC1 or
+// PKCS#1 format, or an encrypted PKCS#8 private key in PEM format
+func parsePrivateKey(data []byte, getPassphrase passphraseFunc) (crypto.Signer, error) {
+	der := data
+	if block, _ := pem.Decode(data); block != nil {
+		switch block.Type {
+		case "ENCRYPTED PRIVATE KEY":
+			return parseEncryptedPrivateKey(block.Bytes, getPassphrase)
+		case "PRIVATE KEY", "EC PRIVATE KEY", "RSA PRIVATE KEY":
+			der = block.Bytes
+		default:
+			return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+		}
+	}
+
+	if k, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		signer, ok := k.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %v", reflect.TypeOf(k))
+		}
+		return signer, nil
+	}
+
+	if k, err := x509.ParseECPrivateKey(der); err == nil {
+		return k, nil
+	}
+
+	if k, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return k, nil
+	}
+
+	return nil, errors.New("unknown private key format: expecting PKCS#8, SEC1 or PKCS#1")
+}
+
+func parseEncryptedPrivateKey(der []byte, getPassphrase passphraseFunc) (crypto.Signer, error) {
+	if getPassphrase == nil {
+		return nil, errors.New("no passphrase available for the encrypted private key")
+	}
+
+	passphrase, err := getPassphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	k, _, err := pkcs8.ParsePrivateKey(der, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting private key: %w", err)
+	}
+
+	signer, ok := k.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %v", reflect.TypeOf(k))
+	}
+
+	return signer, nil
+}
+
+// algFromKey derives the COSE signature algorithm from the public key type.
+// RSA keys use RSASSA-PSS with a hash size matching the modulus size.
+func algFromKey(pub crypto.PublicKey) (cose.Algorithm, error) {
+	switch v := pub.(type) {
+	case *ecdsa.PublicKey:
+		switch v.Curve {
+		case elliptic.P256():
+			return cose.AlgorithmES256, nil
+		case elliptic.P384():
+			return cose.AlgorithmES384, nil
+		case elliptic.P521():
+			return cose.AlgorithmES512, nil
+		default:
+			return 0, fmt.Errorf("unsupported elliptic curve %s", v.Curve.Params().Name)
+		}
+	case ed25519.PublicKey:
+		return cose.AlgorithmEdDSA, nil
+	case *rsa.PublicKey:
+		switch bits := v.N.BitLen(); {
+		case bits >= 4096:
+			return cose.AlgorithmPS512, nil
+		case bits >= 3072:
+			return cose.AlgorithmPS384, nil
+		case bits >= 2048:
+			return cose.AlgorithmPS256, nil
+		default:
+			return 0, fmt.Errorf("RSA key too short (%d bits)", bits)
+		}
+	default:
+		return 0, fmt.Errorf("unsupported key type %v", reflect.TypeOf(pub))
+	}
+}
+
+// keyTypes lists the key types that can be generated, in the format accepted
+// by generateKey
+var keyTypes = []string{
+	"ec-p256", "ec-p384", "ec-p521", "ed25519", "rsa-2048", "rsa-3072", "rsa-4096",
+}
+
+// generateKey creates a new private key of the supplied type (see keyTypes)
+func generateKey(keyType string) (crypto.Signer, error) {
+	switch keyType {
+	case "ec-p256":
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ec-p384":
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case "ec-p521":
+		return ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case "ed25519":
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	case "rsa-2048":
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case "rsa-3072":
+		return rsa.GenerateKey(rand.Reader, 3072)
+	case "rsa-4096":
+		return rsa.GenerateKey(rand.Reader, 4096)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q, expecting one of %v", keyType, keyTypes)
+	}
+}
+
+const (
+	keyFormatJWK = "jwk"
+	keyFormatPEM = "pem"
+)
+
+func checkKeyFormat(format string) error {
+	if format != keyFormatJWK && format != keyFormatPEM {
+		return fmt.Errorf("unsupported key format %q, expecting %q or %q", format, keyFormatJWK, keyFormatPEM)
+	}
+
+	return nil
+}
+
+// encodePrivateKey serializes the private key either as a JWK or as a PKCS#8
+// PEM.  The JWK "alg" parameter is set to the COSE algorithm derived from the
+// key type, since it is needed to pick the RSASSA-PSS variant for RSA keys.
+func encodePrivateKey(key crypto.Signer, format string) ([]byte, error) {
+	switch format {
+	case keyFormatJWK:
+		return encodeJWK(key, key.Public())
+	case keyFormatPEM:
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+	default:
+		return nil, checkKeyFormat(format)
+	}
+}
+
+// encodePublicKey serializes the public key either as a JWK or as a PEM
+// SubjectPublicKeyInfo
+func encodePublicKey(pub crypto.PublicKey, format string) ([]byte, error) {
+	switch format {
+	case keyFormatJWK:
+		return encodeJWK(pub, pub)
+	case keyFormatPEM:
+		der, err := x509.MarshalPKIXPublicKey(pub)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+	default:
+		return nil, checkKeyFormat(format)
+	}
+}
+
+func encodeJWK(raw any, pub crypto.PublicKey) ([]byte, error) {
+	alg, err := algFromKey(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := jwk.FromRaw(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = k.Set(jwk.AlgorithmKey, alg.String()); err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(k, "", "  ")
+}
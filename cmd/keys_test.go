@@ -0,0 +1,133 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cose "github.com/veraison/go-cose"
+)
+
+// testECPublicKeyJWK is the public part of testECKey
+var testECPublicKeyJWK = []byte(`{
+  "kty": "EC",
+  "crv": "P-256",
+  "x": "MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4",
+  "y": "4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM"
+}`)
+
+func pemEncode(typ string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+}
+
+func mustPKCS8(t *testing.T, key crypto.Signer) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return der
+}
+
+func Test_newSignerFromKeyData_ok(t *testing.T) {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	_, ed, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsa2k, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	sec1, err := x509.MarshalECPrivateKey(p256)
+	require.NoError(t, err)
+
+	tvs := []struct {
+		name     string
+		data     []byte
+		expected cose.Algorithm
+	}{
+		{"JWK", testECKey, cose.AlgorithmES256},
+		{"PKCS#8 PEM (P-256)", pemEncode("PRIVATE KEY", mustPKCS8(t, p256)), cose.AlgorithmES256},
+		{"PKCS#8 DER (P-384)", mustPKCS8(t, p384), cose.AlgorithmES384},
+		{"SEC1 PEM", pemEncode("EC PRIVATE KEY", sec1), cose.AlgorithmES256},
+		{"PKCS#8 PEM (Ed25519)", pemEncode("PRIVATE KEY", mustPKCS8(t, ed)), cose.AlgorithmEdDSA},
+		{"PKCS#1 PEM", pemEncode("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsa2k)), cose.AlgorithmPS256},
+	}
+
+	for _, tv := range tvs {
+		t.Run(tv.name, func(t *testing.T) {
+			signer, err := newSignerFromKeyData(tv.data, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tv.expected, signer.Algorithm())
+		})
+	}
+}
+
+func Test_newSignerFromKeyData_bad(t *testing.T) {
+	_, err := newSignerFromKeyData(pemEncode("PUBLIC KEY", []byte{0x00}), nil)
+	assert.EqualError(t, err, `unsupported PEM block type "PUBLIC KEY"`)
+
+	_, err = newSignerFromKeyData([]byte("rubbish"), nil)
+	assert.EqualError(t, err, "unknown private key format: expecting PKCS#8, SEC1 or PKCS#1")
+}
+
+func Test_newPublicKeyFromKeyData_ok(t *testing.T) {
+	pki := makeTestPKI(t)
+
+	leaf, err := x509.ParseCertificate(pki.Leaf)
+	require.NoError(t, err)
+
+	spki, err := x509.MarshalPKIXPublicKey(pki.LeafKey.Public())
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	pkcs1 := x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)
+
+	tvs := []struct {
+		name     string
+		data     []byte
+		expected crypto.PublicKey
+	}{
+		{"SPKI PEM", pemEncode("PUBLIC KEY", spki), leaf.PublicKey},
+		{"SPKI DER", spki, leaf.PublicKey},
+		{"PKCS#1 PEM", pemEncode("RSA PUBLIC KEY", pkcs1), &rsaKey.PublicKey},
+		{"PKCS#1 DER", pkcs1, &rsaKey.PublicKey},
+		{"certificate PEM", pemEncode("CERTIFICATE", pki.Leaf), leaf.PublicKey},
+		{"certificate DER", pki.Leaf, leaf.PublicKey},
+		{"PKCS#8 PEM", pemEncode("PRIVATE KEY", mustPKCS8(t, pki.LeafKey)), leaf.PublicKey},
+	}
+
+	for _, tv := range tvs {
+		t.Run(tv.name, func(t *testing.T) {
+			pub, err := newPublicKeyFromKeyData(tv.data)
+			require.NoError(t, err)
+			assert.Equal(t, tv.expected, pub)
+		})
+	}
+}
+
+func Test_newPublicKeyFromKeyData_jwk(t *testing.T) {
+	fromPrivate, err := newPublicKeyFromKeyData(testECKey)
+	require.NoError(t, err)
+
+	fromPublic, err := newPublicKeyFromKeyData(testECPublicKeyJWK)
+	require.NoError(t, err)
+
+	assert.Equal(t, fromPrivate, fromPublic)
+}
+
+func Test_newPublicKeyFromKeyData_bad(t *testing.T) {
+	_, err := newPublicKeyFromKeyData([]byte("rubbish"))
+	assert.EqualError(t, err, "unknown key format: expecting JWK, public key, certificate or private key")
+}
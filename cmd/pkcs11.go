@@ -0,0 +1,140 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const pkcs11URIScheme = "pkcs11:"
+
+// pkcs11URI holds the subset of the PKCS#11 URI attributes (RFC 7512) that are
+// used to locate a signing key on a token
+type pkcs11URI struct {
+	Token      string
+	Serial     string
+	SlotID     *uint
+	Object     string
+	ID         []byte
+	PIN        string
+	ModulePath string
+}
+
+func isPKCS11URI(s string) bool {
+	return strings.HasPrefix(s, pkcs11URIScheme)
+}
+
+// parsePKCS11URI decodes a PKCS#11 URI, e.g.:
+//
+//	pkcs11:token=cocli;object=corim-signer?pin-value=1234
+func parsePKCS11URI(s string) (*pkcs11URI, error) {
+	if !isPKCS11URI(s) {
+		return nil, fmt.Errorf("missing %q scheme", pkcs11URIScheme)
+	}
+
+	path, query, _ := strings.Cut(strings.TrimPrefix(s, pkcs11URIScheme), "?")
+
+	var u pkcs11URI
+
+	for _, attr := range strings.Split(path, ";") {
+		if attr == "" {
+			continue
+		}
+
+		name, rawValue, ok := strings.Cut(attr, "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed path attribute %q", attr)
+		}
+
+		value, err := url.PathUnescape(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("malformed value for path attribute %q: %w", name, err)
+		}
+
+		switch name {
+		case "token":
+			u.Token = value
+		case "serial":
+			u.Serial = value
+		case "slot-id":
+			id, err := strconv.ParseUint(value, 10, 0)
+			if err != nil {
+				return nil, fmt.Errorf("malformed slot-id: %w", err)
+			}
+			slotID := uint(id)
+			u.SlotID = &slotID
+		case "object":
+			u.Object = value
+		case "id":
+			u.ID = []byte(value)
+		case "type":
+			if value != "private" {
+				return nil, fmt.Errorf("unsupported object type %q, expecting \"private\"", value)
+			}
+		default:
+			// other attributes (manufacturer, model, library-*) are
+			// accepted but not used for key selection
+		}
+	}
+
+	for _, attr := range strings.Split(query, "&") {
+		if attr == "" {
+			continue
+		}
+
+		name, rawValue, ok := strings.Cut(attr, "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed query attribute %q", attr)
+		}
+
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("malformed value for query attribute %q: %w", name, err)
+		}
+
+		switch name {
+		case "pin-value":
+			u.PIN = value
+		case "module-path":
+			u.ModulePath = value
+		}
+	}
+
+	if u.Object == "" && u.ID == nil {
+		return nil, errors.New("at least one of object or id must be specified")
+	}
+
+	return &u, nil
+}
+
+// resolvePKCS11URI parses the supplied PKCS#11 URI and fills in the module
+// path and PIN from the configuration (pkcs11_module and pkcs11_pin, or the
+// COCLI_PKCS11_MODULE and COCLI_PKCS11_PIN environment variables) when they are
+// not given in the URI itself
+func resolvePKCS11URI(s string) (*pkcs11URI, error) {
+	u, err := parsePKCS11URI(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.ModulePath == "" {
+		u.ModulePath = viper.GetString("pkcs11_module")
+	}
+
+	if u.ModulePath == "" {
+		return nil, errors.New("no PKCS#11 module supplied: set module-path in the URI or pkcs11_module in the configuration")
+	}
+
+	if u.PIN == "" {
+		u.PIN = viper.GetString("pkcs11_pin")
+	}
+
+	return u, nil
+}
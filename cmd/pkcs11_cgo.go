@@ -0,0 +1,350 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build cgo
+
+package cmd
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/miekg/pkcs11"
+	cose "github.com/veraison/go-cose"
+)
+
+var (
+	oidNamedCurveP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidNamedCurveP384 = asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+	oidNamedCurveP521 = asn1.ObjectIdentifier{1, 3, 132, 0, 35}
+)
+
+// pkcs11Signer is a crypto.Signer that delegates the signing operation to a
+// private key stored in a PKCS#11 token
+type pkcs11Signer struct {
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+	key     pkcs11.ObjectHandle
+	pub     crypto.PublicKey
+}
+
+// newPKCS11Signer creates a COSE signer backed by the private key identified
+// by the supplied PKCS#11 URI.  The returned function must be called to
+// release the token resources once signing is done.
+func newPKCS11Signer(uri string) (cose.Signer, func(), error) {
+	u, err := resolvePKCS11URI(uri)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx := pkcs11.New(u.ModulePath)
+	if ctx == nil {
+		return nil, nil, fmt.Errorf("error loading PKCS#11 module %s", u.ModulePath)
+	}
+
+	if err = ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, nil, fmt.Errorf("error initializing PKCS#11 module %s: %w", u.ModulePath, err)
+	}
+
+	p := &pkcs11Signer{ctx: ctx}
+
+	signer, err := p.open(u)
+	if err != nil {
+		p.close()
+		return nil, nil, err
+	}
+
+	return signer, p.close, nil
+}
+
+func (p *pkcs11Signer) open(u *pkcs11URI) (cose.Signer, error) {
+	slot, err := findPKCS11Slot(p.ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.session, err = p.ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION); err != nil {
+		return nil, fmt.Errorf("error opening PKCS#11 session: %w", err)
+	}
+
+	if u.PIN != "" {
+		err = p.ctx.Login(p.session, pkcs11.CKU_USER, u.PIN)
+		if e, ok := err.(pkcs11.Error); err != nil && (!ok || e != pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+			return nil, fmt.Errorf("error logging into PKCS#11 token: %w", err)
+		}
+	}
+
+	if p.key, err = p.findObject(pkcs11.CKO_PRIVATE_KEY, u); err != nil {
+		return nil, fmt.Errorf("error looking up private key: %w", err)
+	}
+
+	if p.pub, err = p.publicKey(u); err != nil {
+		return nil, err
+	}
+
+	alg, err := algFromKey(p.pub)
+	if err != nil {
+		return nil, err
+	}
+
+	return cose.NewSigner(alg, p)
+}
+
+func (p *pkcs11Signer) close() {
+	if p.session != 0 {
+		_ = p.ctx.CloseSession(p.session)
+	}
+	_ = p.ctx.Finalize()
+	p.ctx.Destroy()
+}
+
+func findPKCS11Slot(ctx *pkcs11.Ctx, u *pkcs11URI) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("error listing PKCS#11 slots: %w", err)
+	}
+
+	for _, slot := range slots {
+		if u.SlotID != nil && *u.SlotID != slot {
+			continue
+		}
+
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			return 0, fmt.Errorf("error getting PKCS#11 token info for slot %d: %w", slot, err)
+		}
+
+		if u.Token != "" && strings.TrimRight(info.Label, " ") != u.Token {
+			continue
+		}
+
+		if u.Serial != "" && strings.TrimRight(info.SerialNumber, " ") != u.Serial {
+			continue
+		}
+
+		return slot, nil
+	}
+
+	return 0, errors.New("no matching PKCS#11 token found")
+}
+
+func (p *pkcs11Signer) findObject(class uint, u *pkcs11URI) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+	}
+
+	if u.Object != "" {
+		template = append(template, pkcs11.NewAttribute(pkcs11.CKA_LABEL, u.Object))
+	}
+
+	if u.ID != nil {
+		template = append(template, pkcs11.NewAttribute(pkcs11.CKA_ID, u.ID))
+	}
+
+	if err := p.ctx.FindObjectsInit(p.session, template); err != nil {
+		return 0, err
+	}
+
+	objs, _, err := p.ctx.FindObjects(p.session, 2)
+	if finalErr := p.ctx.FindObjectsFinal(p.session); err == nil {
+		err = finalErr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	switch len(objs) {
+	case 0:
+		return 0, errors.New("no matching object found")
+	case 1:
+		return objs[0], nil
+	default:
+		return 0, errors.New("more than one matching object found")
+	}
+}
+
+// publicKey reads the public key corresponding to the signing key from the
+// matching public key object
+func (p *pkcs11Signer) publicKey(u *pkcs11URI) (crypto.PublicKey, error) {
+	obj, err := p.findObject(pkcs11.CKO_PUBLIC_KEY, u)
+	if err != nil {
+		return nil, fmt.Errorf("error looking up public key: %w", err)
+	}
+
+	attrs, err := p.ctx.GetAttributeValue(p.session, obj, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading public key type: %w", err)
+	}
+
+	switch keyType := bytesToUint(attrs[0].Value); keyType {
+	case pkcs11.CKK_EC:
+		return p.ecPublicKey(obj)
+	case pkcs11.CKK_RSA:
+		return p.rsaPublicKey(obj)
+	default:
+		return nil, fmt.Errorf("unsupported PKCS#11 key type 0x%x", keyType)
+	}
+}
+
+func (p *pkcs11Signer) ecPublicKey(obj pkcs11.ObjectHandle) (crypto.PublicKey, error) {
+	attrs, err := p.ctx.GetAttributeValue(p.session, obj, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading EC public key: %w", err)
+	}
+
+	var (
+		oid   asn1.ObjectIdentifier
+		curve elliptic.Curve
+	)
+
+	if _, err = asn1.Unmarshal(attrs[0].Value, &oid); err != nil {
+		return nil, fmt.Errorf("error decoding EC parameters: %w", err)
+	}
+
+	switch {
+	case oid.Equal(oidNamedCurveP256):
+		curve = elliptic.P256()
+	case oid.Equal(oidNamedCurveP384):
+		curve = elliptic.P384()
+	case oid.Equal(oidNamedCurveP521):
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported EC curve %s", oid)
+	}
+
+	// the point is usually wrapped in a DER OCTET STRING, but some tokens
+	// return the raw uncompressed point
+	point := attrs[1].Value
+	var wrapped []byte
+	if rest, err := asn1.Unmarshal(point, &wrapped); err == nil && len(rest) == 0 {
+		point = wrapped
+	}
+
+	size := (curve.Params().BitSize + 7) / 8
+	if len(point) != 1+2*size || point[0] != 4 {
+		return nil, errors.New("unsupported EC point encoding")
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(point[1 : 1+size]),
+		Y:     new(big.Int).SetBytes(point[1+size:]),
+	}, nil
+}
+
+func (p *pkcs11Signer) rsaPublicKey(obj pkcs11.ObjectHandle) (crypto.PublicKey, error) {
+	attrs, err := p.ctx.GetAttributeValue(p.session, obj, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_MODULUS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading RSA public key: %w", err)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(attrs[0].Value),
+		E: int(new(big.Int).SetBytes(attrs[1].Value).Int64()),
+	}, nil
+}
+
+// Public returns the public key corresponding to the token's private key
+func (p *pkcs11Signer) Public() crypto.PublicKey {
+	return p.pub
+}
+
+// Sign signs the digest with the token's private key.  ECDSA signatures are
+// returned ASN.1 encoded, as expected from a crypto.Signer.  RSA keys only
+// support RSASSA-PSS.
+func (p *pkcs11Signer) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	switch p.pub.(type) {
+	case *ecdsa.PublicKey:
+		sig, err := p.sign(pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil), digest)
+		if err != nil {
+			return nil, err
+		}
+
+		// PKCS#11 returns r || s
+		n := len(sig) / 2
+		return asn1.Marshal(struct{ R, S *big.Int }{
+			new(big.Int).SetBytes(sig[:n]),
+			new(big.Int).SetBytes(sig[n:]),
+		})
+	case *rsa.PublicKey:
+		pss, ok := opts.(*rsa.PSSOptions)
+		if !ok {
+			return nil, errors.New("only RSASSA-PSS is supported for RSA keys")
+		}
+
+		hashMech, mgf, err := pkcs11PSSHash(pss.HashFunc())
+		if err != nil {
+			return nil, err
+		}
+
+		saltLength := pss.SaltLength
+		if saltLength == rsa.PSSSaltLengthEqualsHash {
+			saltLength = pss.HashFunc().Size()
+		}
+		if saltLength < 0 {
+			return nil, fmt.Errorf("unsupported PSS salt length %d", pss.SaltLength)
+		}
+
+		params := pkcs11.NewPSSParams(hashMech, mgf, uint(saltLength))
+
+		return p.sign(pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS_PSS, params), digest)
+	default:
+		return nil, errors.New("unsupported key type")
+	}
+}
+
+func (p *pkcs11Signer) sign(mech *pkcs11.Mechanism, digest []byte) ([]byte, error) {
+	if err := p.ctx.SignInit(p.session, []*pkcs11.Mechanism{mech}, p.key); err != nil {
+		return nil, fmt.Errorf("PKCS#11 sign init failed: %w", err)
+	}
+
+	sig, err := p.ctx.Sign(p.session, digest)
+	if err != nil {
+		return nil, fmt.Errorf("PKCS#11 sign failed: %w", err)
+	}
+
+	return sig, nil
+}
+
+func pkcs11PSSHash(h crypto.Hash) (uint, uint, error) {
+	switch h {
+	case crypto.SHA256:
+		return pkcs11.CKM_SHA256, pkcs11.CKG_MGF1_SHA256, nil
+	case crypto.SHA384:
+		return pkcs11.CKM_SHA384, pkcs11.CKG_MGF1_SHA384, nil
+	case crypto.SHA512:
+		return pkcs11.CKM_SHA512, pkcs11.CKG_MGF1_SHA512, nil
+	default:
+		return 0, 0, fmt.Errorf("unsupported hash function %v", h)
+	}
+}
+
+// bytesToUint decodes a CK_ULONG attribute value, which is in host byte order
+func bytesToUint(b []byte) uint {
+	switch len(b) {
+	case 4:
+		return uint(binary.NativeEndian.Uint32(b))
+	case 8:
+		return uint(binary.NativeEndian.Uint64(b))
+	default:
+		return ^uint(0)
+	}
+}
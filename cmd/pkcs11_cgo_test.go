@@ -0,0 +1,153 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build cgo
+
+package cmd
+
+import (
+	"encoding/asn1"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/miekg/pkcs11"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/corim/corim"
+)
+
+// setupSoftHSM initializes a fresh SoftHSM token containing an EC P-256 key
+// pair labelled "corim-signer".  The test is skipped unless the path to the
+// SoftHSM module is supplied via COCLI_TEST_SOFTHSM2_MODULE, e.g.:
+//
+//	COCLI_TEST_SOFTHSM2_MODULE=/usr/lib/softhsm/libsofthsm2.so go test ./cmd/...
+func setupSoftHSM(t *testing.T) (module, pin string) {
+	module = os.Getenv("COCLI_TEST_SOFTHSM2_MODULE")
+	if module == "" {
+		t.Skip("COCLI_TEST_SOFTHSM2_MODULE not set")
+	}
+
+	pin = "1234"
+
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "softhsm2.conf")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "tokens"), 0700))
+	require.NoError(t, os.WriteFile(conf, []byte("directories.tokendir = "+filepath.Join(dir, "tokens")+"\n"), 0600))
+	t.Setenv("SOFTHSM2_CONF", conf)
+
+	ctx := pkcs11.New(module)
+	require.NotNil(t, ctx)
+	require.NoError(t, ctx.Initialize())
+	defer func() {
+		_ = ctx.Finalize()
+		ctx.Destroy()
+	}()
+
+	slots, err := ctx.GetSlotList(false)
+	require.NoError(t, err)
+	require.NotEmpty(t, slots)
+
+	require.NoError(t, ctx.InitToken(slots[0], "so-pin", "cocli"))
+
+	// SoftHSM moves the initialized token to a new slot
+	slots, err = ctx.GetSlotList(true)
+	require.NoError(t, err)
+	require.NotEmpty(t, slots)
+
+	session, err := ctx.OpenSession(slots[0], pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	require.NoError(t, err)
+	defer func() { _ = ctx.CloseSession(session) }()
+
+	require.NoError(t, ctx.Login(session, pkcs11.CKU_SO, "so-pin"))
+	require.NoError(t, ctx.InitPIN(session, pin))
+	require.NoError(t, ctx.Logout(session))
+	require.NoError(t, ctx.Login(session, pkcs11.CKU_USER, pin))
+
+	ecParams, err := asn1.Marshal(oidNamedCurveP256)
+	require.NoError(t, err)
+
+	_, _, err = ctx.GenerateKeyPair(session,
+		[]*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_EC_KEY_PAIR_GEN, nil)},
+		[]*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, ecParams),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, "corim-signer"),
+		},
+		[]*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, "corim-signer"),
+		},
+	)
+	require.NoError(t, err)
+
+	return module, pin
+}
+
+func Test_CorimSignCmd_pkcs11_ok(t *testing.T) {
+	module, pin := setupSoftHSM(t)
+
+	cmd := NewCorimSignCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--key=pkcs11:token=cocli;object=corim-signer?pin-value=" + pin + "&module-path=" + module,
+		"--meta=ok.json",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "ok.cbor", testCorimValid, 0644)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, "ok.json", testMetaValid, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	data, err := afero.ReadFile(fs, "signed-ok.cbor")
+	require.NoError(t, err)
+
+	// verify using the public key read back from the token
+	u, err := resolvePKCS11URI("pkcs11:token=cocli;object=corim-signer?pin-value=" + pin + "&module-path=" + module)
+	require.NoError(t, err)
+
+	ctx := pkcs11.New(module)
+	require.NotNil(t, ctx)
+	require.NoError(t, ctx.Initialize())
+
+	p := &pkcs11Signer{ctx: ctx}
+	defer p.close()
+
+	_, err = p.open(u)
+	require.NoError(t, err)
+
+	var s corim.SignedCorim
+	require.NoError(t, s.FromCOSE(data))
+	assert.NoError(t, s.Verify(p.Public()))
+}
+
+func Test_CorimSignCmd_pkcs11_bad_module(t *testing.T) {
+	cmd := NewCorimSignCmd()
+
+	args := []string{
+		"--file=ok.cbor",
+		"--key=pkcs11:object=corim-signer?module-path=/nonexistent/module.so",
+		"--meta=ok.json",
+	}
+	cmd.SetArgs(args)
+
+	fs = afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "ok.cbor", testCorimValid, 0644)
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, "ok.json", testMetaValid, 0644)
+	require.NoError(t, err)
+
+	err = cmd.Execute()
+	assert.EqualError(t, err,
+		"error loading signing key from PKCS#11 token: error loading PKCS#11 module /nonexistent/module.so")
+}
@@ -0,0 +1,18 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !cgo
+
+package cmd
+
+import (
+	"errors"
+
+	cose "github.com/veraison/go-cose"
+)
+
+// newPKCS11Signer is not available without cgo, which is needed to load the
+// PKCS#11 module
+func newPKCS11Signer(uri string) (cose.Signer, func(), error) {
+	return nil, nil, errors.New("PKCS#11 support requires cocli to be built with cgo enabled")
+}
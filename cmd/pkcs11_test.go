@@ -0,0 +1,74 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parsePKCS11URI_ok(t *testing.T) {
+	u, err := parsePKCS11URI(
+		"pkcs11:token=My%20Token;object=corim-signer;id=%01%02;slot-id=3;type=private" +
+			"?pin-value=1234&module-path=/usr/lib/softhsm/libsofthsm2.so",
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "My Token", u.Token)
+	assert.Equal(t, "corim-signer", u.Object)
+	assert.Equal(t, []byte{0x01, 0x02}, u.ID)
+	require.NotNil(t, u.SlotID)
+	assert.Equal(t, uint(3), *u.SlotID)
+	assert.Equal(t, "1234", u.PIN)
+	assert.Equal(t, "/usr/lib/softhsm/libsofthsm2.so", u.ModulePath)
+}
+
+func Test_parsePKCS11URI_bad(t *testing.T) {
+	tvs := []struct {
+		uri      string
+		expected string
+	}{
+		{"token=foo", `missing "pkcs11:" scheme`},
+		{"pkcs11:token=foo", "at least one of object or id must be specified"},
+		{"pkcs11:object", `malformed path attribute "object"`},
+		{"pkcs11:object=foo;slot-id=x", `malformed slot-id: strconv.ParseUint: parsing "x": invalid syntax`},
+		{"pkcs11:object=foo;type=public", `unsupported object type "public", expecting "private"`},
+	}
+
+	for _, tv := range tvs {
+		t.Run(tv.uri, func(t *testing.T) {
+			_, err := parsePKCS11URI(tv.uri)
+			assert.EqualError(t, err, tv.expected)
+		})
+	}
+}
+
+func Test_resolvePKCS11URI_from_config(t *testing.T) {
+	viper.Set("pkcs11_module", "/path/to/module.so")
+	viper.Set("pkcs11_pin", "5678")
+	defer func() {
+		viper.Set("pkcs11_module", "")
+		viper.Set("pkcs11_pin", "")
+	}()
+
+	u, err := resolvePKCS11URI("pkcs11:object=corim-signer")
+	require.NoError(t, err)
+	assert.Equal(t, "/path/to/module.so", u.ModulePath)
+	assert.Equal(t, "5678", u.PIN)
+
+	// URI attributes take precedence over the configuration
+	u, err = resolvePKCS11URI("pkcs11:object=corim-signer?pin-value=1234&module-path=/other.so")
+	require.NoError(t, err)
+	assert.Equal(t, "/other.so", u.ModulePath)
+	assert.Equal(t, "1234", u.PIN)
+}
+
+func Test_resolvePKCS11URI_no_module(t *testing.T) {
+	_, err := resolvePKCS11URI("pkcs11:object=corim-signer")
+	assert.EqualError(t, err,
+		"no PKCS#11 module supplied: set module-path in the URI or pkcs11_module in the configuration")
+}
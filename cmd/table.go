@@ -0,0 +1,494 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/corim/corim"
+	"github.com/veraison/corim/cots"
+	"github.com/veraison/swid"
+)
+
+// table is the compact, column-aligned view of an item used by the display
+// commands.  A row may span several lines, e.g., a value triple has a line per
+// measurement: only the first line of a row carries the values it shares.
+type table struct {
+	header []string
+	lines  [][]string
+}
+
+// add appends a line, in which missing values are shown as "-"
+func (o *table) add(cells ...string) {
+	line := make([]string, len(o.header))
+
+	for i := range line {
+		line[i] = "-"
+		if i < len(cells) && cells[i] != "" {
+			line[i] = cells[i]
+		}
+	}
+
+	o.lines = append(o.lines, line)
+}
+
+// cont appends a continuation line of the current row, in which only the
+// cells from the supplied column onwards are set
+func (o *table) cont(from int, cells ...string) {
+	line := make([]string, len(o.header))
+
+	for i, c := range cells {
+		if from+i < len(line) {
+			line[from+i] = c
+		}
+	}
+
+	o.lines = append(o.lines, line)
+}
+
+func (o table) String() string {
+	var b strings.Builder
+
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, strings.Join(o.header, "\t"))
+	for _, line := range o.lines {
+		fmt.Fprintln(w, strings.Join(line, "\t"))
+	}
+
+	w.Flush()
+
+	// tabwriter pads the cells of the last column when they are empty
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// toTable returns the table view of the supplied CoMID, CoSWID, CoTS, CoRIM or
+// CoRIM Meta
+func toTable(v any) (*table, error) {
+	switch t := v.(type) {
+	case *comid.Comid:
+		return comidTable(t), nil
+	case *swid.SoftwareIdentity:
+		return coswidTable(t), nil
+	case *cots.ConciseTaStore:
+		return cotsTable(t), nil
+	case *corim.UnsignedCorim:
+		return corimTable(t), nil
+	case *corim.Meta:
+		return metaTable(t), nil
+	default:
+		return nil, fmt.Errorf("no table view for %T", v)
+	}
+}
+
+// comidTable has a row per triple, with a line per measurement and digest of
+// value triples, and per verification key of key triples
+func comidTable(c *comid.Comid) *table {
+	t := &table{header: []string{"TRIPLE", "CLASS-ID", "VENDOR", "MODEL", "INSTANCE", "KEY", "DIGESTS", "SVN"}}
+
+	valueTriples := []*comid.ValueTriples{c.Triples.ReferenceValues, c.Triples.EndorsedValues}
+	for i, vts := range valueTriples {
+		if vts == nil {
+			continue
+		}
+
+		for _, vt := range vts.Values {
+			row := append([]string{diffValueTriples[i]}, environmentCells(vt.Environment)...)
+
+			if len(vt.Measurements.Values) == 0 {
+				t.add(row...)
+				continue
+			}
+
+			for j, m := range vt.Measurements.Values {
+				digests := digestCells(m.Val.Digests)
+
+				if j == 0 {
+					t.add(append(row, mkeyCell(m.Key), digests[0], svnCell(m.Val.SVN))...)
+				} else {
+					t.cont(5, mkeyCell(m.Key), orNone(digests[0]), orNone(svnCell(m.Val.SVN)))
+				}
+
+				for _, d := range digests[1:] {
+					t.cont(6, d)
+				}
+			}
+		}
+	}
+
+	keyTriples := []*comid.KeyTriples{c.Triples.DevIdentityKeys, c.Triples.AttestVerifKeys}
+	for i, kts := range keyTriples {
+		if kts == nil {
+			continue
+		}
+
+		for _, kt := range *kts {
+			row := append([]string{diffKeyTriples[i]}, environmentCells(kt.Environment)...)
+
+			if len(kt.VerifKeys) == 0 {
+				t.add(row...)
+				continue
+			}
+
+			for j, k := range kt.VerifKeys {
+				if j == 0 {
+					t.add(append(row, cryptoKeyCell(k))...)
+				} else {
+					t.cont(5, cryptoKeyCell(k))
+				}
+			}
+		}
+	}
+
+	return t
+}
+
+// environmentCells returns the class id, vendor, model and instance (or group)
+// of the environment
+func environmentCells(e comid.Environment) []string {
+	cells := make([]string, 4)
+
+	if c := e.Class; c != nil {
+		if c.ClassID != nil {
+			cells[0] = c.ClassID.String()
+		}
+		if c.Vendor != nil {
+			cells[1] = *c.Vendor
+		}
+		if c.Model != nil {
+			cells[2] = *c.Model
+		}
+	}
+
+	switch {
+	case e.Instance != nil:
+		cells[3] = e.Instance.String()
+	case e.Group != nil:
+		cells[3] = "group " + e.Group.String()
+	}
+
+	return cells
+}
+
+func mkeyCell(k *comid.Mkey) string {
+	if k == nil || k.Value == nil {
+		return ""
+	}
+
+	// the signer-id is left to the other formats, as it would make the column
+	// too wide
+	if id, err := k.GetPSARefValID(); err == nil {
+		var parts []string
+		if id.Label != nil {
+			parts = append(parts, "label="+*id.Label)
+		}
+		if id.Version != nil {
+			parts = append(parts, "version="+*id.Version)
+		}
+		return strings.Join(parts, " ")
+	}
+
+	return k.Value.String()
+}
+
+// digestCells returns a line per digest, or a single empty line if there is
+// none
+func digestCells(d *comid.Digests) []string {
+	if d == nil || len(*d) == 0 {
+		return []string{""}
+	}
+
+	cells := make([]string, 0, len(*d))
+	for _, h := range *d {
+		cells = append(cells, h.AlgIDToString()+":"+hex.EncodeToString(h.HashValue))
+	}
+
+	return cells
+}
+
+func svnCell(s *comid.SVN) string {
+	if s == nil || s.Value == nil {
+		return ""
+	}
+
+	if s.Value.Type() == comid.MinValueType {
+		return ">=" + s.Value.String()
+	}
+
+	return s.Value.String()
+}
+
+func cryptoKeyCell(k *comid.CryptoKey) string {
+	if k == nil || k.Value == nil {
+		return ""
+	}
+
+	if pub, err := k.PublicKey(); err == nil {
+		return k.Type() + " (" + publicKeyDescription(pub) + ")"
+	}
+
+	return k.Type()
+}
+
+// orNone returns "-" for an empty cell in a continuation line, which would
+// otherwise read as a value shared with the previous line
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// coswidTable has a single row, with a line per entity
+func coswidTable(s *swid.SoftwareIdentity) *table {
+	t := &table{header: []string{"TAG-ID", "TAG-VERSION", "SOFTWARE-NAME", "SOFTWARE-VERSION", "ENTITIES"}}
+
+	entities := make([]string, 0, len(s.Entities))
+	for _, e := range s.Entities {
+		entities = append(entities, fmt.Sprintf("%s (%s)", e.EntityName, e.Roles.String()))
+	}
+	if len(entities) == 0 {
+		entities = append(entities, "")
+	}
+
+	t.add(s.TagID.String(), fmt.Sprint(s.TagVersion), s.SoftwareName, s.SoftwareVersion, entities[0])
+	for _, e := range entities[1:] {
+		t.cont(4, e)
+	}
+
+	return t
+}
+
+// cotsTable has a row per trust anchor, followed by a row per CA certificate
+func cotsTable(ts *cots.ConciseTaStore) *table {
+	t := &table{header: []string{"FORMAT", "SUBJECT", "KEY", "NOT-AFTER"}}
+
+	if ts.Keys == nil {
+		return t
+	}
+
+	formats := map[cots.TaFormat]string{
+		cots.TaFormatCertificate:          "cert",
+		cots.TaFormatTrustAnchorInfo:      "ta",
+		cots.TaFormatSubjectPublicKeyInfo: "spki",
+	}
+
+	for _, ta := range ts.Keys.Tas {
+		format, ok := formats[ta.Format]
+		if !ok {
+			format = fmt.Sprintf("unknown (%d)", ta.Format)
+		}
+
+		t.add(append([]string{format}, trustAnchorCells(ta)...)...)
+	}
+
+	for _, ca := range ts.Keys.Cas {
+		t.add(append([]string{"ca"}, certificateCells(ca)...)...)
+	}
+
+	return t
+}
+
+// trustAnchorCells returns the subject, public key and expiry of the trust
+// anchor, as far as they are known
+func trustAnchorCells(ta cots.TrustAnchor) []string {
+	switch ta.Format {
+	case cots.TaFormatCertificate:
+		return certificateCells(ta.Data)
+	case cots.TaFormatTrustAnchorInfo:
+		return trustAnchorInfoCells(ta.Data)
+	case cots.TaFormatSubjectPublicKeyInfo:
+		pub, err := x509.ParsePKIXPublicKey(ta.Data)
+		if err != nil {
+			return []string{"invalid: " + err.Error()}
+		}
+		return []string{"", publicKeyDescription(pub)}
+	default:
+		return nil
+	}
+}
+
+func certificateCells(der []byte) []string {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return []string{"invalid: " + err.Error()}
+	}
+
+	return []string{
+		cert.Subject.String(),
+		publicKeyDescription(cert.PublicKey),
+		cert.NotAfter.UTC().Format(time.RFC3339),
+	}
+}
+
+// trustAnchorInfo is the part of the TrustAnchorInfo structure (RFC 5914) that
+// is shown in the table view
+type trustAnchorInfo struct {
+	Version  int `asn1:"optional,default:1"`
+	PubKey   asn1.RawValue
+	KeyID    []byte
+	TaTitle  string           `asn1:"optional,utf8"`
+	CertPath certPathControls `asn1:"optional"`
+}
+
+type certPathControls struct {
+	TaName      asn1.RawValue
+	Certificate asn1.RawValue `asn1:"optional,tag:0"`
+}
+
+// trustAnchorInfoCells decodes a TrustAnchorChoice (RFC 5914), which is
+// expected to be either a certificate or a TrustAnchorInfo.  The subject is
+// the name of the trust anchor, or its title, and the expiry is that of the
+// certificate, if any, included in the TrustAnchorInfo.
+func trustAnchorInfoCells(data []byte) []string {
+	var choice asn1.RawValue
+	if _, err := asn1.Unmarshal(data, &choice); err != nil {
+		return []string{"invalid: " + err.Error()}
+	}
+
+	switch {
+	case choice.Class == asn1.ClassUniversal && choice.Tag == asn1.TagSequence:
+		return certificateCells(data)
+	case choice.Class == asn1.ClassContextSpecific && choice.Tag == 2:
+		// [2] EXPLICIT TrustAnchorInfo
+	default:
+		return []string{fmt.Sprintf("unsupported trust anchor choice [%d]", choice.Tag)}
+	}
+
+	var tai trustAnchorInfo
+	if _, err := asn1.Unmarshal(choice.Bytes, &tai); err != nil {
+		return []string{"invalid: " + err.Error()}
+	}
+
+	cells := []string{tai.TaTitle, "", ""}
+
+	if pub, err := x509.ParsePKIXPublicKey(tai.PubKey.FullBytes); err == nil {
+		cells[1] = publicKeyDescription(pub)
+	}
+
+	if len(tai.CertPath.TaName.FullBytes) != 0 {
+		var rdn pkix.RDNSequence
+		if _, err := asn1.Unmarshal(tai.CertPath.TaName.FullBytes, &rdn); err == nil {
+			var name pkix.Name
+			name.FillFromRDNSequence(&rdn)
+			cells[0] = name.String()
+		}
+	}
+
+	if len(tai.CertPath.Certificate.Bytes) != 0 {
+		// the certificate is IMPLICIT tagged: restore its SEQUENCE header
+		der, err := asn1.Marshal(asn1.RawValue{
+			Tag: asn1.TagSequence, IsCompound: true, Bytes: tai.CertPath.Certificate.Bytes,
+		})
+		if err == nil {
+			if cert, err := x509.ParseCertificate(der); err == nil {
+				cells[2] = cert.NotAfter.UTC().Format(time.RFC3339)
+			}
+		}
+	}
+
+	return cells
+}
+
+// publicKeyDescription returns the algorithm and size, or curve, of the key
+func publicKeyDescription(pub crypto.PublicKey) string {
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		return "ECDSA " + k.Curve.Params().Name
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA %d", k.N.BitLen())
+	case ed25519.PublicKey:
+		return "Ed25519"
+	default:
+		return fmt.Sprintf("%T", pub)
+	}
+}
+
+// corimTable has a single row, with a line per tag
+func corimTable(u *corim.UnsignedCorim) *table {
+	t := &table{header: []string{"CORIM-ID", "PROFILE", "TAG", "TYPE", "TAG-ID", "VERSION"}}
+
+	var profile string
+	if u.Profile != nil {
+		profile, _ = u.Profile.Get()
+	}
+
+	row := []string{u.ID.String(), profile}
+
+	if len(u.Tags) == 0 {
+		t.add(row...)
+		return t
+	}
+
+	for i, tag := range u.Tags {
+		cells := []string{fmt.Sprint(i)}
+
+		if ct, err := decodeCorimTag(tag); err != nil {
+			cells = append(cells, "", "invalid: "+err.Error(), "")
+		} else {
+			cells = append(cells, ct.typ, ct.tagID, tagVersionCell(ct))
+		}
+
+		if i == 0 {
+			t.add(append(row, cells...)...)
+			continue
+		}
+
+		// cont leaves empty cells blank, which would read as values shared
+		// with the line above
+		for j := range cells {
+			cells[j] = orNone(cells[j])
+		}
+		t.cont(2, cells...)
+	}
+
+	return t
+}
+
+// tagVersionCell returns the version of the tag, which CoTSs without a
+// tag-identity do not have
+func tagVersionCell(ct corimTag) string {
+	if c, ok := ct.value.(*cots.ConciseTaStore); ok && c.TagIdentity == nil {
+		return ""
+	}
+	return fmt.Sprint(ct.version)
+}
+
+func metaTable(m *corim.Meta) *table {
+	t := &table{header: []string{"SIGNER", "URI", "NOT-BEFORE", "NOT-AFTER"}}
+
+	var uri, notBefore, notAfter string
+
+	if m.Signer.URI != nil {
+		uri = string(*m.Signer.URI)
+	}
+
+	if v := m.Validity; v != nil {
+		if v.NotBefore != nil {
+			notBefore = v.NotBefore.UTC().Format(time.RFC3339)
+		}
+		notAfter = v.NotAfter.UTC().Format(time.RFC3339)
+	}
+
+	t.add(m.Signer.Name, uri, notBefore, notAfter)
+
+	return t
+}
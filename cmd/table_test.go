@@ -0,0 +1,177 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/corim/corim"
+	"github.com/veraison/corim/cots"
+	"github.com/veraison/swid"
+)
+
+var testComidTable = []byte(`{
+	"tag-identity": {
+		"id": "43BBE37F-2E61-4B33-AED3-53CFF1428B16"
+	},
+	"triples": {
+		"reference-values": [
+			{
+				"environment": {
+					"class": {
+						"id": {
+							"type": "uuid",
+							"value": "31fb5abf-023e-4992-aa4e-95f9c1503bfa"
+						},
+						"vendor": "ACME",
+						"model": "RoadRunner"
+					}
+				},
+				"measurements": [
+					{
+						"key": {
+							"type": "uint",
+							"value": 1
+						},
+						"value": {
+							"svn": {
+								"type": "exact-value",
+								"value": 2
+							},
+							"digests": [
+								"sha-256;5Fty9cDAtXLbTY06t+l/No/3TmI0eoJN7LZ6hOUiTXU=",
+								"sha-384;S1bPoH+usqtX3pIeSpfWVRRLVGRw66qrb3HA21GN31tKX7KPsq0bSTQmRCTrHlqG"
+							]
+						}
+					},
+					{
+						"value": {
+							"svn": {
+								"type": "min-value",
+								"value": 3
+							}
+						}
+					}
+				]
+			}
+		],
+		"attester-verification-keys": [
+			{
+				"environment": {
+					"instance": {
+						"type": "ueid",
+						"value": "Ac7rrnuJJ6MiflMDz14PH3s0u1Qq1yUKwD+83jbsLxUI"
+					}
+				},
+				"verification-keys": [
+					{
+						"type": "pkix-base64-key",
+						"value": "-----BEGIN PUBLIC KEY-----\nMFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEFn0taoAwR3PmrKkYLtAsD9o05KSM6mbgfNCgpuL0g6VpTHkZl73wk5BDxoV7n+Oeee0iIqkW3HMZT3ETiniJdg==\n-----END PUBLIC KEY-----"
+					}
+				]
+			}
+		]
+	}
+}`)
+
+func Test_table_String(t *testing.T) {
+	tbl := table{header: []string{"A", "LONGER", "C"}}
+	tbl.add("1", "", "3")
+	tbl.cont(1, "x")
+	tbl.add("a-long-value")
+
+	assert.Equal(t, `A             LONGER  C
+1             -       3
+              x
+a-long-value  -       -`, tbl.String())
+}
+
+func Test_comidTable(t *testing.T) {
+	var c comid.Comid
+	require.NoError(t, c.FromJSON(testComidTable))
+
+	assert.Equal(t, `TRIPLE                      CLASS-ID                              VENDOR  MODEL       INSTANCE                                      KEY                            DIGESTS                                                                                                   SVN
+reference-values            31fb5abf-023e-4992-aa4e-95f9c1503bfa  ACME    RoadRunner  -                                             1                              sha-256:e45b72f5c0c0b572db4d8d3ab7e97f368ff74e62347a824decb67a84e5224d75                                  2
+                                                                                                                                                                   sha-384:4b56cfa07faeb2ab57de921e4a97d655144b546470ebaaab6f71c0db518ddf5b4a5fb28fb2ad1b4934264424eb1e5a86
+                                                                                                                                                                   -                                                                                                         >=3
+attester-verification-keys  -                                     -       -           Ac7rrnuJJ6MiflMDz14PH3s0u1Qq1yUKwD+83jbsLxUI  pkix-base64-key (ECDSA P-256)  -                                                                                                         -`, comidTable(&c).String())
+}
+
+func Test_coswidTable(t *testing.T) {
+	var s swid.SoftwareIdentity
+	require.NoError(t, s.FromJSON(testCoswidTemplate))
+
+	assert.Equal(t, `TAG-ID                            TAG-VERSION  SOFTWARE-NAME                                     SOFTWARE-VERSION  ENTITIES
+com.acme.rrd2013-ce-sp1-v4-1-5-0  0            ACME Roadrunner Detector 2013 Coyote Edition SP1  4.1.5             The ACME Corporation (tagCreator softwareCreator)`, coswidTable(&s).String())
+}
+
+func Test_cotsTable(t *testing.T) {
+	var s corim.SignedCorim
+	require.NoError(t, s.FromCOSE(testSignedCorimValidWithCots))
+
+	var ts cots.ConciseTaStore
+	require.NoError(t, ts.FromCBOR(s.UnsignedCorim.Tags[0][3:]))
+
+	assert.Equal(t, `FORMAT  SUBJECT                                                                 KEY          NOT-AFTER
+ta      CN=Snobbish Apparel\, Inc. Trust Anchor,O=Snobbish Apparel\, Inc.,C=US  ECDSA P-256  2032-05-16T15:13:08Z
+ta      CN=Zesty Hands\, Inc. Trust Anchor,O=Zesty Hands\, Inc.,C=US            ECDSA P-256  2032-05-16T15:13:07Z
+ta      CN=Example Trust Anchor,O=Example,C=US                                  ECDSA P-256  2032-05-16T15:13:07Z
+spki    -                                                                       ECDSA P-256  -`, cotsTable(&ts).String())
+}
+
+func Test_corimTable(t *testing.T) {
+	var s corim.SignedCorim
+	require.NoError(t, s.FromCOSE(testSignedCorimValidWithCots))
+
+	assert.Equal(t, `CORIM-ID                              PROFILE  TAG  TYPE  TAG-ID  VERSION
+5c57e8f4-46cd-421b-91c9-08cf93e13cfc  -        0    cots  -       -`, corimTable(&s.UnsignedCorim).String())
+	assert.Equal(t, `SIGNER                URI  NOT-BEFORE  NOT-AFTER
+ACME Ltd signing key  -    -           -`, metaTable(&s.Meta).String())
+}
+
+func Test_corimTable_missing_values_after_first_tag(t *testing.T) {
+	var s corim.SignedCorim
+	require.NoError(t, s.FromCOSE(testSignedCorimValidWithCots))
+
+	var c comid.Comid
+	require.NoError(t, c.FromJSON(testComidACMEWileE))
+
+	u := corim.NewUnsignedCorim().SetID("5c57e8f4-46cd-421b-91c9-08cf93e13cfc")
+	require.NotNil(t, u.AddComid(&c))
+
+	// a CoTS without tag-identity, then a malformed tag
+	u.Tags = append(u.Tags, s.UnsignedCorim.Tags[0], corim.Tag{0xd9})
+
+	assert.Equal(t, `CORIM-ID                              PROFILE  TAG  TYPE   TAG-ID                   VERSION
+5c57e8f4-46cd-421b-91c9-08cf93e13cfc  -        0    comid  urn:example:acme:wile-e  0
+                                               1    cots   -                        -
+                                               2    -      invalid: malformed tag   -`, corimTable(u).String())
+}
+
+func Test_toYAML(t *testing.T) {
+	v := map[string]any{
+		"version": "1.0",
+		"count":   2,
+		"pem":     "-----BEGIN PUBLIC KEY-----\nMFkw\n-----END PUBLIC KEY-----",
+		"list":    []any{true, "yes", nil},
+		"empty":   map[string]any{},
+	}
+
+	y, err := toYAML(v)
+	require.NoError(t, err)
+	assert.Equal(t, `count: 2
+empty: {}
+list:
+  - true
+  - "yes"
+  - null
+pem: |-
+  -----BEGIN PUBLIC KEY-----
+  MFkw
+  -----END PUBLIC KEY-----
+version: "1.0"`, y)
+}
@@ -0,0 +1,288 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// extensions of the template files, which are in JSON or YAML format
+var templateExts = []string{".json", ".yaml", ".yml"}
+
+type FromJSONLoader interface {
+	FromJSON([]byte) error
+}
+
+// templateFilesList returns the JSON and YAML templates found in the supplied
+// files and directories
+func templateFilesList(files, dirs []string) []string {
+	return filesList(files, dirs, templateExts...)
+}
+
+func isYAMLTemplate(file string) bool {
+	ext := filepath.Ext(file)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+func isTemplate(file string) bool {
+	return filepath.Ext(file) == ".json" || isYAMLTemplate(file)
+}
+
+// decodeTemplate decodes the template in tmplData into t.  YAML templates
+// (.yaml or .yml) are converted to JSON first, and decoding errors are
+// reported with the line of the YAML template they refer to, when it can be
+// found.
+func decodeTemplate(t FromJSONLoader, tmplData []byte, tmplFile string) error {
+	if !isYAMLTemplate(tmplFile) {
+		return t.FromJSON(tmplData)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(tmplData, &doc); err != nil {
+		return err
+	}
+
+	if len(doc.Content) == 0 {
+		return errors.New("empty YAML template")
+	}
+
+	root := doc.Content[0]
+
+	var b bytes.Buffer
+	if err := yamlToJSON(root, &b); err != nil {
+		return err
+	}
+
+	if err := t.FromJSON(b.Bytes()); err != nil {
+		if n := locateYAMLError(root, err); n != nil {
+			return fmt.Errorf("line %d: %w", n.Line, err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// yamlToJSON writes the JSON encoding of the YAML node.  Strings, timestamps
+// and binary values are copied as they are, so that they are interpreted by
+// the JSON decoder of the target type, as in JSON templates.
+func yamlToJSON(n *yaml.Node, b *bytes.Buffer) error {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return yamlToJSON(n.Alias, b)
+	case yaml.MappingNode:
+		pairs, err := yamlMappingPairs(n)
+		if err != nil {
+			return err
+		}
+
+		b.WriteByte('{')
+		for i, p := range pairs {
+			k, v := p[0], p[1]
+			if k.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: unsupported non-scalar mapping key", k.Line)
+			}
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			key, _ := json.Marshal(k.Value)
+			b.Write(key)
+			b.WriteByte(':')
+			if err := yamlToJSON(v, b); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	case yaml.SequenceNode:
+		b.WriteByte('[')
+		for i, c := range n.Content {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := yamlToJSON(c, b); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	case yaml.ScalarNode:
+		var v any = n.Value
+
+		switch n.ShortTag() {
+		case "!!str", "!!timestamp", "!!binary":
+		default:
+			if err := n.Decode(&v); err != nil {
+				return fmt.Errorf("line %d: %w", n.Line, err)
+			}
+		}
+
+		j, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n.Line, err)
+		}
+		b.Write(j)
+	default:
+		return fmt.Errorf("line %d: unsupported YAML node", n.Line)
+	}
+
+	return nil
+}
+
+// yamlMappingPairs returns the key-value pairs of the mapping, with those
+// brought in by merge keys (<<) first, so that they are overridden by the keys
+// of the mapping when the JSON encoding is decoded
+func yamlMappingPairs(n *yaml.Node) ([][2]*yaml.Node, error) {
+	var merged, own [][2]*yaml.Node
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+
+		if k.ShortTag() != "!!merge" {
+			own = append(own, [2]*yaml.Node{k, v})
+			continue
+		}
+
+		sources := []*yaml.Node{v}
+		if v.Kind == yaml.SequenceNode {
+			sources = v.Content
+		}
+
+		for _, src := range sources {
+			for src.Kind == yaml.AliasNode {
+				src = src.Alias
+			}
+
+			if src.Kind != yaml.MappingNode {
+				return nil, fmt.Errorf("line %d: merge key value is not a mapping", src.Line)
+			}
+
+			pairs, err := yamlMappingPairs(src)
+			if err != nil {
+				return nil, err
+			}
+
+			merged = append(merged, pairs...)
+		}
+	}
+
+	return append(merged, own...), nil
+}
+
+// errStepRe matches the field names and array indices in the errors returned
+// by the JSON decoders of the CoRIM types
+var errStepRe = regexp.MustCompile(`error unmarshalling field "([^"]+)"|error at index (\d+)`)
+
+// locateYAMLError returns the node of the YAML template that a JSON decoding
+// error refers to, or nil if it cannot be found.  The decoders of the CoRIM
+// types report the (Go) name of the field and the index of the array item that
+// failed to decode, from the outermost to the innermost, and the standard
+// library JSON decoder the dot-separated path to the offending value.  These are followed
+// for as long as they match the YAML document.
+func locateYAMLError(root *yaml.Node, err error) *yaml.Node {
+	type step struct {
+		field string
+		index int
+	}
+
+	var steps []step
+
+	for _, m := range errStepRe.FindAllStringSubmatch(err.Error(), -1) {
+		if m[1] != "" {
+			steps = append(steps, step{field: m[1]})
+		} else {
+			i, _ := strconv.Atoi(m[2])
+			steps = append(steps, step{index: i})
+		}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		for _, f := range strings.Split(typeErr.Field, ".") {
+			// array items are identified by their index
+			if i, err := strconv.Atoi(f); err == nil {
+				steps = append(steps, step{index: i})
+			} else {
+				steps = append(steps, step{field: f})
+			}
+		}
+	}
+
+	follow := func(n *yaml.Node, s step) *yaml.Node {
+		for n.Kind == yaml.AliasNode {
+			n = n.Alias
+		}
+
+		switch {
+		case s.field != "" && n.Kind == yaml.MappingNode:
+			return yamlMappingValue(n, s.field)
+		case s.field == "" && n.Kind == yaml.SequenceNode && s.index < len(n.Content):
+			return n.Content[s.index]
+		}
+
+		return nil
+	}
+
+	var found *yaml.Node
+
+	n := root
+	for _, s := range steps {
+		next := follow(n, s)
+
+		// some decoders do not report the field that wraps the one that
+		// failed (e.g., the measurements of a triple), so look one level down
+		if next == nil && n.Kind == yaml.MappingNode {
+			pairs, _ := yamlMappingPairs(n)
+			for _, p := range pairs {
+				if next = follow(p[1], s); next != nil {
+					break
+				}
+			}
+		}
+
+		if next == nil {
+			break
+		}
+
+		n, found = next, next
+	}
+
+	return found
+}
+
+// yamlMappingValue returns the value of the key that matches the supplied
+// field name, which is either the JSON name or the Go name of a field, e.g.,
+// "reference-values" or "ReferenceValues"
+func yamlMappingValue(n *yaml.Node, field string) *yaml.Node {
+	pairs, err := yamlMappingPairs(n)
+	if err != nil {
+		return nil
+	}
+
+	normalize := func(s string) string {
+		return strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(s))
+	}
+
+	// the last occurrence of a key is the one that is decoded
+	for i := len(pairs) - 1; i >= 0; i-- {
+		if pairs[i][0].Value == field {
+			return pairs[i][1]
+		}
+	}
+
+	for i := len(pairs) - 1; i >= 0; i-- {
+		if normalize(pairs[i][0].Value) == normalize(field) {
+			return pairs[i][1]
+		}
+	}
+
+	return nil
+}
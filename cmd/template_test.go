@@ -0,0 +1,173 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/corim/corim"
+)
+
+// YAML version of testComidACMERoadRunner
+var testComidACMERoadRunnerYAML = []byte(`# ACME RoadRunner reference values
+tag-identity:
+  id: 43BBE37F-2E61-4B33-AED3-53CFF1428B16
+triples:
+  reference-values:
+    - environment:
+        class:
+          id:
+            type: uuid
+            value: 31fb5abf-023e-4992-aa4e-95f9c1503bfa
+          vendor: ACME
+          model: RoadRunner
+      measurements:
+        # the raw value of the boot loader
+        - value:
+            raw-value:
+              type: bytes
+              value: 3q2+7w==
+`)
+
+func Test_decodeTemplate_yaml(t *testing.T) {
+	var fromJSON, fromYAML comid.Comid
+
+	require.NoError(t, decodeTemplate(&fromJSON, testComidACMERoadRunner, "t.json"))
+	require.NoError(t, decodeTemplate(&fromYAML, testComidACMERoadRunnerYAML, "t.yaml"))
+
+	expected, err := fromJSON.ToCBOR()
+	require.NoError(t, err)
+
+	actual, err := fromYAML.ToCBOR()
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, actual)
+}
+
+func Test_decodeTemplate_yaml_merge_keys(t *testing.T) {
+	tmpl := []byte(`corim-id: 5c57e8f4-46cd-421b-91c9-08cf93e13cfc
+dependent-rims:
+  - &rim
+    href: https://example.com/a.cbor
+    thumbprint: sha-256;5Fty9cDAtXLbTY06t+l/No/3TmI0eoJN7LZ6hOUiTXU=
+  - <<: *rim
+    href: https://example.com/b.cbor
+`)
+
+	var c corim.UnsignedCorim
+	require.NoError(t, decodeTemplate(&c, tmpl, "corim.yml"))
+	require.NotNil(t, c.DependentRims)
+	require.Len(t, *c.DependentRims, 2)
+
+	a, b := (*c.DependentRims)[0], (*c.DependentRims)[1]
+	assert.Equal(t, "https://example.com/a.cbor", string(a.Href))
+	assert.Equal(t, "https://example.com/b.cbor", string(b.Href))
+	assert.Equal(t, a.Thumbprint, b.Thumbprint)
+}
+
+func Test_decodeTemplate_yaml_errors(t *testing.T) {
+	tvs := []struct {
+		desc string
+		tmpl string
+		err  string
+	}{
+		{
+			desc: "syntax error",
+			tmpl: "tag-identity:\n  id: x\n bad",
+			err:  "yaml: line 2: did not find expected key",
+		},
+		{
+			desc: "empty",
+			tmpl: "# nothing here\n",
+			err:  "empty YAML template",
+		},
+		{
+			desc: "not a JSON value",
+			tmpl: "tag-identity:\n  id: x\nlang: .nan\n",
+			err:  "line 3: json: unsupported value: NaN",
+		},
+		{
+			desc: "wrong type",
+			tmpl: `tag-identity:
+  id: 43BBE37F-2E61-4B33-AED3-53CFF1428B16
+triples:
+  reference-values:
+    - environment:
+        class:
+          vendor: ACME
+      measurements: []
+    - environment:
+        class:
+          vendor: 5
+      measurements: []
+`,
+			err: `line 11: error unmarshalling field "Triples": error unmarshalling field "ReferenceValues": ` +
+				`error at index 1: json: cannot unmarshal number into Go struct field ` +
+				`ValueTriple.environment.class.vendor of type string`,
+		},
+		{
+			desc: "bad value",
+			tmpl: `tag-identity:
+  id: 43BBE37F-2E61-4B33-AED3-53CFF1428B16
+triples:
+  reference-values:
+    - environment:
+        class:
+          id:
+            type: foo
+            value: 1
+      measurements: []
+`,
+			err: `line 5: error unmarshalling field "Triples": error unmarshalling field "ReferenceValues": ` +
+				`error at index 0: unknown class id type: foo`,
+		},
+		{
+			desc: "field not in the error path",
+			tmpl: `tag-identity:
+  id: 43BBE37F-2E61-4B33-AED3-53CFF1428B16
+triples:
+  endorsed-values:
+    - environment:
+        class:
+          vendor: ACME
+      measurements:
+        - value:
+            svn:
+              type: exact-value
+              value: two
+`,
+			err: `line 11: error unmarshalling field "Triples": error unmarshalling field "EndorsedValues": ` +
+				`error at index 0: error at index 0: error unmarshalling field "SVN": invalid SVN exact-value: ` +
+				`json: cannot unmarshal string into Go value of type comid.TaggedSVN`,
+		},
+		{
+			desc: "missing field",
+			tmpl: "tag-identity:\n  id: 43BBE37F-2E61-4B33-AED3-53CFF1428B16\n",
+			err:  `missing mandatory field "Triples" ("triples")`,
+		},
+	}
+
+	for _, tv := range tvs {
+		var c comid.Comid
+		err := decodeTemplate(&c, []byte(tv.tmpl), "t.yaml")
+		assert.EqualError(t, err, tv.err, tv.desc)
+	}
+}
+
+func Test_templateFilesList(t *testing.T) {
+	fs = afero.NewMemMapFs()
+
+	for _, f := range []string{"d/a.json", "d/b.yaml", "d/c.yml", "d/d.cbor", "e.yaml"} {
+		require.NoError(t, afero.WriteFile(fs, f, []byte{}, 0644))
+	}
+
+	assert.Equal(t,
+		[]string{"e.yaml", "d/a.json", "d/b.yaml", "d/c.yml"},
+		templateFilesList([]string{"e.yaml", "d/d.cbor"}, []string{"d"}),
+	)
+}
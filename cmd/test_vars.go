@@ -28,6 +28,52 @@ var (
 	testCorimInvalid = comid.MustHexDecode(nil,
 		"a100505c57e8f446cd421b91c908cf93e13cfc",
 	)
+	testCoswidTemplate = []byte(`{
+		"tag-id": "com.acme.rrd2013-ce-sp1-v4-1-5-0",
+		"tag-version": 0,
+		"software-name": "ACME Roadrunner Detector 2013 Coyote Edition SP1",
+		"software-version": "4.1.5",
+		"entity": [
+			{
+				"entity-name": "The ACME Corporation",
+				"reg-id": "acme.com",
+				"role": [ "tagCreator", "softwareCreator" ]
+			}
+		]
+	}`)
+	// a CoSWID with no entities {0: "tag", 12: 0, 1: "name"}
+	invalidCoswid           = comid.MustHexDecode(nil, "a3006374616701646e616d650c00")
+	testComidACMERoadRunner = []byte(`{
+	"tag-identity": {
+		"id": "43BBE37F-2E61-4B33-AED3-53CFF1428B16"
+	},
+	"triples": {
+		"reference-values": [
+			{
+				"environment": {
+					"class": {
+						"id": {
+							"type": "uuid",
+							"value": "31fb5abf-023e-4992-aa4e-95f9c1503bfa"
+						},
+						"vendor": "ACME",
+						"model": "RoadRunner"
+					}
+				},
+				"measurements": [
+					{
+						"value": {
+							"raw-value": {
+								"type": "bytes",
+								"value": "3q2+7w=="
+							}
+						}
+					}
+				]
+			}
+		]
+	}
+}`)
 	testMetaInvalid = []byte("{}")
 	testMetaValid   = []byte(`{
 		"signer": {
@@ -0,0 +1,524 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+
+	cose "github.com/veraison/go-cose"
+)
+
+// headerLabelTimestampToken is the COSE header parameter that carries an RFC
+// 3161 timestamp token computed over the signature, i.e., "3161-ctt" (COSE
+// then timestamp) from draft-ietf-cose-tsa-tst-header-parameter.  It goes in
+// the unprotected header, since it is obtained after signing.
+const headerLabelTimestampToken int64 = 270
+
+// the timeout for the HTTP exchange with the TSA
+const tsaTimeout = 30 * time.Second
+
+var (
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+
+	oidRSAEncryption   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidSHA256WithRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}
+	oidSHA384WithRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 12}
+	oidSHA512WithRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 13}
+	oidECPublicKey     = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidECDSAWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 3}
+	oidECDSAWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 4}
+	oidEd25519         = asn1.ObjectIdentifier{1, 3, 101, 112}
+)
+
+// RFC 3161 and RFC 5652 (CMS) structures, limited to what is needed to request
+// and verify timestamp tokens
+
+type tsMessageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+type tsRequest struct {
+	Version        int
+	MessageImprint tsMessageImprint
+	ReqPolicy      asn1.ObjectIdentifier `asn1:"optional"`
+	Nonce          *big.Int              `asn1:"optional"`
+	CertReq        bool                  `asn1:"optional,default:false"`
+}
+
+type tsStatusInfo struct {
+	Status       int
+	StatusString []string       `asn1:"optional"`
+	FailInfo     asn1.BitString `asn1:"optional"`
+}
+
+type tsResponse struct {
+	Status         tsStatusInfo
+	TimeStampToken asn1.RawValue `asn1:"optional"`
+}
+
+type tsAccuracy struct {
+	Seconds int `asn1:"optional"`
+	Millis  int `asn1:"optional,tag:0"`
+	Micros  int `asn1:"optional,tag:1"`
+}
+
+type tsTSTInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint tsMessageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time        `asn1:"generalized"`
+	Accuracy       tsAccuracy       `asn1:"optional"`
+	Ordering       bool             `asn1:"optional,default:false"`
+	Nonce          *big.Int         `asn1:"optional"`
+	TSA            asn1.RawValue    `asn1:"optional,tag:0"`
+	Extensions     []pkix.Extension `asn1:"optional,tag:1"`
+}
+
+type cmsContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type cmsEncapContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"explicit,optional,tag:0"`
+}
+
+type cmsSignedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo cmsEncapContentInfo
+	Certificates     asn1.RawValue   `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue   `asn1:"optional,tag:1"`
+	SignerInfos      []cmsSignerInfo `asn1:"set"`
+}
+
+type cmsIssuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type cmsSignerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type cmsAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+// timestampToken is a decoded RFC 3161 timestamp token
+type timestampToken struct {
+	info       tsTSTInfo
+	eContent   []byte
+	signedData cmsSignedData
+}
+
+// timestamp is the outcome of the verification of a timestamp token
+type timestamp struct {
+	genTime time.Time
+	tsaCert *x509.Certificate
+}
+
+// requestTimestamp obtains from the TSA at tsaURL a timestamp token over the
+// supplied data, using the RFC 3161 HTTP transport
+func requestTimestamp(tsaURL string, data []byte) ([]byte, error) {
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, fmt.Errorf("error generating nonce: %w", err)
+	}
+
+	digest := crypto.SHA256.New()
+	digest.Write(data)
+
+	req, err := asn1.Marshal(tsRequest{
+		Version: 1,
+		MessageImprint: tsMessageImprint{
+			HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+			HashedMessage: digest.Sum(nil),
+		},
+		Nonce:   nonce,
+		CertReq: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding timestamp request: %w", err)
+	}
+
+	client := http.Client{Timeout: tsaTimeout}
+
+	httpRsp, err := client.Post(tsaURL, "application/timestamp-query", bytes.NewReader(req))
+	if err != nil {
+		return nil, fmt.Errorf("error contacting TSA: %w", err)
+	}
+	defer httpRsp.Body.Close()
+
+	if httpRsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("TSA returned HTTP status %q", httpRsp.Status)
+	}
+
+	body, err := io.ReadAll(httpRsp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading TSA response: %w", err)
+	}
+
+	var rsp tsResponse
+	if rest, err := asn1.Unmarshal(body, &rsp); err != nil {
+		return nil, fmt.Errorf("error decoding TSA response: %w", err)
+	} else if len(rest) != 0 {
+		return nil, errors.New("error decoding TSA response: trailing data")
+	}
+
+	// 0 is granted, 1 is granted with modifications
+	if rsp.Status.Status > 1 {
+		return nil, fmt.Errorf("TSA rejected the request with status %d %q", rsp.Status.Status, rsp.Status.StatusString)
+	}
+
+	token := rsp.TimeStampToken.FullBytes
+	if len(token) == 0 {
+		return nil, errors.New("no timestamp token in TSA response")
+	}
+
+	tst, err := parseTimestampToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if tst.info.Nonce == nil || tst.info.Nonce.Cmp(nonce) != 0 {
+		return nil, errors.New("nonce in timestamp token does not match the request")
+	}
+
+	if err = tst.checkImprint(data); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// parseTimestampToken decodes the supplied timestamp token, i.e., a CMS
+// SignedData carrying a TSTInfo
+func parseTimestampToken(token []byte) (*timestampToken, error) {
+	var (
+		ci  cmsContentInfo
+		tst timestampToken
+	)
+
+	if rest, err := asn1.Unmarshal(token, &ci); err != nil {
+		return nil, fmt.Errorf("error decoding timestamp token: %w", err)
+	} else if len(rest) != 0 {
+		return nil, errors.New("error decoding timestamp token: trailing data")
+	}
+
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, fmt.Errorf("timestamp token: expecting SignedData, got content type %s", ci.ContentType)
+	}
+
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &tst.signedData); err != nil {
+		return nil, fmt.Errorf("error decoding timestamp token SignedData: %w", err)
+	}
+
+	eci := tst.signedData.EncapContentInfo
+	if !eci.EContentType.Equal(oidTSTInfo) {
+		return nil, fmt.Errorf("timestamp token: expecting TSTInfo, got content type %s", eci.EContentType)
+	}
+
+	tst.eContent = eci.EContent
+
+	if _, err := asn1.Unmarshal(tst.eContent, &tst.info); err != nil {
+		return nil, fmt.Errorf("error decoding timestamp token TSTInfo: %w", err)
+	}
+
+	return &tst, nil
+}
+
+// checkImprint makes sure that the token has been issued over the supplied data
+func (o *timestampToken) checkImprint(data []byte) error {
+	h, err := hashFromOID(o.info.MessageImprint.HashAlgorithm.Algorithm)
+	if err != nil {
+		return fmt.Errorf("timestamp token message imprint: %w", err)
+	}
+
+	digest := h.New()
+	digest.Write(data)
+
+	if !bytes.Equal(digest.Sum(nil), o.info.MessageImprint.HashedMessage) {
+		return errors.New("timestamp token message imprint does not match the signature")
+	}
+
+	return nil
+}
+
+// verify checks that the token has been issued over the supplied data, and
+// that its signature was made by a TSA certificate chaining up to one of the
+// supplied roots.  The certificate path is validated at the time of the
+// timestamp.
+func (o *timestampToken) verify(data []byte, roots *x509.CertPool) (*timestamp, error) {
+	if err := o.checkImprint(data); err != nil {
+		return nil, err
+	}
+
+	if len(o.signedData.SignerInfos) != 1 {
+		return nil, fmt.Errorf(
+			"timestamp token: expecting exactly one SignerInfo, got %d", len(o.signedData.SignerInfos),
+		)
+	}
+	si := o.signedData.SignerInfos[0]
+
+	certs, err := x509.ParseCertificates(o.signedData.Certificates.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding timestamp token certificates: %w", err)
+	}
+
+	tsaCert, err := findSignerCert(si.SID, certs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkSignedAttrs(&si, o.eContent); err != nil {
+		return nil, err
+	}
+
+	sigAlg, err := signatureAlgorithmFromOIDs(si.SignatureAlgorithm.Algorithm, si.DigestAlgorithm.Algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("timestamp token: %w", err)
+	}
+
+	// the signature is computed over the DER encoding of the signed
+	// attributes as a SET OF, rather than with their implicit [0] tag
+	signedAttrs := append([]byte{0x31}, si.SignedAttrs.FullBytes[1:]...)
+
+	if err = tsaCert.CheckSignature(sigAlg, signedAttrs, si.Signature); err != nil {
+		return nil, fmt.Errorf("timestamp token signature verification failed: %w", err)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range certs {
+		intermediates.AddCert(c)
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   o.info.GenTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	}
+
+	if _, err = tsaCert.Verify(opts); err != nil {
+		return nil, fmt.Errorf("TSA certificate path validation failed: %w", err)
+	}
+
+	return &timestamp{genTime: o.info.GenTime, tsaCert: tsaCert}, nil
+}
+
+// findSignerCert looks up the certificate identified by the SignerIdentifier,
+// i.e., either by issuer and serial number or by subject key identifier
+func findSignerCert(sid asn1.RawValue, certs []*x509.Certificate) (*x509.Certificate, error) {
+	switch {
+	case sid.Class == asn1.ClassUniversal && sid.Tag == asn1.TagSequence:
+		var ias cmsIssuerAndSerialNumber
+		if _, err := asn1.Unmarshal(sid.FullBytes, &ias); err != nil {
+			return nil, fmt.Errorf("error decoding timestamp token signer identifier: %w", err)
+		}
+
+		for _, c := range certs {
+			if bytes.Equal(c.RawIssuer, ias.Issuer.FullBytes) && c.SerialNumber.Cmp(ias.SerialNumber) == 0 {
+				return c, nil
+			}
+		}
+	case sid.Class == asn1.ClassContextSpecific && sid.Tag == 0:
+		for _, c := range certs {
+			if bytes.Equal(c.SubjectKeyId, sid.Bytes) {
+				return c, nil
+			}
+		}
+	default:
+		return nil, errors.New("timestamp token: unsupported signer identifier")
+	}
+
+	return nil, errors.New("timestamp token: TSA certificate not found")
+}
+
+// checkSignedAttrs makes sure that the signed attributes bind the signature
+// to the TSTInfo
+func checkSignedAttrs(si *cmsSignerInfo, eContent []byte) error {
+	if len(si.SignedAttrs.FullBytes) == 0 {
+		return errors.New("timestamp token: no signed attributes")
+	}
+
+	h, err := hashFromOID(si.DigestAlgorithm.Algorithm)
+	if err != nil {
+		return fmt.Errorf("timestamp token digest algorithm: %w", err)
+	}
+
+	digest := h.New()
+	digest.Write(eContent)
+
+	var hasContentType, hasMessageDigest bool
+
+	for rest := si.SignedAttrs.Bytes; len(rest) != 0; {
+		var attr cmsAttribute
+
+		if rest, err = asn1.Unmarshal(rest, &attr); err != nil {
+			return fmt.Errorf("error decoding timestamp token signed attributes: %w", err)
+		}
+
+		if len(attr.Values) != 1 {
+			continue
+		}
+
+		switch {
+		case attr.Type.Equal(oidContentType):
+			var ct asn1.ObjectIdentifier
+			if _, err = asn1.Unmarshal(attr.Values[0].FullBytes, &ct); err != nil || !ct.Equal(oidTSTInfo) {
+				return errors.New("timestamp token: content-type attribute does not match TSTInfo")
+			}
+			hasContentType = true
+		case attr.Type.Equal(oidMessageDigest):
+			var md []byte
+			if _, err = asn1.Unmarshal(attr.Values[0].FullBytes, &md); err != nil || !bytes.Equal(md, digest.Sum(nil)) {
+				return errors.New("timestamp token: message-digest attribute does not match TSTInfo")
+			}
+			hasMessageDigest = true
+		}
+	}
+
+	if !hasContentType || !hasMessageDigest {
+		return errors.New("timestamp token: missing content-type or message-digest attribute")
+	}
+
+	return nil
+}
+
+func hashFromOID(oid asn1.ObjectIdentifier) (crypto.Hash, error) {
+	switch {
+	case oid.Equal(oidSHA256):
+		return crypto.SHA256, nil
+	case oid.Equal(oidSHA384):
+		return crypto.SHA384, nil
+	case oid.Equal(oidSHA512):
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("unsupported hash algorithm %s", oid)
+	}
+}
+
+// signatureAlgorithmFromOIDs maps the CMS signature algorithm, which may only
+// identify the key type, and digest algorithm to an x509.SignatureAlgorithm
+func signatureAlgorithmFromOIDs(sigAlg, digestAlg asn1.ObjectIdentifier) (x509.SignatureAlgorithm, error) {
+	switch {
+	case sigAlg.Equal(oidECDSAWithSHA256):
+		return x509.ECDSAWithSHA256, nil
+	case sigAlg.Equal(oidECDSAWithSHA384):
+		return x509.ECDSAWithSHA384, nil
+	case sigAlg.Equal(oidECDSAWithSHA512):
+		return x509.ECDSAWithSHA512, nil
+	case sigAlg.Equal(oidSHA256WithRSA):
+		return x509.SHA256WithRSA, nil
+	case sigAlg.Equal(oidSHA384WithRSA):
+		return x509.SHA384WithRSA, nil
+	case sigAlg.Equal(oidSHA512WithRSA):
+		return x509.SHA512WithRSA, nil
+	case sigAlg.Equal(oidEd25519):
+		return x509.PureEd25519, nil
+	}
+
+	h, err := hashFromOID(digestAlg)
+	if err != nil {
+		return x509.UnknownSignatureAlgorithm, err
+	}
+
+	algs := map[crypto.Hash][2]x509.SignatureAlgorithm{
+		crypto.SHA256: {x509.ECDSAWithSHA256, x509.SHA256WithRSA},
+		crypto.SHA384: {x509.ECDSAWithSHA384, x509.SHA384WithRSA},
+		crypto.SHA512: {x509.ECDSAWithSHA512, x509.SHA512WithRSA},
+	}
+
+	switch {
+	case sigAlg.Equal(oidECPublicKey):
+		return algs[h][0], nil
+	case sigAlg.Equal(oidRSAEncryption):
+		return algs[h][1], nil
+	default:
+		return x509.UnknownSignatureAlgorithm, fmt.Errorf("unsupported signature algorithm %s", sigAlg)
+	}
+}
+
+// timestampCorim adds a timestamp token, obtained from the TSA at tsaURL, to
+// the unprotected header of each signature of the signed CoRIM (a COSE Sign1
+// or a COSE Sign).  Any existing token is replaced.
+func timestampCorim(signed []byte, tsaURL string) ([]byte, error) {
+	if isMultiSigned(signed) {
+		msg := cose.NewSignMessage()
+		if err := msg.UnmarshalCBOR(signed); err != nil {
+			return nil, err
+		}
+
+		for i, sig := range msg.Signatures {
+			token, err := requestTimestamp(tsaURL, sig.Signature)
+			if err != nil {
+				return nil, fmt.Errorf("signature #%d: %w", i, err)
+			}
+			if sig.Headers.Unprotected == nil {
+				sig.Headers.Unprotected = cose.UnprotectedHeader{}
+			}
+			sig.Headers.Unprotected[headerLabelTimestampToken] = token
+			// force re-encoding of the unprotected header
+			sig.Headers.RawUnprotected = nil
+		}
+
+		return msg.MarshalCBOR()
+	}
+
+	msg, err := decodeSign1(signed)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := requestTimestamp(tsaURL, msg.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.Headers.Unprotected == nil {
+		msg.Headers.Unprotected = cose.UnprotectedHeader{}
+	}
+	msg.Headers.Unprotected[headerLabelTimestampToken] = token
+	// force re-encoding of the unprotected header
+	msg.Headers.RawUnprotected = nil
+
+	return msg.MarshalCBOR()
+}
+
+// timestampTokenFromHeaders returns the timestamp token from the unprotected
+// header, if any
+func timestampTokenFromHeaders(hdr cose.Headers) []byte {
+	token, _ := hdr.Unprotected[headerLabelTimestampToken].([]byte)
+	return token
+}
@@ -0,0 +1,406 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTSA is a minimal RFC 3161 Time Stamping Authority, issuing tokens signed
+// by a P-256 key whose certificate is issued by Root
+type testTSA struct {
+	URL  string
+	Root []byte
+
+	// the time put in the tokens (default: now)
+	genTime time.Time
+	// the PKIStatus returned to the client (default: granted)
+	status int
+
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func makeTestCertWithValidity(
+	t *testing.T, cn string, isCA bool, pub crypto.PublicKey, parent *x509.Certificate, parentKey crypto.Signer,
+	notBefore, notAfter time.Time, eku ...x509.ExtKeyUsage,
+) (*x509.Certificate, []byte) {
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           eku,
+	}
+
+	if parent == nil {
+		parent = tmpl
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, pub, parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, der
+}
+
+func newTestTSA(t *testing.T) *testTSA {
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	notBefore, notAfter := time.Now().Add(-24*time.Hour), time.Now().Add(24*time.Hour)
+
+	root, rootDER := makeTestCertWithValidity(t, "Test TSA Root", true, rootKey.Public(), nil, rootKey,
+		notBefore, notAfter)
+	cert, _ := makeTestCertWithValidity(t, "Test TSA", false, key.Public(), root, rootKey,
+		notBefore, notAfter, x509.ExtKeyUsageTimeStamping)
+
+	tsa := &testTSA{Root: rootDER, cert: cert, key: key}
+
+	server := httptest.NewServer(http.HandlerFunc(tsa.serveHTTP))
+	t.Cleanup(server.Close)
+	tsa.URL = server.URL
+
+	return tsa
+}
+
+func (o *testTSA) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		req tsRequest
+		rsp tsResponse
+	)
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err = asn1.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rsp.Status.Status = o.status
+	if o.status > 1 {
+		rsp.Status.StatusString = []string{"request rejected"}
+	} else if rsp.TimeStampToken.FullBytes, err = o.issue(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := asn1.Marshal(rsp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/timestamp-reply")
+	_, _ = w.Write(data)
+}
+
+func (o *testTSA) issue(req *tsRequest) ([]byte, error) {
+	genTime := o.genTime
+	if genTime.IsZero() {
+		genTime = time.Now()
+	}
+
+	info, err := asn1.Marshal(tsTSTInfo{
+		Version:        1,
+		Policy:         asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1},
+		MessageImprint: req.MessageImprint,
+		SerialNumber:   big.NewInt(time.Now().UnixNano()),
+		GenTime:        genTime.UTC().Truncate(time.Second),
+		Nonce:          req.Nonce,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var signedAttrs []byte
+
+	infoDigest := sha256.Sum256(info)
+	for _, attr := range []struct {
+		typ asn1.ObjectIdentifier
+		val any
+	}{
+		{oidContentType, oidTSTInfo},
+		{oidMessageDigest, infoDigest[:]},
+	} {
+		val, err := asn1.Marshal(attr.val)
+		if err != nil {
+			return nil, err
+		}
+
+		der, err := asn1.Marshal(cmsAttribute{Type: attr.typ, Values: []asn1.RawValue{{FullBytes: val}}})
+		if err != nil {
+			return nil, err
+		}
+
+		signedAttrs = append(signedAttrs, der...)
+	}
+
+	signedSet, err := asn1.Marshal(asn1.RawValue{
+		Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: signedAttrs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256(signedSet)
+	sig, err := ecdsa.SignASN1(rand.Reader, o.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	sid, err := asn1.Marshal(cmsIssuerAndSerialNumber{
+		Issuer:       asn1.RawValue{FullBytes: o.cert.RawIssuer},
+		SerialNumber: o.cert.SerialNumber,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sd, err := asn1.Marshal(cmsSignedData{
+		Version:          3,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{{Algorithm: oidSHA256}},
+		EncapContentInfo: cmsEncapContentInfo{EContentType: oidTSTInfo, EContent: info},
+		Certificates: asn1.RawValue{
+			Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: o.cert.Raw,
+		},
+		SignerInfos: []cmsSignerInfo{{
+			Version:         1,
+			SID:             asn1.RawValue{FullBytes: sid},
+			DigestAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256},
+			SignedAttrs: asn1.RawValue{
+				Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedAttrs,
+			},
+			SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidECDSAWithSHA256},
+			Signature:          sig,
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(cmsContentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sd},
+	})
+}
+
+func Test_requestTimestamp_ok(t *testing.T) {
+	tsa := newTestTSA(t)
+
+	token, err := requestTimestamp(tsa.URL, []byte("signature"))
+	require.NoError(t, err)
+
+	tst, err := parseTimestampToken(token)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	root, err := x509.ParseCertificate(tsa.Root)
+	require.NoError(t, err)
+	roots.AddCert(root)
+
+	ts, err := tst.verify([]byte("signature"), roots)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), ts.genTime, time.Minute)
+	assert.Equal(t, "CN=Test TSA", ts.tsaCert.Subject.String())
+
+	_, err = tst.verify([]byte("another signature"), roots)
+	assert.EqualError(t, err, "timestamp token message imprint does not match the signature")
+
+	_, err = tst.verify([]byte("signature"), x509.NewCertPool())
+	assert.ErrorContains(t, err, "TSA certificate path validation failed")
+}
+
+func Test_requestTimestamp_rejected(t *testing.T) {
+	tsa := newTestTSA(t)
+	tsa.status = 2
+
+	_, err := requestTimestamp(tsa.URL, []byte("signature"))
+	assert.EqualError(t, err, `TSA rejected the request with status 2 ["request rejected"]`)
+}
+
+func Test_requestTimestamp_http_error(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	_, err := requestTimestamp(server.URL, []byte("signature"))
+	assert.EqualError(t, err, `TSA returned HTTP status "404 Not Found"`)
+}
+
+func Test_CorimSignCmd_tsa_url_ok(t *testing.T) {
+	tsa := newTestTSA(t)
+
+	writeSignFixtures(t)
+	require.NoError(t, afero.WriteFile(fs, "tsa-root.der", tsa.Root, 0644))
+
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{"--file=ok.cbor", "--meta=ok.json", "--key=ok.jwk", "--tsa-url=" + tsa.URL})
+	require.NoError(t, cmd.Execute())
+
+	vc, err := loadSignedCorim("signed-ok.cbor", "")
+	require.NoError(t, err)
+	require.NotNil(t, vc.Signatures[0].timestampToken)
+
+	cmd = NewCorimVerifyCmd()
+	cmd.SetArgs([]string{
+		"--file=signed-ok.cbor", "--key=ok.jwk", "--tsa-cert=tsa-root.der", "--skip-validity-check",
+	})
+	assert.NoError(t, cmd.Execute())
+}
+
+func Test_CorimSignCmd_tsa_url_unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	writeSignFixtures(t)
+
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{"--file=ok.cbor", "--meta=ok.json", "--key=ok.jwk", "--tsa-url=" + server.URL})
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "error timestamping CoRIM with TSA at "+server.URL+": error contacting TSA")
+
+	_, err = fs.Stat("signed-ok.cbor")
+	assert.Error(t, err)
+}
+
+func Test_CorimTimestampCmd_ok(t *testing.T) {
+	tsa := newTestTSA(t)
+	writeMultiSignedFixtures(t, makeTestPKI(t), makeTestPKI(t))
+	require.NoError(t, afero.WriteFile(fs, "tsa-root.der", tsa.Root, 0644))
+
+	cmd := NewCorimTimestampCmd()
+	cmd.SetArgs([]string{"--file=signed.cbor", "--tsa-url=" + tsa.URL})
+	require.NoError(t, cmd.Execute())
+
+	vc, err := loadSignedCorim("signed.cbor", "")
+	require.NoError(t, err)
+	for _, sig := range vc.Signatures {
+		assert.NotNil(t, sig.timestampToken)
+	}
+
+	cmd = NewCorimVerifyCmd()
+	cmd.SetArgs([]string{
+		"--file=signed.cbor", "--key=signer0.pem", "--key=signer1.pem", "--tsa-cert=tsa-root.der",
+	})
+	assert.NoError(t, cmd.Execute())
+}
+
+func Test_CorimTimestampCmd_mandatory_args_missing_tsa_url(t *testing.T) {
+	cmd := NewCorimTimestampCmd()
+	cmd.SetArgs([]string{"--file=signed.cbor"})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "no TSA URL supplied")
+}
+
+func Test_CorimVerifyCmd_tsa_cert_no_token(t *testing.T) {
+	tsa := newTestTSA(t)
+	writeDetachedFixtures(t)
+	require.NoError(t, afero.WriteFile(fs, "tsa-root.der", tsa.Root, 0644))
+
+	cmd := NewCorimVerifyCmd()
+	cmd.SetArgs([]string{
+		"--file=signed-ok.cbor",
+		"--payload=signed-ok.payload.cbor",
+		"--key=ok.jwk",
+		"--tsa-cert=tsa-root.der",
+		"--skip-validity-check",
+	})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "error verifying signed-ok.cbor: no timestamp token")
+}
+
+func Test_CorimVerifyCmd_tsa_cert_untrusted(t *testing.T) {
+	tsa := newTestTSA(t)
+	other := newTestTSA(t)
+	writeDetachedFixtures(t)
+	require.NoError(t, afero.WriteFile(fs, "other-root.der", other.Root, 0644))
+
+	cmd := NewCorimTimestampCmd()
+	cmd.SetArgs([]string{"--file=signed-ok.cbor", "--tsa-url=" + tsa.URL})
+	require.NoError(t, cmd.Execute())
+
+	cmd = NewCorimVerifyCmd()
+	cmd.SetArgs([]string{
+		"--file=signed-ok.cbor",
+		"--payload=signed-ok.payload.cbor",
+		"--key=ok.jwk",
+		"--tsa-cert=other-root.der",
+		"--skip-validity-check",
+	})
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "error verifying timestamp of signed-ok.cbor: TSA certificate path validation failed")
+}
+
+// an x5chain whose signing certificate has expired still verifies if the
+// timestamp proves that the signature was made while it was valid
+func Test_CorimVerifyCmd_timestamp_survives_cert_expiry(t *testing.T) {
+	tsa := newTestTSA(t)
+	tsa.genTime = time.Now().Add(-150 * time.Minute)
+
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	root, rootDER := makeTestCertWithValidity(t, "Test Root", true, rootKey.Public(), nil, rootKey,
+		time.Now().Add(-24*time.Hour), time.Now().Add(24*time.Hour))
+	_, leafDER := makeTestCertWithValidity(t, "Test Signer", false, leafKey.Public(), root, rootKey,
+		time.Now().Add(-3*time.Hour), time.Now().Add(-2*time.Hour))
+
+	fs = afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "ok.cbor", testCorimValid, 0644))
+	require.NoError(t, afero.WriteFile(fs, "ok.json", testMetaValid, 0644))
+	require.NoError(t, afero.WriteFile(fs, "leaf.pem", pemEncode("PRIVATE KEY", mustPKCS8(t, leafKey)), 0644))
+	require.NoError(t, afero.WriteFile(fs, "leaf.der", leafDER, 0644))
+	require.NoError(t, afero.WriteFile(fs, "root.der", rootDER, 0644))
+	require.NoError(t, afero.WriteFile(fs, "tsa-root.der", tsa.Root, 0644))
+
+	cmd := NewCorimSignCmd()
+	cmd.SetArgs([]string{
+		"--file=ok.cbor", "--meta=ok.json", "--key=leaf.pem", "--cert=leaf.der", "--tsa-url=" + tsa.URL,
+	})
+	require.NoError(t, cmd.Execute())
+
+	cmd = NewCorimVerifyCmd()
+	cmd.SetArgs([]string{"--file=signed-ok.cbor", "--ta-cert=root.der", "--skip-validity-check"})
+	assert.ErrorContains(t, cmd.Execute(), "certificate has expired or is not yet valid")
+
+	cmd = NewCorimVerifyCmd()
+	cmd.SetArgs([]string{
+		"--file=signed-ok.cbor", "--ta-cert=root.der", "--tsa-cert=tsa-root.der", "--skip-validity-check",
+	})
+	assert.NoError(t, cmd.Execute())
+}